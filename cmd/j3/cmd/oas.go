@@ -11,6 +11,23 @@ func init() {
 	oasIndexCmd.Flags().StringVarP(&oasIndexCmdOptDirectory, "directory", "d", ".", "Local directory containing the specifications to index.")
 	oasIndexCmd.Flags().StringVarP(&oasIndexCmdOptUrl, "url", "u", "", "Public URL from which the local directory is reachable.")
 	oasIndexCmd.Flags().StringArrayVarP(&oasIndexCmdOptExtensions, "extension", "e", []string{".json", ".yaml", ".yml"}, "File extensions of the specifications to consider when indexing.")
+	oasIndexCmd.Flags().StringVarP(&oasIndexCmdOptOutput, "output", "o", "", "Output of the index: a local directory, '-' for stdout in a single format (json unless --format is set) or s3://bucket/prefix. Defaults to the indexed directory.")
+	oasIndexCmd.Flags().StringArrayVarP(&oasIndexCmdOptFormats, "format", "f", []string{"json", "yaml"}, "Formats of the index to produce.")
+	oasIndexCmd.Flags().BoolVarP(&oasIndexCmdOptCanonical, "canonical", "", false, "Produce a pretty-printed and reproducible index, suitable to be committed.")
+	oasIndexCmd.Flags().StringVarP(&oasIndexCmdOptVersionPolicy, "version-policy", "", string(oas.VersionPolicyWarn), "Handling of invalid semantic versions: reject, warn or coerce.")
+	oasIndexCmd.Flags().StringVarP(&oasIndexCmdOptConflictPolicy, "conflict-policy", "", string(oas.ConflictPolicyKeepFirst), "Handling of specifications sharing the same identifier and version: keep-first, keep-newest-mtime, merge or error.")
+	oasIndexCmd.Flags().BoolVarP(&oasIndexCmdOptConflictReport, "conflict-report", "", false, "Publish the specifications sharing the same identifier and version, and the one kept, as conflicts.json.")
+	oasIndexCmd.Flags().StringVarP(&oasIndexCmdOptIdStrategy, "id-strategy", "", string(oas.IdStrategyExtraInfo), "Computation of the identifier used as index key: extra-info (x-extra-info.id, falling back to the slugified title), directory or title.")
+	oasIndexCmd.Flags().StringArrayVarP(&oasIndexCmdOptOverlays, "overlay", "", []string{}, "Overlay applied to the specifications before indexing them. May be repeated, overlays are applied in order.")
+	oasIndexCmd.Flags().BoolVarP(&oasIndexCmdOptChangelogs, "changelogs", "", false, "Publish the changelog of each specification across its versions under changelogs/.")
+	oasIndexCmd.Flags().BoolVarP(&oasIndexCmdOptExportSchemas, "export-schemas", "", false, "Publish the component schemas of each specification as JSON Schema documents under schemas/.")
+	oasIndexCmd.Flags().BoolVarP(&oasIndexCmdOptStats, "stats", "", false, "Record the quality metrics of each specification in its entry.")
+	oasIndexCmd.Flags().BoolVarP(&oasIndexCmdOptSearch, "search", "", false, "Publish a full-text search index of the latest specifications as search.json.")
+	oasIndexCmd.Flags().StringVarP(&oasIndexCmdOptDefaultLanguage, "default-language", "", "en", "Language of the display names and descriptions given only as translations.")
+	oasIndexCmd.Flags().BoolVarP(&oasIndexCmdOptThumbnails, "thumbnails", "", false, "Generate the missing thumbnails from the PNG, JPEG or GIF logos under thumbnails/.")
+	oasIndexCmd.Flags().IntVarP(&oasIndexCmdOptThumbnailSize, "thumbnail-size", "", 128, "Size in pixels of the square in which generated thumbnails fit.")
+	oasIndexCmd.Flags().StringVarP(&oasIndexCmdOptUrlMode, "url-mode", "", "", "URLs written in the index: relative or absolute. Defaults to absolute when a public URL is given.")
+	oasIndexCmd.Flags().StringVarP(&oasIndexCmdOptUrlTemplate, "url-template", "", "", "Template of the specification URLs, e.g. '{base}/{name}/{version}/spec.yaml'. Placeholders: {base}, {path}, {dir}, {file}, {id}, {name}, {title}, {version}.")
 
 	// Build command hierarchy
 	oasCmd.AddCommand(oasIndexCmd)
@@ -20,6 +37,23 @@ func init() {
 var oasIndexCmdOptDirectory string
 var oasIndexCmdOptUrl string
 var oasIndexCmdOptExtensions []string
+var oasIndexCmdOptOutput string
+var oasIndexCmdOptFormats []string
+var oasIndexCmdOptCanonical bool
+var oasIndexCmdOptVersionPolicy string
+var oasIndexCmdOptConflictPolicy string
+var oasIndexCmdOptConflictReport bool
+var oasIndexCmdOptIdStrategy string
+var oasIndexCmdOptOverlays []string
+var oasIndexCmdOptChangelogs bool
+var oasIndexCmdOptExportSchemas bool
+var oasIndexCmdOptStats bool
+var oasIndexCmdOptSearch bool
+var oasIndexCmdOptDefaultLanguage string
+var oasIndexCmdOptThumbnails bool
+var oasIndexCmdOptThumbnailSize int
+var oasIndexCmdOptUrlMode string
+var oasIndexCmdOptUrlTemplate string
 var oasIndexCmd = &cobra.Command{
 	Use:   "index",
 	Short: "Index capabilities",
@@ -29,6 +63,52 @@ var oasIndexCmd = &cobra.Command{
 		options.Directory = oasIndexCmdOptDirectory
 		options.Extensions = oasIndexCmdOptExtensions
 		options.Url = oasIndexCmdOptUrl
+		options.Formats = oasIndexCmdOptFormats
+		options.Canonical = oasIndexCmdOptCanonical
+		options.Overlays = oasIndexCmdOptOverlays
+		options.Changelogs = oasIndexCmdOptChangelogs
+		options.ExportSchemas = oasIndexCmdOptExportSchemas
+		options.Stats = oasIndexCmdOptStats
+		options.Search = oasIndexCmdOptSearch
+		options.DefaultLanguage = oasIndexCmdOptDefaultLanguage
+		options.Thumbnails = oasIndexCmdOptThumbnails
+		options.ThumbnailSize = oasIndexCmdOptThumbnailSize
+		options.UrlTemplate = oasIndexCmdOptUrlTemplate
+		if oasIndexCmdOptUrlMode != "" {
+			urlMode, err := oas.ParseUrlMode(oasIndexCmdOptUrlMode)
+			if err != nil {
+				return err
+			}
+			options.UrlMode = urlMode
+		}
+		versionPolicy, err := oas.ParseVersionPolicy(oasIndexCmdOptVersionPolicy)
+		if err != nil {
+			return err
+		}
+		options.VersionPolicy = versionPolicy
+		conflictPolicy, err := oas.ParseConflictPolicy(oasIndexCmdOptConflictPolicy)
+		if err != nil {
+			return err
+		}
+		options.ConflictPolicy = conflictPolicy
+		options.ConflictReport = oasIndexCmdOptConflictReport
+		idStrategy, err := oas.ParseIdStrategy(oasIndexCmdOptIdStrategy)
+		if err != nil {
+			return err
+		}
+		options.IdStrategy = idStrategy
+		if oasIndexCmdOptOutput != "" {
+			sink, err := oas.NewIndexSink(oasIndexCmdOptOutput)
+			if err != nil {
+				return err
+			}
+			options.Sink = sink
+
+			// Documents written to stdout are not delimited: default to a single format.
+			if _, stdout := sink.(*oas.StdoutSink); stdout && !cmd.Flags().Changed("format") {
+				options.Formats = []string{"json"}
+			}
+		}
 		return oas.Index(options)
 	},
 }
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasChangelogCmd.Flags().StringVarP(&oasChangelogCmdOptRepository, "repository", "r", ".", "Repository containing the versions of the specification: a directory or an http(s) URL.")
+	oasChangelogCmd.Flags().StringVarP(&oasChangelogCmdOptFormat, "format", "f", string(oas.ChangelogFormatMarkdown), "Format of the changelog: markdown, html or json.")
+	oasChangelogCmd.Flags().StringVarP(&oasChangelogCmdOptOutput, "output", "o", "-", "File receiving the changelog, '-' for stdout.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasChangelogCmd)
+}
+
+var oasChangelogCmdOptRepository string
+var oasChangelogCmdOptFormat string
+var oasChangelogCmdOptOutput string
+var oasChangelogCmd = &cobra.Command{
+	Use:   "changelog <name>",
+	Short: "Changelog capabilities",
+	Long:  `Generate the changelog of an OAS3 specification across the versions indexed in a repository`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewChangelogOpts()
+		options.Repository = oasChangelogCmdOptRepository
+		options.Name = args[0]
+		format, err := oas.ParseChangelogFormat(oasChangelogCmdOptFormat)
+		if err != nil {
+			return err
+		}
+		options.Format = format
+		options.OutputFile = oasChangelogCmdOptOutput
+		return oas.GenerateChangelog(options)
+	},
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasTestCmd.Flags().StringVarP(&oasTestCmdOptBaseUrl, "base-url", "b", "http://localhost:8080", "Base URL of the service under test, including the base path of the operations if any.")
+	oasTestCmd.Flags().StringArrayVarP(&oasTestCmdOptHeaders, "header", "H", []string{}, "Header added to every request, as 'Name: value'.")
+	oasTestCmd.Flags().StringVarP(&oasTestCmdOptJUnit, "junit", "j", "", "File receiving the JUnit XML report, '-' for stdout.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasTestCmd)
+}
+
+var oasTestCmdOptBaseUrl string
+var oasTestCmdOptHeaders []string
+var oasTestCmdOptJUnit string
+var oasTestCmd = &cobra.Command{
+	Use:   "test <spec>",
+	Short: "Contract testing capabilities",
+	Long:  `Replay the examples of an OAS3 specification against a running service and check its responses`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewContractTestOpts()
+		options.SpecificationFile = args[0]
+		options.BaseUrl = oasTestCmdOptBaseUrl
+		options.JUnitFile = oasTestCmdOptJUnit
+		for _, header := range oasTestCmdOptHeaders {
+			parts := strings.SplitN(header, ":", 2)
+			if len(parts) != 2 {
+				return fmt.Errorf("invalid header <%s>: expected 'Name: value'", header)
+			}
+			options.Headers[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+
+		report, err := oas.ContractTest(options)
+		if err != nil {
+			return err
+		}
+		if failures := report.Failures(); failures > 0 {
+			return fmt.Errorf("%d of %d contract tests failed", failures, len(report.Cases))
+		}
+		return nil
+	},
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasExampleCmd.Flags().Int64VarP(&oasExampleCmdOptSeed, "seed", "s", 0, "Seed of the random generator, to reproduce examples. Defaults to a random seed.")
+	oasExampleCmd.Flags().IntVarP(&oasExampleCmdOptCount, "count", "n", 1, "Number of examples to generate.")
+	oasExampleCmd.Flags().BoolVarP(&oasExampleCmdOptRequest, "request", "", false, "Generate the request body of the operation instead of a response body.")
+	oasExampleCmd.Flags().StringVarP(&oasExampleCmdOptStatus, "status", "", "", "Response code of the operation to generate. Defaults to the first success response.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasExampleCmd)
+}
+
+var oasExampleCmdOptSeed int64
+var oasExampleCmdOptCount int
+var oasExampleCmdOptRequest bool
+var oasExampleCmdOptStatus string
+var oasExampleCmd = &cobra.Command{
+	Use:   "example <spec> <schema-or-operation>",
+	Short: "Example generation capabilities",
+	Long:  `Generate fake instances of a component schema, or of the body of an operation given by id or as "GET /path"`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewExampleOpts()
+		options.SpecificationFile = args[0]
+		options.Target = args[1]
+		if cmd.Flags().Changed("seed") {
+			options.Seed = oasExampleCmdOptSeed
+		}
+		options.Count = oasExampleCmdOptCount
+		options.Request = oasExampleCmdOptRequest
+		options.Status = oasExampleCmdOptStatus
+		return oas.Example(options)
+	},
+}
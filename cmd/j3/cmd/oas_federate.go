@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasFederateCmd.Flags().StringVarP(&oasFederateCmdOptConflictPolicy, "conflict-policy", "", string(oas.FederationConflictPolicyError), "Handling of specifications sharing the same identifier in several repositories: error, first, namespace or merge.")
+	oasFederateCmd.Flags().StringVarP(&oasFederateCmdOptOutput, "output", "o", "-", "Output of the federated index: a local directory, '-' for stdout or s3://bucket/prefix. Empty to only serve it.")
+	oasFederateCmd.Flags().StringArrayVarP(&oasFederateCmdOptFormats, "format", "f", []string{"json"}, "Formats of the federated index to produce.")
+	oasFederateCmd.Flags().BoolVarP(&oasFederateCmdOptCanonical, "canonical", "", false, "Produce a pretty-printed and reproducible index, suitable to be committed.")
+	oasFederateCmd.Flags().StringVarP(&oasFederateCmdOptAddress, "serve", "", "", "Address on which the federated index is served and periodically refreshed, e.g. localhost:8080.")
+	oasFederateCmd.Flags().DurationVarP(&oasFederateCmdOptRefresh, "refresh", "", 5*time.Minute, "Refresh interval of the served federated index, 0 to disable.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasFederateCmd)
+}
+
+var oasFederateCmdOptConflictPolicy string
+var oasFederateCmdOptOutput string
+var oasFederateCmdOptFormats []string
+var oasFederateCmdOptCanonical bool
+var oasFederateCmdOptAddress string
+var oasFederateCmdOptRefresh time.Duration
+var oasFederateCmd = &cobra.Command{
+	Use:   "federate [name=]<repository>...",
+	Short: "Federation capabilities",
+	Long:  `Combine the indexes of several local or remote repositories into one federated index, with the source of each entry`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewFederateOpts()
+		options.Sources = args
+		conflictPolicy, err := oas.ParseFederationConflictPolicy(oasFederateCmdOptConflictPolicy)
+		if err != nil {
+			return err
+		}
+		options.ConflictPolicy = conflictPolicy
+		options.Output = oasFederateCmdOptOutput
+		options.Formats = oasFederateCmdOptFormats
+		options.Canonical = oasFederateCmdOptCanonical
+		options.Address = oasFederateCmdOptAddress
+		options.RefreshInterval = oasFederateCmdOptRefresh
+		return oas.Federate(options)
+	},
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasFilterCmd.Flags().StringVarP(&oasFilterCmdOptRepository, "repository", "r", ".", "Repository used to resolve name@version references: a directory or an http(s) URL.")
+	oasFilterCmd.Flags().StringArrayVarP(&oasFilterCmdOptTags, "tag", "t", []string{}, "Keep the operations having this tag. May be repeated.")
+	oasFilterCmd.Flags().StringArrayVarP(&oasFilterCmdOptPaths, "path", "p", []string{}, "Keep the operations under this path prefix. May be repeated.")
+	oasFilterCmd.Flags().StringArrayVarP(&oasFilterCmdOptAudiences, "audience", "a", []string{}, "Keep the operations whose x-audience is included in this audience: public, partner or internal. May be repeated.")
+	oasFilterCmd.Flags().StringVarP(&oasFilterCmdOptDefaultAudience, "default-audience", "", "internal", "Audience of the operations without x-audience.")
+	oasFilterCmd.Flags().StringVarP(&oasFilterCmdOptOutput, "output", "o", "-", "File receiving the filtered specification, '-' for stdout. Its extension selects the format.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasFilterCmd)
+}
+
+var oasFilterCmdOptRepository string
+var oasFilterCmdOptTags []string
+var oasFilterCmdOptPaths []string
+var oasFilterCmdOptAudiences []string
+var oasFilterCmdOptDefaultAudience string
+var oasFilterCmdOptOutput string
+var oasFilterCmd = &cobra.Command{
+	Use:   "filter <spec>",
+	Short: "Filter capabilities",
+	Long:  `Derive an OAS3 specification containing the operations matching tags, path prefixes or audiences`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewFilterOpts()
+		options.Repository = oasFilterCmdOptRepository
+		options.SpecificationFile = args[0]
+		options.Tags = oasFilterCmdOptTags
+		options.PathPrefixes = oasFilterCmdOptPaths
+		options.Audiences = oasFilterCmdOptAudiences
+		options.DefaultAudience = oasFilterCmdOptDefaultAudience
+		options.OutputFile = oasFilterCmdOptOutput
+		return oas.Filter(options)
+	},
+}
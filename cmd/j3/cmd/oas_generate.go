@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasGenerateGoCmd.Flags().StringVarP(&oasGenerateGoCmdOptOutput, "output", "o", ".", "Directory receiving the generated files.")
+	oasGenerateGoCmd.Flags().StringVarP(&oasGenerateGoCmdOptPackage, "package", "p", "api", "Name of the generated Go package.")
+
+	// Build command hierarchy
+	oasGenerateCmd.AddCommand(oasGenerateGoCmd)
+	oasCmd.AddCommand(oasGenerateCmd)
+}
+
+var oasGenerateGoCmdOptOutput string
+var oasGenerateGoCmdOptPackage string
+var oasGenerateGoCmd = &cobra.Command{
+	Use:   "go <spec>",
+	Short: "Go code generation capabilities",
+	Long:  `Generate Go models, client and server interface from an OAS3 specification`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewGenerateGoOpts()
+		options.SpecificationFile = args[0]
+		options.OutputDirectory = oasGenerateGoCmdOptOutput
+		options.PackageName = oasGenerateGoCmdOptPackage
+		return oas.GenerateGo(options)
+	},
+}
+
+var oasGenerateCmd = &cobra.Command{Use: "generate"}
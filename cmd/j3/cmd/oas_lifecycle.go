@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasLifecycleCmd.Flags().StringVarP(&oasLifecycleCmdOptRepository, "repository", "r", ".", "Repository to report: a directory or an http(s) URL.")
+	oasLifecycleCmd.Flags().StringVarP(&oasLifecycleCmdOptDate, "date", "", "", "Date compared to the sunset dates, as YYYY-MM-DD. Defaults to today.")
+	oasLifecycleCmd.Flags().StringVarP(&oasLifecycleCmdOptFormat, "format", "f", "text", "Format of the report: text or json.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasLifecycleCmd)
+}
+
+var oasLifecycleCmdOptRepository string
+var oasLifecycleCmdOptDate string
+var oasLifecycleCmdOptFormat string
+var oasLifecycleCmd = &cobra.Command{
+	Use:   "lifecycle",
+	Short: "Lifecycle capabilities",
+	Long:  `Report the OAS3 specifications of a repository past their sunset date, with their owner and replacement`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewLifecycleOpts()
+		options.Repository = oasLifecycleCmdOptRepository
+		if oasLifecycleCmdOptDate != "" {
+			date, err := time.Parse("2006-01-02", oasLifecycleCmdOptDate)
+			if err != nil {
+				return fmt.Errorf("invalid date <%s>: expected YYYY-MM-DD", oasLifecycleCmdOptDate)
+			}
+			options.Date = date
+		}
+		options.Format = oasLifecycleCmdOptFormat
+		return oas.Lifecycle(options)
+	},
+}
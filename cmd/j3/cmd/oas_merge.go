@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasMergeCmd.Flags().StringVarP(&oasMergeCmdOptRepository, "repository", "r", ".", "Repository containing the specifications: a directory or an http(s) URL.")
+	oasMergeCmd.Flags().StringVarP(&oasMergeCmdOptTitle, "title", "", "Merged API", "Title of the merged specification.")
+	oasMergeCmd.Flags().StringVarP(&oasMergeCmdOptVersion, "version", "", "1.0.0", "Version of the merged specification.")
+	oasMergeCmd.Flags().StringVarP(&oasMergeCmdOptDescription, "description", "", "", "Description of the merged specification. Defaults to the list of merged specifications.")
+	oasMergeCmd.Flags().StringVarP(&oasMergeCmdOptServer, "server", "s", "", "URL of the gateway. Defaults to the origin of the first server of the first specification.")
+	oasMergeCmd.Flags().StringVarP(&oasMergeCmdOptOutput, "output", "o", "-", "File receiving the merged specification, '-' for stdout. Its extension selects the format.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasMergeCmd)
+}
+
+var oasMergeCmdOptRepository string
+var oasMergeCmdOptTitle string
+var oasMergeCmdOptVersion string
+var oasMergeCmdOptDescription string
+var oasMergeCmdOptServer string
+var oasMergeCmdOptOutput string
+var oasMergeCmd = &cobra.Command{
+	Use:   "merge <name@version[=/prefix]>...",
+	Short: "Merge capabilities",
+	Long:  `Merge OAS3 specifications of a repository, or files, into a single gateway specification`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewMergeOpts()
+		options.Repository = oasMergeCmdOptRepository
+		options.Sources = args
+		options.Title = oasMergeCmdOptTitle
+		options.Version = oasMergeCmdOptVersion
+		options.Description = oasMergeCmdOptDescription
+		options.ServerUrl = oasMergeCmdOptServer
+		options.OutputFile = oasMergeCmdOptOutput
+		return oas.Merge(options)
+	},
+}
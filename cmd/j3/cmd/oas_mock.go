@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasMockCmd.Flags().StringVarP(&oasMockCmdOptAddress, "address", "a", "localhost:8080", "Address on which the mock server listens.")
+	oasMockCmd.Flags().StringVarP(&oasMockCmdOptBasePath, "base-path", "b", "", "Base path of the operations. Defaults to the path of the first server URL of the specification.")
+	oasMockCmd.Flags().BoolVarP(&oasMockCmdOptValidate, "validate", "", true, "Validate incoming requests against the parameters and request bodies of the specification.")
+	oasMockCmd.Flags().Int64VarP(&oasMockCmdOptSeed, "seed", "s", 0, "Seed of the random generator of the payloads not given as examples.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasMockCmd)
+}
+
+var oasMockCmdOptAddress string
+var oasMockCmdOptBasePath string
+var oasMockCmdOptValidate bool
+var oasMockCmdOptSeed int64
+var oasMockCmd = &cobra.Command{
+	Use:   "mock <spec>",
+	Short: "Mock capabilities",
+	Long:  `Start a local HTTP server answering every operation of an OAS3 specification with its examples or generated payloads`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewMockOpts()
+		options.SpecificationFile = args[0]
+		options.Address = oasMockCmdOptAddress
+		options.BasePath = oasMockCmdOptBasePath
+		options.ValidateRequests = oasMockCmdOptValidate
+		options.Seed = oasMockCmdOptSeed
+		return oas.Mock(options)
+	},
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasOverlayApplyCmd.Flags().StringVarP(&oasOverlayApplyCmdOptOutput, "output", "o", "-", "File receiving the resulting specification, '-' for stdout. Its extension selects the format.")
+
+	// Build command hierarchy
+	oasOverlayCmd.AddCommand(oasOverlayApplyCmd)
+	oasCmd.AddCommand(oasOverlayCmd)
+}
+
+var oasOverlayApplyCmdOptOutput string
+var oasOverlayApplyCmd = &cobra.Command{
+	Use:   "apply <spec> <overlay>...",
+	Short: "Overlay capabilities",
+	Long:  `Apply OpenAPI Overlay documents, in order, to an OAS3 specification`,
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewOverlayOpts()
+		options.SpecificationFile = args[0]
+		options.OverlayFiles = args[1:]
+		options.OutputFile = oasOverlayApplyCmdOptOutput
+		return oas.ApplyOverlays(options)
+	},
+}
+
+var oasOverlayCmd = &cobra.Command{Use: "overlay"}
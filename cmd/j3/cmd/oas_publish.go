@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasPublishCmd.Flags().StringVarP(&oasPublishCmdOptRepository, "repository", "r", ".", "Local directory of the repository receiving the specification.")
+	oasPublishCmd.Flags().StringVarP(&oasPublishCmdOptUrl, "url", "u", "", "Public URL from which the repository is reachable.")
+	oasPublishCmd.Flags().StringArrayVarP(&oasPublishCmdOptFormats, "format", "f", []string{"json", "yaml"}, "Formats of the index to produce.")
+	oasPublishCmd.Flags().BoolVarP(&oasPublishCmdOptCanonical, "canonical", "", false, "Produce a pretty-printed and reproducible index, suitable to be committed.")
+	oasPublishCmd.Flags().StringVarP(&oasPublishCmdOptIdStrategy, "id-strategy", "", string(oas.IdStrategyExtraInfo), "Computation of the identifier used as index key: extra-info (x-extra-info.id, falling back to the slugified title), directory or title.")
+	oasPublishCmd.Flags().BoolVarP(&oasPublishCmdOptChangelogs, "changelogs", "", false, "Publish the changelog of each specification across its versions under changelogs/.")
+	oasPublishCmd.Flags().BoolVarP(&oasPublishCmdOptSearch, "search", "", false, "Publish a full-text search index of the latest specifications as search.json.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasPublishCmd)
+}
+
+var oasPublishCmdOptRepository string
+var oasPublishCmdOptUrl string
+var oasPublishCmdOptFormats []string
+var oasPublishCmdOptCanonical bool
+var oasPublishCmdOptIdStrategy string
+var oasPublishCmdOptChangelogs bool
+var oasPublishCmdOptSearch bool
+var oasPublishCmd = &cobra.Command{
+	Use:   "publish <spec>",
+	Short: "Publish capabilities",
+	Long:  `Validate an OAS3 specification, copy it into a repository as <name>/<version>/openapi.yaml and re-index the repository. Released versions cannot be overwritten`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewPublishOpts()
+		options.Specification = args[0]
+		options.Index.Directory = oasPublishCmdOptRepository
+		options.Index.Url = oasPublishCmdOptUrl
+		options.Index.Formats = oasPublishCmdOptFormats
+		options.Index.Canonical = oasPublishCmdOptCanonical
+		options.Index.Changelogs = oasPublishCmdOptChangelogs
+		options.Index.Search = oasPublishCmdOptSearch
+		idStrategy, err := oas.ParseIdStrategy(oasPublishCmdOptIdStrategy)
+		if err != nil {
+			return err
+		}
+		options.Index.IdStrategy = idStrategy
+		return oas.Publish(options)
+	},
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasSchemasExportCmd.Flags().StringVarP(&oasSchemasExportCmdOptOutput, "output", "o", ".", "Directory receiving the JSON Schema documents.")
+	oasSchemasExportCmd.Flags().StringVarP(&oasSchemasExportCmdOptBaseUrl, "base-url", "", "", "Base URL used to build the $id of the documents.")
+
+	// Build command hierarchy
+	oasSchemasCmd.AddCommand(oasSchemasExportCmd)
+	oasCmd.AddCommand(oasSchemasCmd)
+}
+
+var oasSchemasExportCmdOptOutput string
+var oasSchemasExportCmdOptBaseUrl string
+var oasSchemasExportCmd = &cobra.Command{
+	Use:   "export <spec>",
+	Short: "JSON Schema export capabilities",
+	Long:  `Export the component schemas of an OAS3 specification as JSON Schema 2020-12 documents`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewSchemaExportOpts()
+		options.SpecificationFile = args[0]
+		options.OutputDirectory = oasSchemasExportCmdOptOutput
+		options.BaseUrl = oasSchemasExportCmdOptBaseUrl
+		return oas.ExportSchemas(options)
+	},
+}
+
+var oasSchemasCmd = &cobra.Command{Use: "schemas"}
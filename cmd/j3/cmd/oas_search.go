@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasSearchCmd.Flags().StringVarP(&oasSearchCmdOptRepository, "repository", "r", ".", "Repository to search: a directory or an http(s) URL.")
+	oasSearchCmd.Flags().IntVarP(&oasSearchCmdOptLimit, "limit", "n", 10, "Maximum number of results, 0 for all.")
+	oasSearchCmd.Flags().StringArrayVarP(&oasSearchCmdOptLanguages, "language", "l", []string{}, "Language in which results are shown when translated. May be repeated, by decreasing preference.")
+	oasSearchCmd.Flags().StringVarP(&oasSearchCmdOptFormat, "format", "f", "text", "Format of the results: text or json.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasSearchCmd)
+}
+
+var oasSearchCmdOptRepository string
+var oasSearchCmdOptLimit int
+var oasSearchCmdOptLanguages []string
+var oasSearchCmdOptFormat string
+var oasSearchCmd = &cobra.Command{
+	Use:   "search <query>...",
+	Short: "Search capabilities",
+	Long:  `Search the specifications of a repository by name, keywords, operation summaries and descriptions`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewSearchOpts()
+		options.Repository = oasSearchCmdOptRepository
+		options.Query = strings.Join(args, " ")
+		options.Limit = oasSearchCmdOptLimit
+		options.Languages = oas.ParseAcceptLanguage(strings.Join(oasSearchCmdOptLanguages, ","))
+		options.Format = oasSearchCmdOptFormat
+		return oas.Search(options)
+	},
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasServeCmd.Flags().StringVarP(&oasServeCmdOptRepository, "repository", "r", ".", "Repository directory to serve.")
+	oasServeCmd.Flags().StringVarP(&oasServeCmdOptAddress, "address", "a", "localhost:8080", "Address on which the server listens.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasServeCmd)
+}
+
+var oasServeCmdOptRepository string
+var oasServeCmdOptAddress string
+var oasServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve capabilities",
+	Long:  `Serve the files of a repository over HTTP, with its search index exposed at /search?q=`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewServeOpts()
+		options.Repository = oasServeCmdOptRepository
+		options.Address = oasServeCmdOptAddress
+		return oas.Serve(options)
+	},
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasStatsCmd.Flags().StringVarP(&oasStatsCmdOptRepository, "repository", "r", ".", "Repository used to resolve name@version references, and whose specifications are reported when none is given.")
+	oasStatsCmd.Flags().StringVarP(&oasStatsCmdOptFormat, "format", "f", "text", "Format of the report: text or json.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasStatsCmd)
+}
+
+var oasStatsCmdOptRepository string
+var oasStatsCmdOptFormat string
+var oasStatsCmd = &cobra.Command{
+	Use:   "stats [spec]...",
+	Short: "Stats capabilities",
+	Long:  `Report the metrics and quality score of OAS3 specifications, or of the latest versions of a repository`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewStatsOpts()
+		options.Repository = oasStatsCmdOptRepository
+		options.Specifications = args
+		options.Format = oasStatsCmdOptFormat
+		return oas.Stats(options)
+	},
+}
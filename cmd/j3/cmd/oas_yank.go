@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+
+	"github.com/julb/go/pkg/oas"
+)
+
+func init() {
+	// Command opts
+	oasYankCmd.Flags().StringVarP(&oasYankCmdOptRepository, "repository", "r", ".", "Local directory of the repository holding the yanked version.")
+	oasYankCmd.Flags().StringVarP(&oasYankCmdOptReason, "reason", "", "", "Reason of the yank, recorded in the index.")
+	oasYankCmd.Flags().StringVarP(&oasYankCmdOptUrl, "url", "u", "", "Public URL from which the repository is reachable.")
+	oasYankCmd.Flags().StringArrayVarP(&oasYankCmdOptFormats, "format", "f", []string{"json", "yaml"}, "Formats of the index to produce.")
+	oasYankCmd.Flags().BoolVarP(&oasYankCmdOptCanonical, "canonical", "", false, "Produce a pretty-printed and reproducible index, suitable to be committed.")
+	oasYankCmd.Flags().StringVarP(&oasYankCmdOptIdStrategy, "id-strategy", "", string(oas.IdStrategyExtraInfo), "Computation of the identifier used as index key: extra-info (x-extra-info.id, falling back to the slugified title), directory or title.")
+	oasYankCmd.Flags().BoolVarP(&oasYankCmdOptChangelogs, "changelogs", "", false, "Publish the changelog of each specification across its versions under changelogs/.")
+	oasYankCmd.Flags().BoolVarP(&oasYankCmdOptSearch, "search", "", false, "Publish a full-text search index of the latest specifications as search.json.")
+
+	// Build command hierarchy
+	oasCmd.AddCommand(oasYankCmd)
+}
+
+var oasYankCmdOptRepository string
+var oasYankCmdOptReason string
+var oasYankCmdOptUrl string
+var oasYankCmdOptFormats []string
+var oasYankCmdOptCanonical bool
+var oasYankCmdOptIdStrategy string
+var oasYankCmdOptChangelogs bool
+var oasYankCmdOptSearch bool
+var oasYankCmd = &cobra.Command{
+	Use:   "yank <name@version>",
+	Short: "Yank capabilities",
+	Long:  `Mark a published version of an OAS3 specification as yanked: it stays in the index for history but is excluded from version resolution`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		options := oas.NewYankOpts()
+		options.Reference = args[0]
+		options.Reason = oasYankCmdOptReason
+		options.Index.Directory = oasYankCmdOptRepository
+		options.Index.Url = oasYankCmdOptUrl
+		options.Index.Formats = oasYankCmdOptFormats
+		options.Index.Canonical = oasYankCmdOptCanonical
+		options.Index.Changelogs = oasYankCmdOptChangelogs
+		options.Index.Search = oasYankCmdOptSearch
+		idStrategy, err := oas.ParseIdStrategy(oasYankCmdOptIdStrategy)
+		if err != nil {
+			return err
+		}
+		options.Index.IdStrategy = idStrategy
+		return oas.Yank(options)
+	},
+}
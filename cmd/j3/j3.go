@@ -1,9 +1,13 @@
 package main
 
 import (
+	"os"
+
 	"github.com/julb/go/cmd/j3/cmd"
 )
 
 func main() {
-	cmd.ExecuteMainCmd()
+	if err := cmd.ExecuteMainCmd(); err != nil {
+		os.Exit(1)
+	}
 }
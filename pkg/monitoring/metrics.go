@@ -6,6 +6,20 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+var (
+	// Requests not matching the OAS3 specification of the service, by operation.
+	OASRequestViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "oas_request_violations_total",
+		Help: "The total number of requests not matching the OAS3 specification",
+	}, []string{"method", "path"})
+
+	// Responses not matching the OAS3 specification of the service, by operation.
+	OASResponseViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "oas_response_violations_total",
+		Help: "The total number of responses not matching the OAS3 specification",
+	}, []string{"method", "path"})
+)
+
 func init() {
 	promauto.NewGauge(prometheus.GaugeOpts{
 		Name: "build_info",
@@ -0,0 +1,163 @@
+package oas
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Directory of the repository receiving the generated thumbnails.
+const thumbnailsDirectory = "thumbnails"
+
+// resolveImages checks that the images of an entry given as paths exist in the indexed directory and
+// rewrites them to public URLs. Paths are relative to the specification, or to the indexed directory
+// when starting with a slash. URLs are kept as is.
+func resolveImages(o *IndexOpts, oas3Source *OAS3Source, entry *V1_RepositoryIndexSpecificationEntry) error {
+	for _, image := range []*string{&entry.Image.Icon, &entry.Image.Logo, &entry.Image.Thumbnail} {
+		relativePath, err := localImagePath(o, oas3Source, *image)
+		if err != nil {
+			return err
+		}
+		if relativePath == "" {
+			continue
+		}
+		*image = o.publicUrl(relativePath)
+	}
+	return nil
+}
+
+// localImagePath returns the path of an image relative to the indexed directory, with slashes, or an
+// empty string when the image is empty or a URL.
+func localImagePath(o *IndexOpts, oas3Source *OAS3Source, image string) (string, error) {
+	if image == "" || isHttpLocation(image) || strings.HasPrefix(image, "data:") {
+		return "", nil
+	}
+
+	// Resolve path
+	path := filepath.Join(filepath.Dir(oas3Source.path), filepath.FromSlash(image))
+	if strings.HasPrefix(image, "/") {
+		path = filepath.Join(o.Directory, filepath.FromSlash(image))
+	}
+	relativePath, err := filepath.Rel(o.Directory, path)
+	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("specification <%s>: image <%s> is outside of the indexed directory", oas3Source.path, image)
+	}
+
+	// Verify file
+	fileInfo, err := os.Stat(path)
+	if err != nil || fileInfo.IsDir() {
+		return "", fmt.Errorf("specification <%s>: image <%s> not found", oas3Source.path, image)
+	}
+	return filepath.ToSlash(relativePath), nil
+}
+
+// buildThumbnailArtifacts generates a PNG thumbnail, named thumbnails/<id>/<version>.png, for the
+// entries having a local PNG, JPEG or GIF logo but no thumbnail.
+func buildThumbnailArtifacts(o *IndexOpts, index *V1_RepositoryIndex, resolver *conflictResolver, sources map[string]*OAS3Source) (map[string][]byte, error) {
+	artifacts := make(map[string][]byte)
+	for id, entries := range index.Entries {
+		for i := range entries {
+			entry := &entries[i]
+			source := sources[resolver.sources[id+"@"+entryVersionKey(entry)]]
+			if source == nil || entry.Image.Thumbnail != "" {
+				continue
+			}
+			logoPath, err := localImagePath(o, source, source.specification.Info.ExtraInfo.LogoUrl)
+			if err != nil {
+				return nil, err
+			}
+			if logoPath == "" {
+				continue
+			}
+
+			if err := validatePathSegment(entryVersionKey(entry)); err != nil {
+				return nil, fmt.Errorf("specification <%s>: version cannot be used in the path of the thumbnail: %v", source.path, err)
+			}
+
+			// Decode logo
+			logoFile, err := os.Open(filepath.Join(o.Directory, filepath.FromSlash(logoPath)))
+			if err != nil {
+				return nil, err
+			}
+			logo, _, err := image.Decode(logoFile)
+			logoFile.Close()
+			if err != nil {
+				log.Debugf("Logo <%s> cannot be decoded, no thumbnail generated: %v", logoPath, err)
+				continue
+			}
+
+			// Encode thumbnail
+			var buffer bytes.Buffer
+			err = png.Encode(&buffer, scaleImage(logo, o.ThumbnailSize))
+			if err != nil {
+				return nil, err
+			}
+			name := strings.Join([]string{thumbnailsDirectory, id, entryVersionKey(entry) + ".png"}, "/")
+			artifacts[name] = buffer.Bytes()
+
+			entry.Image.Thumbnail = o.publicUrl(name)
+		}
+	}
+	return artifacts, nil
+}
+
+// scaleImage reduces an image so that it fits in a square of the given size, averaging the source
+// pixels covered by each target pixel. Smaller images are returned unchanged.
+func scaleImage(source image.Image, size int) image.Image {
+	bounds := source.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if size <= 0 || (width <= size && height <= size) {
+		return source
+	}
+	targetWidth, targetHeight := size, size
+	if width > height {
+		targetHeight = maxInt(1, height*size/width)
+	} else {
+		targetWidth = maxInt(1, width*size/height)
+	}
+
+	target := image.NewNRGBA(image.Rect(0, 0, targetWidth, targetHeight))
+	for y := 0; y < targetHeight; y++ {
+		y0, y1 := bounds.Min.Y+y*height/targetHeight, bounds.Min.Y+(y+1)*height/targetHeight
+		for x := 0; x < targetWidth; x++ {
+			x0, x1 := bounds.Min.X+x*width/targetWidth, bounds.Min.X+(x+1)*width/targetWidth
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pixel := color.NRGBA64Model.Convert(source.At(sx, sy)).(color.NRGBA64)
+					r += uint64(pixel.R) * uint64(pixel.A)
+					g += uint64(pixel.G) * uint64(pixel.A)
+					b += uint64(pixel.B) * uint64(pixel.A)
+					a += uint64(pixel.A)
+					count++
+				}
+			}
+			if a == 0 {
+				continue
+			}
+			target.SetNRGBA(x, y, color.NRGBA{
+				R: uint8(r / a >> 8),
+				G: uint8(g / a >> 8),
+				B: uint8(b / a >> 8),
+				A: uint8(a / count >> 8),
+			})
+		}
+	}
+	return target
+}
+
+func maxInt(a int, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
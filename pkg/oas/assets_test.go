@@ -0,0 +1,178 @@
+package oas
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeTestImage writes a PNG whose left half is red and right half is blue.
+func writeTestImage(t *testing.T, path string, width int, height int) {
+	t.Helper()
+	picture := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if x < width/2 {
+				picture.SetNRGBA(x, y, color.NRGBA{R: 255, A: 255})
+			} else {
+				picture.SetNRGBA(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, picture); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, path, buffer.String())
+}
+
+func assetsTestSpecification(version string, extraInfo string) string {
+	return "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: " + version + "\n  x-extra-info:\n" + extraInfo + "paths: {}\n"
+}
+
+func TestResolveImages(t *testing.T) {
+	cases := []struct {
+		name     string
+		image    string
+		expected string
+		err      string
+	}{
+		{"relative to the specification", "icon.png", "https://apis.example.com/pets/icon.png", ""},
+		{"relative to the repository", "/images/logo.png", "https://apis.example.com/images/logo.png", ""},
+		{"parent directory", "../images/logo.png", "https://apis.example.com/images/logo.png", ""},
+		{"URL", "https://cdn.example.com/logo.png", "https://cdn.example.com/logo.png", ""},
+		{"data URL", "data:image/png;base64,AA==", "data:image/png;base64,AA==", ""},
+		{"missing file", "missing.png", "", "not found"},
+		{"directory", "/images", "", "not found"},
+		{"outside of the repository", "../../logo.png", "", "outside of the indexed directory"},
+		{"outside from the root", "/../logo.png", "", "outside of the indexed directory"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			root := t.TempDir()
+			directory := filepath.Join(root, "repository")
+			writeTestFile(t, filepath.Join(root, "logo.png"), "outside")
+			writeTestFile(t, filepath.Join(directory, "pets/icon.png"), "icon")
+			writeTestFile(t, filepath.Join(directory, "images/logo.png"), "logo")
+			writeTestFile(t, filepath.Join(directory, "pets/1.0.0.yaml"), assetsTestSpecification("1.0.0", "    iconUrl: "+c.image+"\n"))
+
+			opts := NewIndexOpts()
+			opts.Directory = directory
+			opts.Url = "https://apis.example.com"
+			err := Index(opts)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Errorf("got error <%v>, want <%s>", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if icon := readTestIndex(t, directory).Entries["pets"][0].Image.Icon; icon != c.expected {
+				t.Errorf("got icon <%s>, want <%s>", icon, c.expected)
+			}
+		})
+	}
+}
+
+func TestThumbnails(t *testing.T) {
+	directory := t.TempDir()
+	writeTestImage(t, filepath.Join(directory, "pets/logo.png"), 256, 128)
+	writeTestImage(t, filepath.Join(directory, "pets/small.png"), 32, 16)
+	writeTestFile(t, filepath.Join(directory, "pets/broken.png"), "not an image")
+	writeTestFile(t, filepath.Join(directory, "pets/thumbnail.png"), "thumbnail")
+	specifications := map[string]string{
+		"1.0.0": "    logoUrl: logo.png\n",
+		"2.0.0": "    logoUrl: small.png\n",
+		"3.0.0": "    logoUrl: broken.png\n",
+		"4.0.0": "    logoUrl: logo.png\n    thumbnailUrl: thumbnail.png\n",
+		"5.0.0": "    logoUrl: https://cdn.example.com/logo.png\n",
+	}
+	for version, extraInfo := range specifications {
+		writeTestFile(t, filepath.Join(directory, "pets", version+".yaml"), assetsTestSpecification(version, extraInfo))
+	}
+
+	opts := NewIndexOpts()
+	opts.Directory = directory
+	opts.Url = "https://apis.example.com"
+	opts.Thumbnails = true
+	opts.ThumbnailSize = 64
+	if err := Index(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	thumbnails := map[string]string{}
+	for _, entry := range readTestIndex(t, directory).Entries["pets"] {
+		thumbnails[entry.Version] = entry.Image.Thumbnail
+	}
+	expected := map[string]string{
+		"1.0.0": "https://apis.example.com/thumbnails/pets/1.0.0.png",
+		"2.0.0": "https://apis.example.com/thumbnails/pets/2.0.0.png",
+		"3.0.0": "",
+		"4.0.0": "https://apis.example.com/pets/thumbnail.png",
+		"5.0.0": "",
+	}
+	if !reflect.DeepEqual(thumbnails, expected) {
+		t.Errorf("got thumbnails %v, want %v", thumbnails, expected)
+	}
+
+	cases := []struct {
+		version string
+		width   int
+		height  int
+	}{
+		{"1.0.0", 64, 32},
+		{"2.0.0", 32, 16},
+	}
+	for _, c := range cases {
+		file, err := os.Open(filepath.Join(directory, "thumbnails/pets", c.version+".png"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		thumbnail, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		bounds := thumbnail.Bounds()
+		left := color.NRGBAModel.Convert(thumbnail.At(0, 0)).(color.NRGBA)
+		right := color.NRGBAModel.Convert(thumbnail.At(bounds.Dx()-1, bounds.Dy()-1)).(color.NRGBA)
+		if bounds.Dx() != c.width || bounds.Dy() != c.height || left != (color.NRGBA{R: 255, A: 255}) || right != (color.NRGBA{B: 255, A: 255}) {
+			t.Errorf("got thumbnail %v of %s with pixels %v and %v", bounds, c.version, left, right)
+		}
+	}
+	for _, version := range []string{"3.0.0", "4.0.0", "5.0.0"} {
+		if _, err := os.Stat(filepath.Join(directory, "thumbnails/pets", version+".png")); !os.IsNotExist(err) {
+			t.Errorf("got a thumbnail for %s: %v", version, err)
+		}
+	}
+}
+
+func TestScaleImage(t *testing.T) {
+	source := image.NewNRGBA(image.Rect(0, 0, 4, 2))
+	source.SetNRGBA(0, 0, color.NRGBA{R: 200, A: 255})
+	source.SetNRGBA(1, 0, color.NRGBA{R: 100, A: 255})
+	source.SetNRGBA(0, 1, color.NRGBA{G: 255, A: 255})
+
+	scaled := scaleImage(source, 2)
+	if bounds := scaled.Bounds(); bounds.Dx() != 2 || bounds.Dy() != 1 {
+		t.Fatalf("got bounds %v", bounds)
+	}
+	// The transparent pixels do not darken the average, but make it partially transparent.
+	if pixel := color.NRGBAModel.Convert(scaled.At(0, 0)).(color.NRGBA); pixel.A != 191 || pixel.R != 100 || pixel.G != 85 {
+		t.Errorf("got pixel %v", pixel)
+	}
+	if pixel := color.NRGBAModel.Convert(scaled.At(1, 0)).(color.NRGBA); pixel.A != 0 {
+		t.Errorf("got pixel %v for a transparent area", pixel)
+	}
+	if scaleImage(source, 4) != image.Image(source) || scaleImage(source, 0) != image.Image(source) {
+		t.Error("image fitting in the size scaled")
+	}
+}
@@ -0,0 +1,503 @@
+package oas
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ChangelogFormat is the output format of a changelog.
+type ChangelogFormat string
+
+const (
+	ChangelogFormatMarkdown ChangelogFormat = "markdown"
+	ChangelogFormatHtml     ChangelogFormat = "html"
+	ChangelogFormatJson     ChangelogFormat = "json"
+)
+
+// ParseChangelogFormat converts a flag value into a ChangelogFormat.
+func ParseChangelogFormat(value string) (ChangelogFormat, error) {
+	switch ChangelogFormat(value) {
+	case ChangelogFormatMarkdown, ChangelogFormatHtml, ChangelogFormatJson:
+		return ChangelogFormat(value), nil
+	case "md":
+		return ChangelogFormatMarkdown, nil
+	default:
+		return "", fmt.Errorf("invalid changelog format <%s>: expected markdown, html or json", value)
+	}
+}
+
+type ChangelogOpts struct {
+	// Repository containing the versions of the specification: a directory or an http(s) URL.
+	Repository string
+
+	// Name is the identifier or an alias of the specification in the repository.
+	Name   string
+	Format ChangelogFormat
+
+	// OutputFile receiving the changelog, "-" for stdout.
+	OutputFile string
+}
+
+func NewChangelogOpts() *ChangelogOpts {
+	return &ChangelogOpts{
+		Repository: ".",
+		Format:     ChangelogFormatMarkdown,
+		OutputFile: "-",
+	}
+}
+
+// Change is a structural difference between two versions of a specification.
+type Change struct {
+	Kind     string `yaml:"kind" json:"kind"`
+	Location string `yaml:"location" json:"location"`
+	Message  string `yaml:"message" json:"message"`
+	Breaking bool   `yaml:"breaking" json:"breaking"`
+}
+
+const (
+	ChangeKindAdded   = "added"
+	ChangeKindRemoved = "removed"
+	ChangeKindChanged = "changed"
+)
+
+// ChangelogRelease lists the changes of a version compared with the previous one.
+type ChangelogRelease struct {
+	Version         string   `yaml:"version" json:"version"`
+	PreviousVersion string   `yaml:"previousVersion,omitempty" json:"previousVersion,omitempty"`
+	Changes         []Change `yaml:"changes" json:"changes"`
+}
+
+// Breaking tells whether a release contains breaking changes.
+func (r ChangelogRelease) Breaking() bool {
+	for _, change := range r.Changes {
+		if change.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+type Changelog struct {
+	Id       string             `yaml:"id" json:"id"`
+	Title    string             `yaml:"title" json:"title"`
+	Releases []ChangelogRelease `yaml:"releases" json:"releases"`
+}
+
+// GenerateChangelog writes the changelog of a specification across its versions in a repository.
+func GenerateChangelog(opts *ChangelogOpts) error {
+	log.Infof("Generating changelog of %s from repository: %s.", opts.Name, opts.Repository)
+
+	// Open repository
+	repository, err := OpenRepository(opts.Repository)
+	if err != nil {
+		return err
+	}
+	id, entries := repository.Index.GetSpecificationEntries(opts.Name)
+	if len(entries) == 0 {
+		return fmt.Errorf("specification <%s> not found in repository <%s>", opts.Name, opts.Repository)
+	}
+
+	// Load versions, latest first
+	sources := []*OAS3Source{}
+	for i := range entries {
+		content, location, err := repository.Fetch(&entries[i])
+		if err != nil {
+			return err
+		}
+		source, err := ParseBytes(location, content)
+		if err != nil {
+			return fmt.Errorf("specification <%s>: %v", location, err)
+		}
+		sources = append(sources, source)
+	}
+
+	// Render
+	changelog := BuildChangelog(id, sources)
+	content, err := changelog.Render(opts.Format)
+	if err != nil {
+		return err
+	}
+	if opts.OutputFile == "-" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+	log.Debugf("Writing %s.", opts.OutputFile)
+	return ioutil.WriteFile(opts.OutputFile, content, 0644)
+}
+
+// BuildChangelog compares each version of a specification with the previous one. Sources are sorted
+// from the latest version to the oldest, as in the index.
+func BuildChangelog(id string, sources []*OAS3Source) *Changelog {
+	changelog := &Changelog{Id: id, Releases: []ChangelogRelease{}}
+	if len(sources) > 0 {
+		changelog.Title = sources[0].specification.Info.Title
+	}
+	for i, source := range sources {
+		release := ChangelogRelease{Version: source.specification.Info.Version, Changes: []Change{}}
+		if i+1 < len(sources) {
+			release.PreviousVersion = sources[i+1].specification.Info.Version
+			release.Changes = DiffSpecifications(sources[i+1].specification, source.specification)
+		}
+		changelog.Releases = append(changelog.Releases, release)
+	}
+	return changelog
+}
+
+// DiffSpecifications lists the operations and component schemas added, removed or changed between
+// two versions of a specification. Changes which may break existing clients are flagged.
+func DiffSpecifications(previous *OAS3Specification, current *OAS3Specification) []Change {
+	differ := &specificationDiffer{previous: previous, current: current, changes: []Change{}}
+
+	// Operations
+	previousOperations := operationsByKey(previous)
+	currentOperations := operationsByKey(current)
+	for _, key := range unionKeys(previousOperations, currentOperations) {
+		previousOperation, currentOperation := previousOperations[key], currentOperations[key]
+		switch {
+		case previousOperation == nil:
+			differ.add(ChangeKindAdded, key, "operation added", false)
+		case currentOperation == nil:
+			differ.add(ChangeKindRemoved, key, "operation removed", true)
+		default:
+			differ.diffOperation(key, previousOperation.Operation, currentOperation.Operation)
+		}
+	}
+
+	// Component schemas
+	for _, name := range unionKeys(previous.Components.Schemas, current.Components.Schemas) {
+		previousSchema, currentSchema := previous.Components.Schemas[name], current.Components.Schemas[name]
+		switch {
+		case previousSchema == nil:
+			differ.add(ChangeKindAdded, "schema "+name, "schema added", false)
+		case currentSchema == nil:
+			differ.add(ChangeKindRemoved, "schema "+name, "schema removed", true)
+		default:
+			differ.diffSchema("schema "+name, previousSchema, currentSchema, 0)
+		}
+	}
+	return differ.changes
+}
+
+type specificationDiffer struct {
+	previous *OAS3Specification
+	current  *OAS3Specification
+	changes  []Change
+}
+
+func (d *specificationDiffer) add(kind string, location string, message string, breaking bool) {
+	d.changes = append(d.changes, Change{Kind: kind, Location: location, Message: message, Breaking: breaking})
+}
+
+func (d *specificationDiffer) diffOperation(location string, previous *OAS3Operation, current *OAS3Operation) {
+	if !previous.Deprecated && current.Deprecated {
+		d.add(ChangeKindChanged, location, "operation deprecated", false)
+	}
+	if previous.OperationId != current.OperationId {
+		d.add(ChangeKindChanged, location, fmt.Sprintf("operation id changed from %s to %s", quoted(previous.OperationId), quoted(current.OperationId)), false)
+	}
+
+	// Parameters
+	previousParameters := d.parametersByKey(d.previous, previous)
+	currentParameters := d.parametersByKey(d.current, current)
+	for _, key := range unionKeys(previousParameters, currentParameters) {
+		previousParameter, currentParameter := previousParameters[key], currentParameters[key]
+		switch {
+		case previousParameter == nil:
+			d.add(ChangeKindAdded, location, "parameter "+key+" added"+requiredSuffix(currentParameter.Required), currentParameter.Required)
+		case currentParameter == nil:
+			d.add(ChangeKindRemoved, location, "parameter "+key+" removed", false)
+		default:
+			if !previousParameter.Required && currentParameter.Required {
+				d.add(ChangeKindChanged, location, "parameter "+key+" is now required", true)
+			}
+			if !previousParameter.Deprecated && currentParameter.Deprecated {
+				d.add(ChangeKindChanged, location, "parameter "+key+" deprecated", false)
+			}
+			d.diffSchema(location+" parameter "+key, previousParameter.Schema, currentParameter.Schema, 0)
+		}
+	}
+
+	// Request body
+	previousBody := d.previous.ResolveRequestBody(previous.RequestBody)
+	currentBody := d.current.ResolveRequestBody(current.RequestBody)
+	switch {
+	case previousBody == nil && currentBody != nil:
+		d.add(ChangeKindAdded, location, "request body added"+requiredSuffix(currentBody.Required), currentBody.Required)
+	case previousBody != nil && currentBody == nil:
+		d.add(ChangeKindRemoved, location, "request body removed", false)
+	case previousBody != nil:
+		if !previousBody.Required && currentBody.Required {
+			d.add(ChangeKindChanged, location, "request body is now required", true)
+		}
+		d.diffContent(location+" request body", previousBody.Content, currentBody.Content, false)
+	}
+
+	// Responses
+	for _, code := range unionKeys(previous.Responses, current.Responses) {
+		previousResponse := d.previous.ResolveResponse(previous.Responses[code])
+		currentResponse := d.current.ResolveResponse(current.Responses[code])
+		switch {
+		case previousResponse == nil:
+			d.add(ChangeKindAdded, location, "response "+code+" added", false)
+		case currentResponse == nil:
+			d.add(ChangeKindRemoved, location, "response "+code+" removed", strings.HasPrefix(code, "2"))
+		default:
+			d.diffContent(location+" response "+code, previousResponse.Content, currentResponse.Content, true)
+		}
+	}
+}
+
+func (d *specificationDiffer) diffContent(location string, previous map[string]*OAS3MediaType, current map[string]*OAS3MediaType, removalBreaking bool) {
+	for _, mediaType := range unionKeys(previous, current) {
+		switch {
+		case previous[mediaType] == nil:
+			d.add(ChangeKindAdded, location, "media type "+mediaType+" added", false)
+		case current[mediaType] == nil:
+			d.add(ChangeKindRemoved, location, "media type "+mediaType+" removed", removalBreaking)
+		default:
+			d.diffSchema(location, previous[mediaType].Schema, current[mediaType].Schema, 0)
+		}
+	}
+}
+
+// diffSchema compares two schemas. References to component schemas are compared by name only, since
+// the component schemas are compared on their own.
+func (d *specificationDiffer) diffSchema(location string, previous *OAS3Schema, current *OAS3Schema, depth int) {
+	if previous == nil || current == nil || depth > 16 {
+		return
+	}
+	previousRef, previousIsRef := SchemaRefName(previous)
+	currentRef, currentIsRef := SchemaRefName(current)
+	if previousIsRef || currentIsRef {
+		if previousRef != currentRef {
+			d.add(ChangeKindChanged, location, fmt.Sprintf("schema changed from %s to %s", schemaLabel(previous), schemaLabel(current)), true)
+		}
+		return
+	}
+
+	if previous.Type != current.Type {
+		d.add(ChangeKindChanged, location, fmt.Sprintf("type changed from %s to %s", quoted(previous.Type), quoted(current.Type)), true)
+		return
+	}
+	if previous.Format != current.Format {
+		d.add(ChangeKindChanged, location, fmt.Sprintf("format changed from %s to %s", quoted(previous.Format), quoted(current.Format)), true)
+	}
+	if !previous.Deprecated && current.Deprecated {
+		d.add(ChangeKindChanged, location, "deprecated", false)
+	}
+	if !previous.Nullable && current.Nullable {
+		d.add(ChangeKindChanged, location, "now nullable", false)
+	}
+
+	// Enumerations
+	for _, value := range previous.Enum {
+		if !containsValue(current.Enum, value) && len(current.Enum) > 0 {
+			d.add(ChangeKindRemoved, location, fmt.Sprintf("enum value %v removed", value), true)
+		}
+	}
+	for _, value := range current.Enum {
+		if !containsValue(previous.Enum, value) && len(previous.Enum) > 0 {
+			d.add(ChangeKindAdded, location, fmt.Sprintf("enum value %v added", value), false)
+		}
+	}
+
+	// Properties
+	for _, name := range current.Required {
+		if !previous.IsRequired(name) && previous.Properties[name] != nil {
+			d.add(ChangeKindChanged, location, "property "+name+" is now required", true)
+		}
+	}
+	for _, name := range unionKeys(previous.Properties, current.Properties) {
+		previousProperty, currentProperty := previous.Properties[name], current.Properties[name]
+		switch {
+		case previousProperty == nil:
+			d.add(ChangeKindAdded, location, "property "+name+" added"+requiredSuffix(current.IsRequired(name)), current.IsRequired(name))
+		case currentProperty == nil:
+			d.add(ChangeKindRemoved, location, "property "+name+" removed", true)
+		default:
+			d.diffSchema(location+"."+name, previousProperty, currentProperty, depth+1)
+		}
+	}
+	d.diffSchema(location+"[]", previous.Items, current.Items, depth+1)
+}
+
+func (d *specificationDiffer) parametersByKey(specification *OAS3Specification, operation *OAS3Operation) map[string]*OAS3Parameter {
+	parameters := make(map[string]*OAS3Parameter)
+	for _, parameter := range operation.Parameters {
+		if resolved := specification.ResolveParameter(parameter); resolved != nil {
+			parameters[resolved.In+" "+resolved.Name] = resolved
+		}
+	}
+	return parameters
+}
+
+func operationsByKey(specification *OAS3Specification) map[string]*OAS3OperationRef {
+	operations := make(map[string]*OAS3OperationRef)
+	for _, operation := range specification.Operations() {
+		operations[strings.ToUpper(operation.Method)+" "+operation.Path] = operation
+	}
+	return operations
+}
+
+// unionKeys returns the sorted keys of two maps of the same type.
+func unionKeys(a interface{}, b interface{}) []string {
+	keys := []string{}
+	seen := make(map[string]bool)
+	for _, m := range []reflect.Value{reflect.ValueOf(a), reflect.ValueOf(b)} {
+		for _, key := range m.MapKeys() {
+			if !seen[key.String()] {
+				seen[key.String()] = true
+				keys = append(keys, key.String())
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, candidate := range values {
+		if valuesEqual(normalizeGenericValue(candidate), normalizeGenericValue(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+func schemaLabel(schema *OAS3Schema) string {
+	if name, ok := SchemaRefName(schema); ok {
+		return name
+	}
+	if schema.Type == "" {
+		return "an inline schema"
+	}
+	return "an inline " + schema.Type
+}
+
+func requiredSuffix(required bool) string {
+	if required {
+		return " (required)"
+	}
+	return ""
+}
+
+func quoted(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return "\"" + value + "\""
+}
+
+// Directory of the repository receiving the published changelogs.
+const changelogsDirectory = "changelogs"
+
+// buildChangelogArtifacts renders the changelog of each identifier in Markdown, named
+// changelogs/<id>.md, and references it from the entries. Entries must be sorted.
+func buildChangelogArtifacts(o *IndexOpts, index *V1_RepositoryIndex, resolver *conflictResolver, sources map[string]*OAS3Source) (map[string][]byte, error) {
+	artifacts := make(map[string][]byte)
+	for id, entries := range index.Entries {
+		entrySources := []*OAS3Source{}
+		for i := range entries {
+			if source := sources[resolver.sources[id+"@"+entryVersionKey(&entries[i])]]; source != nil {
+				entrySources = append(entrySources, source)
+			}
+		}
+
+		name := changelogsDirectory + "/" + id + ".md"
+		content, err := BuildChangelog(id, entrySources).Render(ChangelogFormatMarkdown)
+		if err != nil {
+			return nil, err
+		}
+		artifacts[name] = content
+
+		for i := range entries {
+			entries[i].ChangelogUrl = o.publicUrl(name)
+		}
+	}
+	return artifacts, nil
+}
+
+// Render formats the changelog in Markdown, HTML or JSON.
+func (c *Changelog) Render(format ChangelogFormat) ([]byte, error) {
+	switch format {
+	case ChangelogFormatJson:
+		return marshallCanonicalJson(c)
+	case ChangelogFormatHtml:
+		buffer := &bytes.Buffer{}
+		err := changelogHtmlTemplate.Execute(buffer, c)
+		return buffer.Bytes(), err
+	default:
+		return c.markdown(), nil
+	}
+}
+
+func (c *Changelog) markdown() []byte {
+	buffer := &bytes.Buffer{}
+	fmt.Fprintf(buffer, "# Changelog of %s\n", c.Title)
+	for _, release := range c.Releases {
+		fmt.Fprintf(buffer, "\n## %s\n\n", release.Version)
+		if release.PreviousVersion == "" {
+			buffer.WriteString("Initial version.\n")
+			continue
+		}
+		if len(release.Changes) == 0 {
+			fmt.Fprintf(buffer, "No structural change since %s.\n", release.PreviousVersion)
+			continue
+		}
+		fmt.Fprintf(buffer, "Changes since %s.\n", release.PreviousVersion)
+		for _, section := range []struct {
+			title    string
+			breaking bool
+		}{{"Breaking changes", true}, {"Other changes", false}} {
+			printed := false
+			for _, change := range release.Changes {
+				if change.Breaking != section.breaking {
+					continue
+				}
+				if !printed {
+					fmt.Fprintf(buffer, "\n### %s\n\n", section.title)
+					printed = true
+				}
+				fmt.Fprintf(buffer, "- `%s`: %s\n", change.Location, change.Message)
+			}
+		}
+	}
+	return buffer.Bytes()
+}
+
+var changelogHtmlTemplate = template.Must(template.New("changelog").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Changelog of {{.Title}}</title>
+</head>
+<body>
+<h1>Changelog of {{.Title}}</h1>
+{{- range .Releases}}
+<section>
+<h2>{{.Version}}{{if .Breaking}} <small>(breaking)</small>{{end}}</h2>
+{{- if not .PreviousVersion}}
+<p>Initial version.</p>
+{{- else if not .Changes}}
+<p>No structural change since {{.PreviousVersion}}.</p>
+{{- else}}
+<p>Changes since {{.PreviousVersion}}.</p>
+<ul>
+{{- range .Changes}}
+<li class="{{.Kind}}{{if .Breaking}} breaking{{end}}"><code>{{.Location}}</code>: {{.Message}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</section>
+{{- end}}
+</body>
+</html>
+`))
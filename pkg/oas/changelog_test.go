@@ -0,0 +1,194 @@
+package oas
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// changelogTestSpecification builds a specification with a GET /pets/{id} operation and a Pet schema.
+func changelogTestSpecification(t *testing.T, operation string, pet string) *OAS3Specification {
+	t.Helper()
+	content := "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: 1.0.0\npaths:\n  /pets/{id}:\n    get:\n" + operation +
+		"components:\n  schemas:\n    Pet:\n" + pet
+	specification := &OAS3Specification{}
+	if err := yaml.Unmarshal([]byte(content), specification); err != nil {
+		t.Fatalf("invalid specification: %v\n%s", err, content)
+	}
+	return specification
+}
+
+const changelogTestOperation = `      operationId: getPet
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+        - {name: fields, in: query, schema: {type: string}}
+      responses:
+        "200":
+          description: Pet
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+`
+
+const changelogTestPet = `      type: object
+      required: [id]
+      properties:
+        id: {type: integer}
+        name: {type: string}
+        status: {type: string, enum: [available, sold]}
+`
+
+func TestDiffSpecifications(t *testing.T) {
+	cases := []struct {
+		name      string
+		operation string
+		pet       string
+		expected  []string
+	}{
+		{"identical", changelogTestOperation, changelogTestPet, []string{}},
+		{
+			"extensions ignored",
+			changelogTestOperation + "      x-unused: true\n",
+			changelogTestPet,
+			[]string{},
+		},
+		{
+			"parameter now required and renamed operation",
+			`      operationId: readPet
+      deprecated: true
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: string}}
+        - {name: fields, in: query, required: true, schema: {type: string}}
+        - {name: lang, in: header, schema: {type: string}}
+      responses:
+        "200":
+          description: Pet
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/Pet'}
+`,
+			changelogTestPet,
+			[]string{
+				"changed GET /pets/{id}: operation deprecated",
+				`changed GET /pets/{id}: operation id changed from "getPet" to "readPet"`,
+				"added GET /pets/{id}: parameter header lang added",
+				`changed GET /pets/{id} parameter path id: type changed from "integer" to "string" (breaking)`,
+				"changed GET /pets/{id}: parameter query fields is now required (breaking)",
+			},
+		},
+		{
+			"responses",
+			`      operationId: getPet
+      parameters:
+        - {name: id, in: path, required: true, schema: {type: integer}}
+      responses:
+        "404":
+          description: Not found
+`,
+			changelogTestPet,
+			[]string{
+				"removed GET /pets/{id}: parameter query fields removed",
+				"removed GET /pets/{id}: response 200 removed (breaking)",
+				"added GET /pets/{id}: response 404 added",
+			},
+		},
+		{
+			"schema properties and enums",
+			changelogTestOperation,
+			`      type: object
+      required: [id, name, tag]
+      properties:
+        id: {type: integer, format: int64}
+        name: {type: string, nullable: true}
+        status: {type: string, enum: [available, pending]}
+        tag: {type: string}
+`,
+			[]string{
+				"changed schema Pet: property name is now required (breaking)",
+				`changed schema Pet.id: format changed from none to "int64" (breaking)`,
+				"changed schema Pet.name: now nullable",
+				"removed schema Pet.status: enum value sold removed (breaking)",
+				"added schema Pet.status: enum value pending added",
+				"added schema Pet: property tag added (required) (breaking)",
+			},
+		},
+	}
+	previous := changelogTestSpecification(t, changelogTestOperation, changelogTestPet)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			current := changelogTestSpecification(t, c.operation, c.pet)
+			changes := []string{}
+			for _, change := range DiffSpecifications(previous, current) {
+				description := fmt.Sprintf("%s %s: %s", change.Kind, change.Location, change.Message)
+				if change.Breaking {
+					description += " (breaking)"
+				}
+				changes = append(changes, description)
+			}
+			if !reflect.DeepEqual(changes, c.expected) {
+				t.Errorf("got changes:\n%q\nwant:\n%q", changes, c.expected)
+			}
+		})
+	}
+}
+
+func TestDiffSpecificationsOperationsAndSchemas(t *testing.T) {
+	previous := changelogTestSpecification(t, changelogTestOperation, changelogTestPet)
+	current := changelogTestSpecification(t, changelogTestOperation, changelogTestPet)
+	current.Paths["/stores"] = current.Paths["/pets/{id}"]
+	delete(current.Paths, "/pets/{id}")
+	current.Components.Schemas["Store"] = &OAS3Schema{Type: "object"}
+	delete(current.Components.Schemas, "Pet")
+
+	expected := []Change{
+		{ChangeKindRemoved, "GET /pets/{id}", "operation removed", true},
+		{ChangeKindAdded, "GET /stores", "operation added", false},
+		{ChangeKindRemoved, "schema Pet", "schema removed", true},
+		{ChangeKindAdded, "schema Store", "schema added", false},
+	}
+	if changes := DiffSpecifications(previous, current); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("got %v, want %v", changes, expected)
+	}
+}
+
+func TestBuildChangelog(t *testing.T) {
+	sources := []*OAS3Source{}
+	for _, version := range []string{"2.0.0", "1.1.0", "1.0.0"} {
+		specification := changelogTestSpecification(t, changelogTestOperation, changelogTestPet)
+		specification.Info.Version = version
+		if version == "2.0.0" {
+			delete(specification.Components.Schemas["Pet"].Properties, "name")
+		}
+		sources = append(sources, &OAS3Source{specification: specification})
+	}
+
+	changelog := BuildChangelog("pets", sources)
+	if changelog.Title != "Pets" || len(changelog.Releases) != 3 {
+		t.Fatalf("unexpected changelog %+v", changelog)
+	}
+	expected := []struct {
+		version  string
+		previous string
+		breaking bool
+	}{
+		{"2.0.0", "1.1.0", true},
+		{"1.1.0", "1.0.0", false},
+		{"1.0.0", "", false},
+	}
+	for i, release := range changelog.Releases {
+		if release.Version != expected[i].version || release.PreviousVersion != expected[i].previous || release.Breaking() != expected[i].breaking {
+			t.Errorf("release %d: got %s after %s breaking <%t>", i, release.Version, release.PreviousVersion, release.Breaking())
+		}
+	}
+}
+
+func TestParseChangelogFormat(t *testing.T) {
+	for value, expected := range map[string]ChangelogFormat{"markdown": ChangelogFormatMarkdown, "md": ChangelogFormatMarkdown, "html": ChangelogFormatHtml, "json": ChangelogFormatJson, "pdf": ""} {
+		format, err := ParseChangelogFormat(value)
+		if format != expected || (err != nil) != (expected == "") {
+			t.Errorf("<%s>: got <%s> <%v>, want <%s>", value, format, err, expected)
+		}
+	}
+}
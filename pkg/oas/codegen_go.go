@@ -0,0 +1,867 @@
+package oas
+
+import (
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type GenerateGoOpts struct {
+	SpecificationFile string
+	OutputDirectory   string
+	PackageName       string
+}
+
+func NewGenerateGoOpts() *GenerateGoOpts {
+	return &GenerateGoOpts{
+		OutputDirectory: ".",
+		PackageName:     "api",
+	}
+}
+
+// GenerateGo generates typed Go models from the component schemas of a specification, along with
+// a client and a server interface exposed through a net/http handler.
+func GenerateGo(opts *GenerateGoOpts) error {
+	log.Infof("Generating Go code for specification: %s.", opts.SpecificationFile)
+
+	// Parse specification
+	oas3Source, err := ParseFile(opts.SpecificationFile)
+	if err != nil {
+		return err
+	}
+
+	// Generate files
+	generator := newGoGenerator(oas3Source.specification)
+	files := map[string]string{
+		"models.go": generator.models(opts.PackageName),
+		"client.go": generator.client(opts.PackageName),
+		"server.go": generator.server(opts.PackageName),
+	}
+
+	// Write files
+	err = os.MkdirAll(opts.OutputDirectory, 0755)
+	if err != nil {
+		return err
+	}
+	names := []string{"models.go", "client.go", "server.go"}
+	for _, name := range names {
+		source, err := format.Source([]byte(files[name]))
+		if err != nil {
+			return fmt.Errorf("generated %s is not valid Go code: %v", name, err)
+		}
+		path := filepath.Join(opts.OutputDirectory, name)
+		log.Debugf("Writing %s.", path)
+		err = ioutil.WriteFile(path, source, 0644)
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Infof("Generation complete.")
+	return nil
+}
+
+// goGenerator produces Go source code from a specification.
+type goGenerator struct {
+	specification *OAS3Specification
+
+	// Named types to declare, by name, and their declaration order.
+	types     map[string]string
+	typeOrder []string
+
+	// Named types referenced while being declared, which cannot be declared as aliases.
+	recursiveTypes map[string]bool
+}
+
+func newGoGenerator(specification *OAS3Specification) *goGenerator {
+	return &goGenerator{
+		specification:  specification,
+		types:          make(map[string]string),
+		recursiveTypes: make(map[string]bool),
+	}
+}
+
+// goOperation describes the Go view of an operation.
+type goOperation struct {
+	Name         string
+	Method       string
+	Path         string
+	Summary      string
+	Parameters   []*goParameter
+	BodyType     string
+	BodyMedia    string
+	BodyRequired bool
+	Responses    []*goResponse
+}
+
+type goParameter struct {
+	Name     string
+	Field    string
+	In       string
+	Type     string
+	Required bool
+}
+
+type goResponse struct {
+	Code  string
+	Field string
+	Type  string
+}
+
+func (g *goGenerator) header(packageName string, imports ...string) string {
+	var b strings.Builder
+	b.WriteString("// Code generated by j3 oas generate go. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", packageName)
+	if len(imports) > 0 {
+		b.WriteString("import (\n")
+		for _, importPath := range imports {
+			fmt.Fprintf(&b, "\t%q\n", importPath)
+		}
+		b.WriteString(")\n\n")
+	}
+	return b.String()
+}
+
+func (g *goGenerator) models(packageName string) string {
+	// Component schemas
+	names := make([]string, 0, len(g.specification.Components.Schemas))
+	for name := range g.specification.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.declareType(goName(name), g.specification.Components.Schemas[name])
+	}
+
+	// Inline schemas of the operations
+	g.operations()
+
+	var body strings.Builder
+	for _, name := range g.typeOrder {
+		body.WriteString(g.types[name])
+		body.WriteString("\n")
+	}
+
+	imports := []string{}
+	if strings.Contains(body.String(), "time.Time") {
+		imports = append(imports, "time")
+	}
+	if strings.Contains(body.String(), "json.RawMessage") {
+		imports = append([]string{"encoding/json"}, imports...)
+	}
+	return g.header(packageName, imports...) + body.String()
+}
+
+// declareType declares a named Go type for a schema.
+func (g *goGenerator) declareType(name string, schema *OAS3Schema) {
+	if _, found := g.types[name]; found {
+		return
+	}
+	g.types[name] = ""
+	g.typeOrder = append(g.typeOrder, name)
+
+	var b strings.Builder
+	resolvedSchema := g.specification.ResolveSchema(schema)
+	writeGoComment(&b, name, resolvedSchema)
+
+	switch {
+	case resolvedSchema == nil:
+		fmt.Fprintf(&b, "type %s = interface{}\n", name)
+	case schema.Ref != "":
+		fmt.Fprintf(&b, "type %s = %s\n", name, g.goType(schema, name))
+	case isGoStruct(g.specification, resolvedSchema):
+		fmt.Fprintf(&b, "type %s struct {\n", name)
+		for _, property := range g.structProperties(resolvedSchema) {
+			propertyType := g.goType(property.schema, name+goName(property.name))
+			if !property.required && !strings.HasPrefix(propertyType, "[]") && !strings.HasPrefix(propertyType, "map[") && propertyType != "interface{}" && propertyType != "json.RawMessage" {
+				propertyType = "*" + propertyType
+			}
+			tag := property.name
+			if !property.required {
+				tag += ",omitempty"
+			}
+			if description := g.description(property.schema); description != "" {
+				fmt.Fprintf(&b, "\t// %s\n", description)
+			}
+			fmt.Fprintf(&b, "\t%s %s `json:\"%s\"`\n", goName(property.name), propertyType, tag)
+		}
+		b.WriteString("}\n")
+	case len(resolvedSchema.Enum) > 0 && resolvedSchema.Type == "string":
+		fmt.Fprintf(&b, "type %s string\n\nconst (\n", name)
+		for _, value := range resolvedSchema.Enum {
+			fmt.Fprintf(&b, "\t%s%s %s = %q\n", name, goName(fmt.Sprint(value)), name, fmt.Sprint(value))
+		}
+		b.WriteString(")\n")
+	default:
+		// Aliases keep the methods of the target type, such as the JSON encoding of json.RawMessage
+		// and time.Time. Recursive types such as a list of itself must be defined types.
+		target := g.goType(resolvedSchema, name+"Item")
+		if g.recursiveTypes[name] {
+			fmt.Fprintf(&b, "type %s %s\n", name, target)
+		} else {
+			fmt.Fprintf(&b, "type %s = %s\n", name, target)
+		}
+	}
+
+	g.types[name] = b.String()
+}
+
+type goProperty struct {
+	name     string
+	schema   *OAS3Schema
+	required bool
+}
+
+// structProperties returns the properties of an object schema, including those of its allOf schemas.
+func (g *goGenerator) structProperties(schema *OAS3Schema) []goProperty {
+	properties := []goProperty{}
+	seen := make(map[string]bool)
+
+	var collect func(schema *OAS3Schema, depth int)
+	collect = func(schema *OAS3Schema, depth int) {
+		schema = g.specification.ResolveSchema(schema)
+		if schema == nil || depth > 16 {
+			return
+		}
+		for _, subSchema := range schema.AllOf {
+			collect(subSchema, depth+1)
+		}
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			properties = append(properties, goProperty{name: name, schema: schema.Properties[name], required: schema.IsRequired(name)})
+		}
+	}
+	collect(schema, 0)
+	return properties
+}
+
+func isGoStruct(specification *OAS3Specification, schema *OAS3Schema) bool {
+	if len(schema.Properties) > 0 {
+		return true
+	}
+	if len(schema.AllOf) > 0 {
+		for _, subSchema := range schema.AllOf {
+			if resolved := specification.ResolveSchema(subSchema); resolved != nil && isGoStruct(specification, resolved) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// goType returns the Go type of a schema, declaring named types for inline objects.
+func (g *goGenerator) goType(schema *OAS3Schema, nameHint string) string {
+	if schema == nil {
+		return "interface{}"
+	}
+	if name, ok := SchemaRefName(schema); ok {
+		if g.specification.Components.Schemas[name] == nil {
+			return "interface{}"
+		}
+		if declaration, found := g.types[goName(name)]; found && declaration == "" {
+			g.recursiveTypes[goName(name)] = true
+		}
+		g.declareType(goName(name), g.specification.Components.Schemas[name])
+		return goName(name)
+	}
+
+	if len(schema.OneOf) > 0 || len(schema.AnyOf) > 0 {
+		return "json.RawMessage"
+	}
+	if isGoStruct(g.specification, schema) {
+		g.declareType(nameHint, schema)
+		return nameHint
+	}
+
+	switch schema.Type {
+	case "string":
+		switch schema.Format {
+		case "date-time":
+			return "time.Time"
+		case "binary":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if schema.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(schema.Items, nameHint+"Item")
+	case "object":
+		if additionalSchema, _ := schema.AdditionalPropertiesSchema(); additionalSchema != nil {
+			return "map[string]" + g.goType(additionalSchema, nameHint+"Value")
+		}
+		return "map[string]interface{}"
+	}
+	return "interface{}"
+}
+
+func (g *goGenerator) description(schema *OAS3Schema) string {
+	resolved := g.specification.ResolveSchema(schema)
+	if resolved == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(resolved.Description), " ")
+}
+
+func writeGoComment(b *strings.Builder, name string, schema *OAS3Schema) {
+	if schema == nil {
+		return
+	}
+	text := strings.Join(strings.Fields(schema.Description), " ")
+	if text == "" {
+		text = strings.Join(strings.Fields(schema.Title), " ")
+	}
+	if text != "" {
+		fmt.Fprintf(b, "// %s %s\n", name, text)
+	}
+}
+
+// operations returns the Go view of every operation, declaring the types of inline schemas.
+func (g *goGenerator) operations() []*goOperation {
+	operations := []*goOperation{}
+	for _, operation := range g.specification.Operations() {
+		name := goOperationName(operation)
+		goOp := &goOperation{
+			Name:    name,
+			Method:  strings.ToUpper(operation.Method),
+			Path:    operation.Path,
+			Summary: strings.Join(strings.Fields(operation.Operation.Summary), " "),
+		}
+
+		// Parameters
+		for _, parameter := range g.specification.OperationParameters(operation) {
+			if parameter.In == "cookie" {
+				continue
+			}
+			goOp.Parameters = append(goOp.Parameters, &goParameter{
+				Name:     parameter.Name,
+				Field:    goName(parameter.Name),
+				In:       parameter.In,
+				Type:     goParameterType(g.specification, parameter.Schema),
+				Required: parameter.Required || parameter.In == "path",
+			})
+		}
+
+		// Body
+		if requestBody := g.specification.ResolveRequestBody(operation.Operation.RequestBody); requestBody != nil {
+			mediaTypeName, mediaType := PreferredMediaType(requestBody.Content)
+			if mediaType != nil && IsJsonMediaType(mediaTypeName) {
+				goOp.BodyType = g.goType(mediaType.Schema, name+"RequestBody")
+				goOp.BodyMedia = mediaTypeName
+				goOp.BodyRequired = requestBody.Required
+			}
+		}
+
+		// Responses
+		for _, code := range SortedResponseCodes(operation.Operation) {
+			goResp := &goResponse{Code: code, Field: goResponseField(code)}
+			if response := g.specification.ResolveResponse(operation.Operation.Responses[code]); response != nil {
+				if mediaTypeName, mediaType := PreferredMediaType(response.Content); mediaType != nil && IsJsonMediaType(mediaTypeName) {
+					goResp.Type = g.goType(mediaType.Schema, name+strings.TrimPrefix(goResp.Field, "JSON")+"Response")
+				}
+			}
+			goOp.Responses = append(goOp.Responses, goResp)
+		}
+
+		operations = append(operations, goOp)
+	}
+	return operations
+}
+
+// goParameterType returns the Go type of a parameter: string, int32, int64, float32, float64, bool or a slice of those.
+func goParameterType(specification *OAS3Specification, schema *OAS3Schema) string {
+	schema = specification.ResolveSchema(schema)
+	if schema == nil {
+		return "string"
+	}
+	switch schema.Type {
+	case "integer":
+		if schema.Format == "int32" {
+			return "int32"
+		}
+		return "int64"
+	case "number":
+		if schema.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + goParameterType(specification, schema.Items)
+	}
+	return "string"
+}
+
+func goOperationName(operation *OAS3OperationRef) string {
+	if operation.Operation.OperationId != "" {
+		return goName(operation.Operation.OperationId)
+	}
+	return goName(operation.Method + " " + strings.NewReplacer("{", "by ", "}", "").Replace(operation.Path))
+}
+
+// requestTypes declares the request and response types of the operations.
+func (g *goGenerator) requestTypes(operations []*goOperation) string {
+	var b strings.Builder
+	for _, operation := range operations {
+		// Request
+		fmt.Fprintf(&b, "// %sRequest holds the parameters and body of %s %s.\n", operation.Name, operation.Method, operation.Path)
+		fmt.Fprintf(&b, "type %sRequest struct {\n", operation.Name)
+		for _, parameter := range operation.Parameters {
+			parameterType := parameter.Type
+			if !parameter.Required && !strings.HasPrefix(parameterType, "[]") {
+				parameterType = "*" + parameterType
+			}
+			fmt.Fprintf(&b, "\t%s %s // %s parameter %q\n", parameter.Field, parameterType, parameter.In, parameter.Name)
+		}
+		if operation.BodyType != "" {
+			fmt.Fprintf(&b, "\tBody *%s\n", operation.BodyType)
+		}
+		b.WriteString("}\n\n")
+
+		// Response
+		fmt.Fprintf(&b, "// %sResponse holds the status, headers and body of a response of %s %s.\n", operation.Name, operation.Method, operation.Path)
+		fmt.Fprintf(&b, "type %sResponse struct {\n", operation.Name)
+		b.WriteString("\tStatusCode int\n\tHeader http.Header\n")
+		for _, response := range operation.Responses {
+			if response.Type != "" {
+				fmt.Fprintf(&b, "\t%s *%s\n", response.Field, response.Type)
+			}
+		}
+		b.WriteString("}\n\n")
+
+		// Body selection
+		fmt.Fprintf(&b, "func (r *%sResponse) body() interface{} {\n", operation.Name)
+		b.WriteString("\tswitch {\n")
+		for _, response := range operation.Responses {
+			if response.Type == "" {
+				continue
+			}
+			fmt.Fprintf(&b, "\tcase %s && r.%s != nil:\n\t\treturn r.%s\n", goStatusCondition(response.Code), response.Field, response.Field)
+		}
+		b.WriteString("\t}\n\treturn nil\n}\n\n")
+
+		fmt.Fprintf(&b, "func (r *%sResponse) setBody(decode func(interface{}) error) error {\n", operation.Name)
+		b.WriteString("\tswitch {\n")
+		for _, response := range operation.Responses {
+			if response.Type == "" {
+				continue
+			}
+			fmt.Fprintf(&b, "\tcase %s:\n\t\tr.%s = new(%s)\n\t\treturn decode(r.%s)\n", goStatusCondition(response.Code), response.Field, response.Type, response.Field)
+		}
+		b.WriteString("\t}\n\treturn nil\n}\n\n")
+	}
+	return b.String()
+}
+
+// goResponseField returns the name of the field holding the body of a response code, e.g. JSON200, JSON4XX or JSONDefault.
+func goResponseField(code string) string {
+	if strings.EqualFold(code, "default") {
+		return "JSONDefault"
+	}
+	return "JSON" + strings.ToUpper(code)
+}
+
+// goStatusCondition returns the condition on r.StatusCode matching a response code.
+func goStatusCondition(code string) string {
+	upperCode := strings.ToUpper(code)
+	switch {
+	case upperCode == "DEFAULT":
+		return "true"
+	case strings.HasSuffix(upperCode, "XX"):
+		return fmt.Sprintf("r.StatusCode/100 == %c", upperCode[0])
+	default:
+		return "r.StatusCode == " + code
+	}
+}
+
+func (g *goGenerator) client(packageName string) string {
+	operations := g.operations()
+
+	var b strings.Builder
+	b.WriteString(g.header(packageName, "bytes", "context", "encoding/json", "fmt", "io/ioutil", "net/http", "net/url", "strings"))
+	b.WriteString(`// Client calls the operations of the API.
+type Client struct {
+	BaseUrl    string
+	HttpClient *http.Client
+
+	// RequestEditors are applied to every request before it is sent, e.g. to add authentication.
+	RequestEditors []func(req *http.Request) error
+}
+
+func NewClient(baseUrl string) *Client {
+	return &Client{
+		BaseUrl:    strings.TrimSuffix(baseUrl, "/"),
+		HttpClient: http.DefaultClient,
+	}
+}
+
+func (c *Client) do(ctx context.Context, method string, path string, query url.Values, header http.Header, body interface{}, contentType string) (*http.Response, []byte, error) {
+	requestUrl := c.BaseUrl + path
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+
+	var reader *bytes.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+
+	var req *http.Request
+	var err error
+	if reader != nil {
+		req, err = http.NewRequestWithContext(ctx, method, requestUrl, reader)
+	} else {
+		req, err = http.NewRequestWithContext(ctx, method, requestUrl, nil)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Accept", "application/json")
+	for _, editor := range c.RequestEditors {
+		if err := editor(req); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	res, err := c.HttpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer res.Body.Close()
+	responseBody, err := ioutil.ReadAll(res.Body)
+	return res, responseBody, err
+}
+
+func formatParameter(value interface{}) string {
+	return fmt.Sprint(value)
+}
+
+`)
+	b.WriteString(g.requestTypes(operations))
+
+	for _, operation := range operations {
+		if operation.Summary != "" {
+			fmt.Fprintf(&b, "// %s %s\n", operation.Name, operation.Summary)
+		}
+		fmt.Fprintf(&b, "func (c *Client) %s(ctx context.Context, request *%sRequest) (*%sResponse, error) {\n", operation.Name, operation.Name, operation.Name)
+		fmt.Fprintf(&b, "\tpath := %q\n", operation.Path)
+		b.WriteString("\tquery := url.Values{}\n\theader := http.Header{}\n")
+		for _, parameter := range operation.Parameters {
+			g.writeClientParameter(&b, parameter)
+		}
+		b.WriteString("\n\tvar body interface{}\n")
+		if operation.BodyType != "" {
+			b.WriteString("\tif request.Body != nil {\n\t\tbody = request.Body\n\t}\n")
+		}
+		fmt.Fprintf(&b, "\tres, responseBody, err := c.do(ctx, %q, path, query, header, body, %q)\n", operation.Method, operation.BodyMedia)
+		b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n\n")
+		fmt.Fprintf(&b, "\tresponse := &%sResponse{StatusCode: res.StatusCode, Header: res.Header}\n", operation.Name)
+		b.WriteString("\tif len(responseBody) > 0 {\n")
+		b.WriteString("\t\terr = response.setBody(func(target interface{}) error { return json.Unmarshal(responseBody, target) })\n")
+		b.WriteString("\t\tif err != nil {\n\t\t\treturn response, err\n\t\t}\n\t}\n")
+		b.WriteString("\treturn response, nil\n}\n\n")
+	}
+	return b.String()
+}
+
+func (g *goGenerator) writeClientParameter(b *strings.Builder, parameter *goParameter) {
+	value := "request." + parameter.Field
+	isSlice := strings.HasPrefix(parameter.Type, "[]")
+	optional := !parameter.Required && !isSlice
+	if optional {
+		fmt.Fprintf(b, "\tif %s != nil {\n", value)
+		value = "*" + value
+	} else if isSlice {
+		fmt.Fprintf(b, "\tif len(%s) > 0 {\n", value)
+	} else {
+		b.WriteString("\t{\n")
+	}
+
+	switch parameter.In {
+	case "path":
+		fmt.Fprintf(b, "\t\tpath = strings.ReplaceAll(path, %q, url.PathEscape(formatParameter(%s)))\n", "{"+parameter.Name+"}", value)
+	case "query":
+		if isSlice {
+			fmt.Fprintf(b, "\t\tfor _, item := range %s {\n\t\t\tquery.Add(%q, formatParameter(item))\n\t\t}\n", value, parameter.Name)
+		} else {
+			fmt.Fprintf(b, "\t\tquery.Set(%q, formatParameter(%s))\n", parameter.Name, value)
+		}
+	case "header":
+		if isSlice {
+			fmt.Fprintf(b, "\t\tfor _, item := range %s {\n\t\t\theader.Add(%q, formatParameter(item))\n\t\t}\n", value, parameter.Name)
+		} else {
+			fmt.Fprintf(b, "\t\theader.Set(%q, formatParameter(%s))\n", parameter.Name, value)
+		}
+	}
+	b.WriteString("\t}\n")
+}
+
+func (g *goGenerator) server(packageName string) string {
+	operations := g.operations()
+
+	var b strings.Builder
+	b.WriteString(g.header(packageName, "context", "encoding/json", "fmt", "net/http", "net/url", "strconv", "strings"))
+
+	// Interface
+	b.WriteString("// Server is implemented by the services exposing the API.\ntype Server interface {\n")
+	for _, operation := range operations {
+		if operation.Summary != "" {
+			fmt.Fprintf(&b, "\t// %s %s\n", operation.Name, operation.Summary)
+		}
+		fmt.Fprintf(&b, "\t%s(ctx context.Context, request *%sRequest) (*%sResponse, error)\n", operation.Name, operation.Name, operation.Name)
+	}
+	b.WriteString("}\n\n")
+
+	// Router
+	b.WriteString(`// route binds a path template to the handler of an operation.
+type route struct {
+	method   string
+	segments []string
+	handle   func(server Server, w http.ResponseWriter, r *http.Request, pathParams map[string]string) error
+}
+
+// Router dispatches the requests to a Server implementation.
+type Router struct {
+	server Server
+	routes []route
+
+	// ErrorHandler writes the response of failed requests. Defaults to a plain text response.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, status int, err error)
+}
+
+// NewRouter returns a net/http handler serving the operations of the API with the given server.
+func NewRouter(server Server) *Router {
+	return &Router{
+		server: server,
+		routes: routes,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, status int, err error) {
+			http.Error(w, err.Error(), status)
+		},
+	}
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	for _, candidate := range rt.routes {
+		if candidate.method != r.Method {
+			continue
+		}
+		pathParams, ok := matchRoute(candidate.segments, segments)
+		if !ok {
+			continue
+		}
+		if err := candidate.handle(rt.server, w, r, pathParams); err != nil {
+			status := http.StatusInternalServerError
+			if _, ok := err.(*parameterError); ok {
+				status = http.StatusBadRequest
+			}
+			rt.ErrorHandler(w, r, status, err)
+		}
+		return
+	}
+	rt.ErrorHandler(w, r, http.StatusNotFound, fmt.Errorf("no operation matches %s %s", r.Method, r.URL.Path))
+}
+
+func matchRoute(templateSegments []string, segments []string) (map[string]string, bool) {
+	if len(templateSegments) != len(segments) {
+		return nil, false
+	}
+	pathParams := map[string]string{}
+	for i, templateSegment := range templateSegments {
+		if strings.HasPrefix(templateSegment, "{") && strings.HasSuffix(templateSegment, "}") {
+			value, err := url.PathUnescape(segments[i])
+			if err != nil || value == "" {
+				return nil, false
+			}
+			pathParams[templateSegment[1:len(templateSegment)-1]] = value
+		} else if templateSegment != segments[i] {
+			return nil, false
+		}
+	}
+	return pathParams, true
+}
+
+// parameterError reports a missing or malformed parameter.
+type parameterError struct {
+	name    string
+	message string
+}
+
+func (e *parameterError) Error() string {
+	return fmt.Sprintf("parameter %s %s", e.name, e.message)
+}
+
+func writeResponse(w http.ResponseWriter, status int, header http.Header, body interface{}) error {
+	for name, values := range header {
+		w.Header()[name] = values
+	}
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if body == nil {
+		w.WriteHeader(status)
+		return nil
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(body)
+}
+
+`)
+
+	// Parameter parsers
+	parsers := map[string]string{
+		"string":  "return value, nil",
+		"int32":   "v, err := strconv.ParseInt(value, 10, 32)\n\treturn int32(v), err",
+		"int64":   "return strconv.ParseInt(value, 10, 64)",
+		"float32": "v, err := strconv.ParseFloat(value, 32)\n\treturn float32(v), err",
+		"float64": "return strconv.ParseFloat(value, 64)",
+		"bool":    "return strconv.ParseBool(value)",
+	}
+	for _, parserType := range []string{"string", "int32", "int64", "float32", "float64", "bool"} {
+		fmt.Fprintf(&b, "func parse_%s(value string) (%s, error) {\n\t%s\n}\n\n", parserType, parserType, parsers[parserType])
+	}
+
+	// Routes
+	b.WriteString("var routes = []route{\n")
+	for _, operation := range operations {
+		segments := splitPath(operation.Path)
+		quotedSegments := make([]string, len(segments))
+		for i, segment := range segments {
+			quotedSegments[i] = fmt.Sprintf("%q", segment)
+		}
+		fmt.Fprintf(&b, "\t{method: %q, segments: []string{%s}, handle: handle%s},\n", operation.Method, strings.Join(quotedSegments, ", "), operation.Name)
+	}
+	b.WriteString("}\n\n")
+
+	// Handlers
+	for _, operation := range operations {
+		fmt.Fprintf(&b, "func handle%s(server Server, w http.ResponseWriter, r *http.Request, pathParams map[string]string) error {\n", operation.Name)
+		fmt.Fprintf(&b, "\trequest := &%sRequest{}\n", operation.Name)
+		usesQuery := false
+		for _, parameter := range operation.Parameters {
+			if parameter.In == "query" {
+				usesQuery = true
+			}
+		}
+		if usesQuery {
+			b.WriteString("\tquery := r.URL.Query()\n")
+		}
+		for _, parameter := range operation.Parameters {
+			g.writeServerParameter(&b, parameter)
+		}
+		if operation.BodyType != "" {
+			b.WriteString("\tif r.ContentLength != 0 {\n")
+			fmt.Fprintf(&b, "\t\trequest.Body = new(%s)\n", operation.BodyType)
+			b.WriteString("\t\tif err := json.NewDecoder(r.Body).Decode(request.Body); err != nil {\n\t\t\treturn &parameterError{name: \"body\", message: err.Error()}\n\t\t}\n")
+			if operation.BodyRequired {
+				b.WriteString("\t} else {\n\t\treturn &parameterError{name: \"body\", message: \"is required\"}\n")
+			}
+			b.WriteString("\t}\n")
+		}
+		fmt.Fprintf(&b, "\n\tresponse, err := server.%s(r.Context(), request)\n", operation.Name)
+		b.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n")
+		b.WriteString("\treturn writeResponse(w, response.StatusCode, response.Header, response.body())\n}\n\n")
+	}
+	return b.String()
+}
+
+func (g *goGenerator) writeServerParameter(b *strings.Builder, parameter *goParameter) {
+	var source string
+	switch parameter.In {
+	case "path":
+		source = fmt.Sprintf("[]string{pathParams[%q]}", parameter.Name)
+	case "query":
+		source = fmt.Sprintf("query[%q]", parameter.Name)
+	case "header":
+		source = fmt.Sprintf("r.Header.Values(%q)", parameter.Name)
+	}
+
+	isSlice := strings.HasPrefix(parameter.Type, "[]")
+	itemType := strings.TrimPrefix(parameter.Type, "[]")
+	field := "request." + parameter.Field
+
+	fmt.Fprintf(b, "\tif values := %s; len(values) > 0 && values[0] != \"\" {\n", source)
+	if isSlice {
+		b.WriteString("\t\tif len(values) == 1 {\n\t\t\tvalues = strings.Split(values[0], \",\")\n\t\t}\n")
+		b.WriteString("\t\tfor _, value := range values {\n")
+		fmt.Fprintf(b, "\t\t\titem, err := parse_%s(value)\n", itemType)
+		fmt.Fprintf(b, "\t\t\tif err != nil {\n\t\t\t\treturn &parameterError{name: %q, message: err.Error()}\n\t\t\t}\n", parameter.Name)
+		fmt.Fprintf(b, "\t\t\t%s = append(%s, item)\n\t\t}\n", field, field)
+	} else {
+		fmt.Fprintf(b, "\t\tvalue, err := parse_%s(values[0])\n", itemType)
+		fmt.Fprintf(b, "\t\tif err != nil {\n\t\t\treturn &parameterError{name: %q, message: err.Error()}\n\t\t}\n", parameter.Name)
+		if parameter.Required {
+			fmt.Fprintf(b, "\t\t%s = value\n", field)
+		} else {
+			fmt.Fprintf(b, "\t\t%s = &value\n", field)
+		}
+	}
+	if parameter.Required {
+		fmt.Fprintf(b, "\t} else {\n\t\treturn &parameterError{name: %q, message: \"is required\"}\n", parameter.Name)
+	}
+	b.WriteString("\t}\n")
+}
+
+// goName converts an identifier such as "pet_id", "list-pets" or "200" into an exported Go name.
+func goName(value string) string {
+	var b strings.Builder
+	upperNext := true
+	for _, r := range value {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upperNext = true
+			continue
+		}
+		if upperNext {
+			b.WriteRune(unicode.ToUpper(r))
+			upperNext = false
+		} else {
+			b.WriteRune(r)
+		}
+	}
+
+	name := b.String()
+	if name == "" {
+		return "Value"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		return "N" + name
+	}
+	return name
+}
@@ -0,0 +1,163 @@
+package oas
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const codegenTestSpecification = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+        - name: tags
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+      responses:
+        "200":
+          description: Pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+        default:
+          description: Error
+          content:
+            application/json:
+              schema:
+                type: object
+                properties:
+                  message:
+                    type: string
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, born]
+      properties:
+        id:
+          type: integer
+        born:
+          $ref: '#/components/schemas/Stamp'
+        mixed:
+          $ref: '#/components/schemas/Mixed'
+        color:
+          $ref: '#/components/schemas/Color'
+        tree:
+          $ref: '#/components/schemas/Tree'
+    Stamp:
+      type: string
+      format: date-time
+    Mixed:
+      oneOf:
+        - type: string
+        - type: object
+          properties:
+            name:
+              type: string
+    Color:
+      type: string
+      enum: [red, green]
+    Names:
+      type: array
+      items:
+        type: string
+    Tree:
+      type: array
+      items:
+        $ref: '#/components/schemas/Tree'
+`
+
+// codegenTestRoundTrip is compiled along with the generated code to check the JSON encoding of its types.
+const codegenTestRoundTrip = `package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRoundTrip(t *testing.T) {
+	var stamp Stamp = time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)
+	encodedStamp, err := json.Marshal(stamp)
+	if err != nil || string(encodedStamp) != ` + "`" + `"2021-03-04T05:06:07Z"` + "`" + ` {
+		t.Fatalf("got %s %v", encodedStamp, err)
+	}
+
+	input := ` + "`" + `{"born":"2021-03-04T05:06:07Z","color":"red","id":7,"mixed":{"name":"Rex"},"tree":[[],[[]]]}` + "`" + `
+	var pet Pet
+	if err := json.Unmarshal([]byte(input), &pet); err != nil {
+		t.Fatal(err)
+	}
+	if !time.Time(pet.Born).Equal(stamp) || *pet.Color != ColorRed || len(*pet.Tree) != 2 {
+		t.Fatalf("got %+v", pet)
+	}
+	output, err := json.Marshal(pet)
+	if err != nil || string(output) != input {
+		t.Fatalf("got %s %v", output, err)
+	}
+}
+`
+
+func TestGenerateGo(t *testing.T) {
+	directory := t.TempDir()
+	writeTestFile(t, filepath.Join(directory, "openapi.yaml"), codegenTestSpecification)
+	opts := NewGenerateGoOpts()
+	opts.SpecificationFile = filepath.Join(directory, "openapi.yaml")
+	opts.OutputDirectory = filepath.Join(directory, "api")
+	if err := GenerateGo(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Check the generated files and the declarations of the non-struct types.
+	models := ""
+	for _, name := range []string{"models.go", "client.go", "server.go"} {
+		content, err := ioutil.ReadFile(filepath.Join(opts.OutputDirectory, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := parser.ParseFile(token.NewFileSet(), name, content, 0); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if name == "models.go" {
+			models = string(content)
+		}
+	}
+	for _, declaration := range []string{"type Stamp = time.Time", "type Mixed = json.RawMessage", "type Names = []string", "type Tree []Tree", "type Color string"} {
+		if !strings.Contains(models, declaration+"\n") {
+			t.Errorf("missing declaration <%s> in:\n%s", declaration, models)
+		}
+	}
+
+	// Compile the generated code and round-trip values through it.
+	goCommand, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found, generated code not compiled")
+	}
+	writeTestFile(t, filepath.Join(opts.OutputDirectory, "go.mod"), "module api\n\ngo 1.16\n")
+	writeTestFile(t, filepath.Join(opts.OutputDirectory, "roundtrip_test.go"), codegenTestRoundTrip)
+	command := exec.Command(goCommand, "test", "./...")
+	command.Dir = opts.OutputDirectory
+	command.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	if output, err := command.CombinedOutput(); err != nil {
+		t.Fatalf("generated code does not pass: %v\n%s", err, output)
+	}
+}
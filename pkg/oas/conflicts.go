@@ -0,0 +1,206 @@
+package oas
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ConflictPolicy defines how several specifications declaring the same identifier and version are handled.
+type ConflictPolicy string
+
+// conflictReportFile is the name of the published conflict report.
+const conflictReportFile = "conflicts.json"
+
+const (
+	// ConflictPolicyError fails the indexation and reports every collision.
+	ConflictPolicyError ConflictPolicy = "error"
+
+	// ConflictPolicyKeepFirst keeps the first specification found, in lexical file order.
+	ConflictPolicyKeepFirst ConflictPolicy = "keep-first"
+
+	// ConflictPolicyKeepNewestMtime keeps the specification file modified last.
+	ConflictPolicyKeepNewestMtime ConflictPolicy = "keep-newest-mtime"
+
+	// ConflictPolicyMerge keeps the first specification and completes its empty fields with the other ones.
+	ConflictPolicyMerge ConflictPolicy = "merge"
+)
+
+// ParseConflictPolicy converts a string into a conflict policy.
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(value); policy {
+	case ConflictPolicyError, ConflictPolicyKeepFirst, ConflictPolicyKeepNewestMtime, ConflictPolicyMerge:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported conflict policy <%s>: expected one of error, keep-first, keep-newest-mtime, merge", value)
+	}
+}
+
+// IndexConflict describes specification files declaring the same identifier and version.
+type IndexConflict struct {
+	Id      string   `yaml:"id" json:"id"`
+	Version string   `yaml:"version" json:"version"`
+	Files   []string `yaml:"files" json:"files"`
+	Kept    string   `yaml:"kept" json:"kept"`
+}
+
+func (c *IndexConflict) String() string {
+	return fmt.Sprintf("%s@%s declared by %s", c.Id, c.Version, strings.Join(c.Files, ", "))
+}
+
+// conflictResolver tracks the source file of each indexed entry and resolves collisions.
+type conflictResolver struct {
+	policy    ConflictPolicy
+	sources   map[string]string
+	conflicts map[string]*IndexConflict
+	order     []string
+}
+
+func newConflictResolver(policy ConflictPolicy) *conflictResolver {
+	return &conflictResolver{
+		policy:    policy,
+		sources:   make(map[string]string),
+		conflicts: make(map[string]*IndexConflict),
+	}
+}
+
+// add adds the entry to the index, resolving any collision with an already indexed entry.
+func (c *conflictResolver) add(index *V1_RepositoryIndex, e *V1_RepositoryIndexSpecificationEntry, path string) error {
+	key := e.Id + "@" + entryVersionKey(e)
+
+	// No collision
+	keptPath, found := c.sources[key]
+	if !found {
+		c.sources[key] = path
+		index.AddSpecificationEntry(e)
+		return nil
+	}
+
+	// Record collision
+	conflict, found := c.conflicts[key]
+	if !found {
+		conflict = &IndexConflict{
+			Id:      e.Id,
+			Version: e.Version,
+			Files:   []string{keptPath},
+		}
+		c.conflicts[key] = conflict
+		c.order = append(c.order, key)
+	}
+	conflict.Files = append(conflict.Files, path)
+
+	// Resolve collision
+	existingEntry := index.FindSpecificationEntry(e.Id, entryVersionKey(e))
+	switch c.policy {
+	case ConflictPolicyKeepFirst:
+		// Nothing to do.
+	case ConflictPolicyKeepNewestMtime:
+		newer, err := isNewerFile(path, keptPath)
+		if err != nil {
+			return err
+		}
+		if newer {
+			*existingEntry = *e
+			c.sources[key] = path
+		}
+	case ConflictPolicyMerge:
+		mergeSpecificationEntries(existingEntry, e)
+	default:
+		// Error is reported once all files are processed.
+	}
+	conflict.Kept = c.sources[key]
+
+	return nil
+}
+
+// report logs the collisions and returns them, with an error if the policy does not allow them.
+func (c *conflictResolver) report() ([]IndexConflict, error) {
+	conflicts := []IndexConflict{}
+	messages := []string{}
+	for _, key := range c.order {
+		conflict := c.conflicts[key]
+		conflicts = append(conflicts, *conflict)
+		messages = append(messages, conflict.String())
+
+		if c.policy != ConflictPolicyError {
+			log.Warnf("Conflict: %s. Policy <%s> keeps <%s>.", conflict, c.policy, conflict.Kept)
+		}
+	}
+
+	if c.policy != ConflictPolicyError && len(conflicts) > 0 {
+		log.Warnf("%d conflicting specifications found with policy <%s>.", len(conflicts), c.policy)
+	}
+	if c.policy == ConflictPolicyError && len(conflicts) > 0 {
+		return conflicts, fmt.Errorf("%d conflicting specifications found: %s", len(conflicts), strings.Join(messages, "; "))
+	}
+	return conflicts, nil
+}
+
+// entryVersionKey returns the version used to detect collisions, so that "v1" and "1.0.0" collide.
+func entryVersionKey(e *V1_RepositoryIndexSpecificationEntry) string {
+	if e.NormalizedVersion != "" {
+		return e.NormalizedVersion
+	}
+	return e.Version
+}
+
+func isNewerFile(path string, otherPath string) (bool, error) {
+	fileInfo, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	otherFileInfo, err := os.Stat(otherPath)
+	if err != nil {
+		return false, err
+	}
+	return fileInfo.ModTime().After(otherFileInfo.ModTime()), nil
+}
+
+// mergeSpecificationEntries fills the empty fields of dst with the values of src and
+// concatenates keywords and tags.
+func mergeSpecificationEntries(dst *V1_RepositoryIndexSpecificationEntry, src *V1_RepositoryIndexSpecificationEntry) {
+	mergeString(&dst.BusinessCategory, src.BusinessCategory)
+	mergeString(&dst.Description, src.Description)
+	mergeString(&dst.DisplayName, src.DisplayName)
+	mergeString(&dst.LongDescription, src.LongDescription)
+	mergeString(&dst.TermsOfService, src.TermsOfService)
+	mergeString(&dst.Contact.Name, src.Contact.Name)
+	mergeString(&dst.Contact.Email, src.Contact.Email)
+	mergeString(&dst.Contact.Url, src.Contact.Url)
+	mergeString(&dst.Image.Icon, src.Image.Icon)
+	mergeString(&dst.Image.Logo, src.Image.Logo)
+	mergeString(&dst.Image.Thumbnail, src.Image.Thumbnail)
+	mergeString(&dst.License.Name, src.License.Name)
+	mergeString(&dst.License.Url, src.License.Url)
+	mergeString(&dst.Vcs.GitUrl, src.Vcs.GitUrl)
+	mergeString(&dst.Vcs.GitRevision, src.Vcs.GitRevision)
+	mergeString(&dst.Owner.Team, src.Owner.Team)
+	mergeString(&dst.Owner.OnCall, src.Owner.OnCall)
+	mergeString(&dst.Lifecycle.Stage, src.Lifecycle.Stage)
+	mergeString(&dst.Lifecycle.SunsetDate, src.Lifecycle.SunsetDate)
+	mergeString(&dst.Lifecycle.ReplacedBy, src.Lifecycle.ReplacedBy)
+	mergeString(&dst.Deprecation.Sunset, src.Deprecation.Sunset)
+	if dst.Deprecation.Operations == 0 {
+		dst.Deprecation.Operations = src.Deprecation.Operations
+	}
+	for language, translation := range src.Localized {
+		if _, found := dst.Localized[language]; !found {
+			if dst.Localized == nil {
+				dst.Localized = make(map[string]V1_RepositoryIndexLocalizedEntry)
+			}
+			dst.Localized[language] = translation
+		}
+	}
+	dst.Deprecated = dst.Deprecated || src.Deprecated
+	dst.Starred = dst.Starred || src.Starred
+	dst.Keywords = canonicalStrings(append(append([]string{}, dst.Keywords...), src.Keywords...))
+	dst.Tags = canonicalStrings(append(append([]string{}, dst.Tags...), src.Tags...))
+}
+
+func mergeString(dst *string, src string) {
+	if *dst == "" {
+		*dst = src
+	}
+}
@@ -0,0 +1,136 @@
+package oas
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseConflictPolicy(t *testing.T) {
+	cases := []struct {
+		value  string
+		policy ConflictPolicy
+		err    bool
+	}{
+		{"error", ConflictPolicyError, false},
+		{"keep-first", ConflictPolicyKeepFirst, false},
+		{"keep-newest-mtime", ConflictPolicyKeepNewestMtime, false},
+		{"merge", ConflictPolicyMerge, false},
+		{"", "", true},
+		{"keep-last", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			policy, err := ParseConflictPolicy(c.value)
+			if (err != nil) != c.err || policy != c.policy {
+				t.Errorf("got <%s> <%v>, want <%s> error <%t>", policy, err, c.policy, c.err)
+			}
+		})
+	}
+}
+
+func TestNewIndexOptsConflictPolicy(t *testing.T) {
+	if policy := NewIndexOpts().ConflictPolicy; policy != ConflictPolicyKeepFirst {
+		t.Errorf("got default policy <%s>, want <%s>", policy, ConflictPolicyKeepFirst)
+	}
+}
+
+func TestIndexConflictPolicies(t *testing.T) {
+	cases := []struct {
+		policy      ConflictPolicy
+		err         bool
+		description string
+		contact     string
+	}{
+		{ConflictPolicyKeepFirst, false, "First", ""},
+		{ConflictPolicyKeepNewestMtime, false, "Second", "ops@example.com"},
+		{ConflictPolicyMerge, false, "First", "ops@example.com"},
+		{ConflictPolicyError, true, "", ""},
+	}
+	for _, c := range cases {
+		t.Run(string(c.policy), func(t *testing.T) {
+			directory := t.TempDir()
+			writeTestFile(t, filepath.Join(directory, "a.yaml"), "openapi: 3.0.3\ninfo:\n  title: Pets\n  description: First\n  version: 1.0.0\npaths: {}\n")
+			writeTestFile(t, filepath.Join(directory, "b.yaml"), "openapi: 3.0.3\ninfo:\n  title: Pets\n  description: Second\n  version: v1\n  contact:\n    email: ops@example.com\npaths: {}\n")
+			past := time.Now().Add(-time.Hour)
+			if err := os.Chtimes(filepath.Join(directory, "a.yaml"), past, past); err != nil {
+				t.Fatal(err)
+			}
+
+			opts := NewIndexOpts()
+			opts.Directory = directory
+			opts.Formats = []string{"json"}
+			opts.ConflictPolicy = c.policy
+			err := Index(opts)
+			if c.err {
+				if err == nil || !strings.Contains(err.Error(), "a.yaml") || !strings.Contains(err.Error(), "b.yaml") {
+					t.Errorf("got error <%v>, want both files reported", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			index := readTestIndex(t, directory)
+			entries := index.Entries["pets"]
+			if len(entries) != 1 {
+				t.Fatalf("got %d entries, want 1", len(entries))
+			}
+			if entries[0].Description != c.description || entries[0].Contact.Email != c.contact {
+				t.Errorf("got description <%s> contact <%s>, want <%s> <%s>", entries[0].Description, entries[0].Contact.Email, c.description, c.contact)
+			}
+		})
+	}
+}
+
+func TestIndexConflictReport(t *testing.T) {
+	directory := t.TempDir()
+	writeTestFile(t, filepath.Join(directory, "a.yaml"), "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: 1.0.0\npaths: {}\n")
+	writeTestFile(t, filepath.Join(directory, "b.yaml"), "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: 1.0.0\npaths: {}\n")
+	writeTestFile(t, filepath.Join(directory, "c.yaml"), "openapi: 3.0.3\ninfo:\n  title: Orders\n  version: 1.0.0\npaths: {}\n")
+
+	opts := NewIndexOpts()
+	opts.Directory = directory
+	opts.Formats = []string{"json"}
+	opts.ConflictReport = true
+	for i := 0; i < 2; i++ {
+		// Index twice to check that the published report is not indexed itself.
+		if err := Index(opts); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(directory, conflictReportFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conflicts []IndexConflict
+	if err := json.Unmarshal(content, &conflicts); err != nil {
+		t.Fatal(err)
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("got %d conflicts, want 1: %s", len(conflicts), content)
+	}
+	conflict := conflicts[0]
+	if conflict.Id != "pets" || conflict.Version != "1.0.0" || len(conflict.Files) != 2 || conflict.Kept != filepath.Join(directory, "a.yaml") {
+		t.Errorf("unexpected conflict %+v", conflict)
+	}
+}
+
+func readTestIndex(t *testing.T, directory string) *V1_RepositoryIndex {
+	t.Helper()
+	content, err := ioutil.ReadFile(filepath.Join(directory, "index.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	index := NewV1_RepositoryIndex()
+	if err := json.Unmarshal(content, index); err != nil {
+		t.Fatal(err)
+	}
+	return index
+}
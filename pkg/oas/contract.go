@@ -0,0 +1,394 @@
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type ContractTestOpts struct {
+	SpecificationFile string
+
+	// BaseUrl of the service under test, including the base path of the operations if any.
+	BaseUrl string
+
+	// Headers added to every request, e.g. for authentication.
+	Headers map[string]string
+
+	// JUnitFile receiving the JUnit XML report: a file path, "-" for stdout or empty for none.
+	JUnitFile string
+
+	Client *http.Client
+}
+
+func NewContractTestOpts() *ContractTestOpts {
+	return &ContractTestOpts{
+		BaseUrl: "http://localhost:8080",
+		Headers: make(map[string]string),
+		Client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// ContractTestCase is the outcome of one request sent to the service under test.
+type ContractTestCase struct {
+	Name     string
+	Method   string
+	Path     string
+	Status   int
+	Duration time.Duration
+	Failure  string
+}
+
+type ContractTestReport struct {
+	Name     string
+	Cases    []*ContractTestCase
+	Duration time.Duration
+}
+
+// Failures returns the number of failed test cases.
+func (r *ContractTestReport) Failures() int {
+	failures := 0
+	for _, testCase := range r.Cases {
+		if testCase.Failure != "" {
+			failures++
+		}
+	}
+	return failures
+}
+
+// ContractTest sends a request built from the examples of the specification to every operation of the
+// service under test, and checks that the responses have the documented success status and match its schemas.
+func ContractTest(opts *ContractTestOpts) (*ContractTestReport, error) {
+	log.Infof("Testing service %s against specification: %s.", opts.BaseUrl, opts.SpecificationFile)
+
+	// Parse specification
+	oas3Source, err := ParseFile(opts.SpecificationFile)
+	if err != nil {
+		return nil, err
+	}
+	specification := oas3Source.specification
+	validator := NewValidator(specification)
+	faker := NewFaker(specification, 0)
+	faker.DeclaredExamples = true
+
+	// Run test cases
+	start := time.Now()
+	report := &ContractTestReport{Name: specification.Info.Title}
+	for _, operation := range specification.Operations() {
+		for _, exampleName := range requestExampleNames(specification, operation) {
+			testCase := runContractTestCase(opts, validator, faker, operation, exampleName)
+			if testCase.Failure != "" {
+				log.Warnf("FAIL %s: %s", testCase.Name, testCase.Failure)
+			} else {
+				log.Infof("PASS %s", testCase.Name)
+			}
+			report.Cases = append(report.Cases, testCase)
+		}
+	}
+	report.Duration = time.Since(start)
+
+	// Write JUnit report
+	if opts.JUnitFile != "" {
+		err = writeJUnitReport(opts.JUnitFile, report)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+// requestExampleNames returns the named examples of the request body of an operation, or a single
+// empty name when the operation does not declare several examples.
+func requestExampleNames(specification *OAS3Specification, operation *OAS3OperationRef) []string {
+	requestBody := specification.ResolveRequestBody(operation.Operation.RequestBody)
+	if requestBody == nil {
+		return []string{""}
+	}
+	_, mediaType := PreferredMediaType(requestBody.Content)
+	if mediaType == nil || len(mediaType.Examples) == 0 {
+		return []string{""}
+	}
+
+	names := make([]string, 0, len(mediaType.Examples))
+	for name := range mediaType.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func runContractTestCase(opts *ContractTestOpts, validator *Validator, faker *Faker, operation *OAS3OperationRef, exampleName string) *ContractTestCase {
+	specification := validator.Specification
+	testCase := &ContractTestCase{
+		Name:   strings.ToUpper(operation.Method) + " " + operation.Path,
+		Method: strings.ToUpper(operation.Method),
+		Path:   operation.Path,
+	}
+	if operation.Operation.OperationId != "" {
+		testCase.Name += " (" + operation.Operation.OperationId + ")"
+	}
+	if exampleName != "" {
+		testCase.Name += " [" + exampleName + "]"
+	}
+
+	// Build request
+	req, err := buildContractTestRequest(opts, faker, operation, exampleName)
+	if err != nil {
+		testCase.Failure = err.Error()
+		return testCase
+	}
+
+	// Send request
+	start := time.Now()
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		testCase.Duration = time.Since(start)
+		testCase.Failure = err.Error()
+		return testCase
+	}
+	body, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	testCase.Duration = time.Since(start)
+	testCase.Status = res.StatusCode
+	if err != nil {
+		testCase.Failure = err.Error()
+		return testCase
+	}
+
+	// Check status
+	expectedStatus, response := expectedContractResponse(specification, operation)
+	if !matchesStatus(expectedStatus, res.StatusCode) {
+		testCase.Failure = fmt.Sprintf("got status %d, expected %s", res.StatusCode, expectedStatus)
+		return testCase
+	}
+
+	// Validate response
+	if response != nil {
+		err = validator.validateResponse(response, res.Header, body)
+		if err != nil {
+			testCase.Failure = fmt.Sprintf("response %d does not match the specification: %s", res.StatusCode, err)
+		}
+	}
+	return testCase
+}
+
+// expectedContractResponse returns the status expected from an operation replayed with its examples, which is
+// its first documented success response, and that response. Any 2XX status is expected when none is documented.
+func expectedContractResponse(specification *OAS3Specification, operation *OAS3OperationRef) (string, *OAS3Response) {
+	codes := make([]string, 0, len(operation.Operation.Responses))
+	for code := range operation.Operation.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, strings.ToUpper(code))
+		}
+	}
+	if len(codes) == 0 {
+		return "2XX", nil
+	}
+
+	// Exact codes sort before the 2XX range.
+	sort.Strings(codes)
+	response, found := operation.Operation.Responses[codes[0]]
+	if !found {
+		response = operation.Operation.Responses[strings.ToLower(codes[0])]
+	}
+	return codes[0], specification.ResolveResponse(response)
+}
+
+// matchesStatus tells whether a status matches a response code, either exact (e.g. 201) or a range (e.g. 2XX).
+func matchesStatus(code string, status int) bool {
+	if strings.HasSuffix(code, "XX") {
+		return strconv.Itoa(status)[:1] == code[:1]
+	}
+	return strconv.Itoa(status) == code
+}
+
+func buildContractTestRequest(opts *ContractTestOpts, faker *Faker, operation *OAS3OperationRef, exampleName string) (*http.Request, error) {
+	specification := faker.specification
+	path := operation.Path
+	query := url.Values{}
+	header := http.Header{}
+	cookies := []*http.Cookie{}
+
+	// Parameters
+	for _, parameter := range specification.OperationParameters(operation) {
+		if !parameter.Required && parameter.In != "path" && parameter.Example == nil && len(parameter.Examples) == 0 {
+			continue
+		}
+		value := formatParameterValue(faker.ParameterExample(parameter))
+		switch parameter.In {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+parameter.Name+"}", url.PathEscape(value))
+		case "query":
+			query.Set(parameter.Name, value)
+		case "header":
+			header.Set(parameter.Name, value)
+		case "cookie":
+			cookies = append(cookies, &http.Cookie{Name: parameter.Name, Value: value})
+		}
+	}
+
+	// Body
+	var body io.Reader
+	requestBody := specification.ResolveRequestBody(operation.Operation.RequestBody)
+	if requestBody != nil {
+		mediaTypeName, mediaType := PreferredMediaType(requestBody.Content)
+		if mediaType != nil {
+			value := faker.MediaTypeExample(mediaType, exampleName, ValidationDirectionRequest)
+			if text, ok := value.(string); ok && !IsJsonMediaType(mediaTypeName) {
+				body = strings.NewReader(text)
+			} else {
+				encoded, err := json.Marshal(value)
+				if err != nil {
+					return nil, err
+				}
+				body = bytes.NewReader(encoded)
+			}
+			header.Set("Content-Type", mediaTypeName)
+		}
+	}
+
+	// Request
+	requestUrl := strings.TrimSuffix(opts.BaseUrl, "/") + path
+	if len(query) > 0 {
+		requestUrl += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(strings.ToUpper(operation.Method), requestUrl, body)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	// Cookies are sent in a single header, as required by RFC 6265.
+	for _, cookie := range cookies {
+		req.AddCookie(cookie)
+	}
+	for name, value := range opts.Headers {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+// ParameterExample returns the example of a parameter, or its first example in alphabetical order,
+// or a value faked from its schema.
+func (f *Faker) ParameterExample(parameter *OAS3Parameter) interface{} {
+	if parameter.Example != nil {
+		return normalizeGenericValue(parameter.Example)
+	}
+
+	names := make([]string, 0, len(parameter.Examples))
+	for name := range parameter.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if example := f.specification.ResolveExample(parameter.Examples[name]); example != nil && example.Value != nil {
+			return normalizeGenericValue(example.Value)
+		}
+	}
+
+	if parameter.Schema == nil {
+		if _, mediaType := PreferredMediaType(parameter.Content); mediaType != nil {
+			return f.MediaTypeExample(mediaType, "", ValidationDirectionRequest)
+		}
+	}
+	return f.Fake(parameter.Schema, ValidationDirectionRequest)
+}
+
+// formatParameterValue serializes a parameter value using the default form/simple styles.
+func formatParameterValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatParameterValue(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]interface{}:
+		encoded, _ := json.Marshal(v)
+		return string(encoded)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+func writeJUnitReport(path string, report *ContractTestReport) error {
+	suite := junitTestSuite{
+		Name:     report.Name,
+		Tests:    len(report.Cases),
+		Failures: report.Failures(),
+		Time:     fmt.Sprintf("%.3f", report.Duration.Seconds()),
+	}
+	for _, testCase := range report.Cases {
+		junitCase := junitTestCase{
+			Name:      testCase.Name,
+			ClassName: report.Name,
+			Time:      fmt.Sprintf("%.3f", testCase.Duration.Seconds()),
+		}
+		if testCase.Failure != "" {
+			junitCase.Failure = &junitFailure{Message: testCase.Failure, Content: testCase.Failure}
+		}
+		suite.TestCases = append(suite.TestCases, junitCase)
+	}
+
+	marshalled, err := xml.MarshalIndent(junitTestSuites{TestSuites: []junitTestSuite{suite}}, "", "  ")
+	if err != nil {
+		return err
+	}
+	marshalled = append([]byte(xml.Header), marshalled...)
+	marshalled = append(marshalled, '\n')
+
+	if path == "-" {
+		_, err = os.Stdout.Write(marshalled)
+		return err
+	}
+	log.Debugf("Writing JUnit report to %s.", path)
+	return ioutil.WriteFile(path, marshalled, 0644)
+}
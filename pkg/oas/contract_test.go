@@ -0,0 +1,199 @@
+package oas
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const contractTestSpecification = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    get:
+      operationId: getPet
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+          example: 7
+      responses:
+        "200":
+          description: Pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+        "404":
+          description: Not found
+        default:
+          description: Error
+          content:
+            application/json:
+              schema:
+                type: object
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+        name:
+          type: string
+`
+
+func TestContractTest(t *testing.T) {
+	cases := []struct {
+		name    string
+		status  int
+		body    string
+		failure string
+	}{
+		{"conforming response", http.StatusOK, `{"id":7,"name":"Rex"}`, ""},
+		{"non-conforming body", http.StatusOK, `{"id":"7"}`, "does not match the specification"},
+		{"documented error status", http.StatusNotFound, ``, "got status 404, expected 200"},
+		{"status only covered by default", http.StatusInternalServerError, `{}`, "got status 500, expected 200"},
+		{"other success status", http.StatusAccepted, `{"id":7,"name":"Rex"}`, "got status 202, expected 200"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var path string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				path = r.URL.Path
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(c.status)
+				w.Write([]byte(c.body))
+			}))
+			defer server.Close()
+
+			directory := t.TempDir()
+			writeTestFile(t, filepath.Join(directory, "openapi.yaml"), contractTestSpecification)
+			opts := NewContractTestOpts()
+			opts.SpecificationFile = filepath.Join(directory, "openapi.yaml")
+			opts.BaseUrl = server.URL
+			opts.JUnitFile = filepath.Join(directory, "junit.xml")
+
+			report, err := ContractTest(opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if path != "/pets/7" {
+				t.Errorf("got request path <%s>, want </pets/7>", path)
+			}
+			if len(report.Cases) != 1 {
+				t.Fatalf("got %d test cases, want 1", len(report.Cases))
+			}
+			failure := report.Cases[0].Failure
+			if c.failure == "" && failure != "" || !strings.Contains(failure, c.failure) {
+				t.Errorf("got failure <%s>, want <%s>", failure, c.failure)
+			}
+
+			junit, err := ioutil.ReadFile(opts.JUnitFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (c.failure != "") != strings.Contains(string(junit), "<failure") {
+				t.Errorf("unexpected JUnit report:\n%s", junit)
+			}
+		})
+	}
+}
+
+func TestExpectedContractResponse(t *testing.T) {
+	cases := []struct {
+		name      string
+		responses []string
+		expected  string
+	}{
+		{"exact success", []string{"201", "400", "default"}, "201"},
+		{"lowest success", []string{"204", "200"}, "200"},
+		{"exact before range", []string{"2XX", "202"}, "202"},
+		{"range", []string{"2xx", "default"}, "2XX"},
+		{"no success", []string{"default"}, "2XX"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			operation := &OAS3Operation{Responses: make(map[string]*OAS3Response)}
+			for _, code := range c.responses {
+				operation.Responses[code] = &OAS3Response{Description: code}
+			}
+			code, response := expectedContractResponse(&OAS3Specification{}, &OAS3OperationRef{Operation: operation})
+			if code != c.expected {
+				t.Errorf("got status <%s>, want <%s>", code, c.expected)
+			}
+			if (response == nil) != (c.name == "no success") {
+				t.Errorf("got response %v", response)
+			}
+		})
+	}
+}
+
+func TestMatchesStatus(t *testing.T) {
+	cases := []struct {
+		code    string
+		status  int
+		matches bool
+	}{
+		{"200", 200, true},
+		{"200", 201, false},
+		{"2XX", 204, true},
+		{"2XX", 500, false},
+	}
+	for _, c := range cases {
+		if matchesStatus(c.code, c.status) != c.matches {
+			t.Errorf("%s %d: want matches <%t>", c.code, c.status, c.matches)
+		}
+	}
+}
+
+func TestBuildContractTestRequestCookies(t *testing.T) {
+	specification := &OAS3Specification{}
+	err := yaml.Unmarshal([]byte(`openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: session
+          in: cookie
+          required: true
+          schema:
+            type: string
+          example: abc
+        - name: theme
+          in: cookie
+          schema:
+            type: string
+          example: dark
+      responses:
+        "200":
+          description: Pets
+`), specification)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := NewContractTestOpts()
+	opts.BaseUrl = "http://localhost"
+	operation := specification.Operations()[0]
+	req, err := buildContractTestRequest(opts, NewFaker(specification, 0), operation, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cookies := req.Header["Cookie"]; len(cookies) != 1 || cookies[0] != "session=abc; theme=dark" {
+		t.Errorf("got cookie headers %q, want a single header", cookies)
+	}
+}
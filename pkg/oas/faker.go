@@ -0,0 +1,605 @@
+package oas
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type ExampleOpts struct {
+	SpecificationFile string
+
+	// Target is a component schema name, an operation id or an operation such as "GET /pets/{id}".
+	Target string
+
+	// Seed of the random generator. The same seed always produces the same examples.
+	Seed  int64
+	Count int
+
+	// Request produces the request body of an operation instead of a response body.
+	Request bool
+
+	// Status of the response of an operation. Defaults to the first documented success response.
+	Status string
+
+	Writer io.Writer
+}
+
+func NewExampleOpts() *ExampleOpts {
+	return &ExampleOpts{
+		Seed:   time.Now().UnixNano(),
+		Count:  1,
+		Writer: os.Stdout,
+	}
+}
+
+// Example writes fake instances of a schema, or of the request or response body of an operation, as JSON.
+func Example(opts *ExampleOpts) error {
+	log.Infof("Generating examples of %s from specification: %s (seed %d).", opts.Target, opts.SpecificationFile, opts.Seed)
+
+	// Parse specification
+	oas3Source, err := ParseFile(opts.SpecificationFile)
+	if err != nil {
+		return err
+	}
+	specification := oas3Source.specification
+
+	// Find schema
+	schema, direction, err := specification.exampleTarget(opts.Target, opts.Request, opts.Status)
+	if err != nil {
+		return err
+	}
+
+	// Generate examples
+	faker := NewFaker(specification, opts.Seed)
+	for i := 0; i < opts.Count; i++ {
+		marshalled, err := marshallCanonicalJson(faker.Fake(schema, direction))
+		if err != nil {
+			return err
+		}
+		_, err = opts.Writer.Write(marshalled)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exampleTarget finds the schema designated by a component schema name, an operation id or a method and path.
+func (s *OAS3Specification) exampleTarget(target string, request bool, status string) (*OAS3Schema, ValidationDirection, error) {
+	if schema, found := s.Components.Schemas[target]; found {
+		return schema, ValidationDirectionResponse, nil
+	}
+
+	var operation *OAS3OperationRef
+	for _, candidate := range s.Operations() {
+		if candidate.Operation.OperationId == target || strings.EqualFold(candidate.Method+" "+candidate.Path, target) {
+			operation = candidate
+			break
+		}
+	}
+	if operation == nil {
+		return nil, ValidationDirectionResponse, fmt.Errorf("no component schema nor operation named <%s>", target)
+	}
+
+	if request {
+		requestBody := s.ResolveRequestBody(operation.Operation.RequestBody)
+		if requestBody == nil {
+			return nil, ValidationDirectionResponse, fmt.Errorf("operation <%s> has no request body", target)
+		}
+		_, mediaType := PreferredMediaType(requestBody.Content)
+		if mediaType == nil || mediaType.Schema == nil {
+			return nil, ValidationDirectionResponse, fmt.Errorf("request body of operation <%s> has no schema", target)
+		}
+		return mediaType.Schema, ValidationDirectionRequest, nil
+	}
+
+	codes := []string{status}
+	if status == "" {
+		codes = SortedResponseCodes(operation.Operation)
+	}
+	for _, code := range codes {
+		response := s.ResolveResponse(operation.Operation.Responses[code])
+		if response == nil {
+			continue
+		}
+		_, mediaType := PreferredMediaType(response.Content)
+		if mediaType != nil && mediaType.Schema != nil {
+			return mediaType.Schema, ValidationDirectionResponse, nil
+		}
+	}
+	return nil, ValidationDirectionResponse, fmt.Errorf("operation <%s> has no response body schema", target)
+}
+
+var fakeFirstNames = []string{"alice", "bob", "carol", "david", "emma", "frank", "grace", "henry", "irene", "jack"}
+var fakeLastNames = []string{"martin", "bernard", "dubois", "smith", "johnson", "garcia", "muller", "rossi", "silva", "brown"}
+var fakeWords = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet", "kilo", "lima"}
+var fakeCities = []string{"Paris", "Lyon", "Berlin", "Madrid", "Rome", "Lisbon", "London", "Dublin", "Vienna", "Oslo"}
+var fakeCountries = []string{"FR", "DE", "ES", "IT", "PT", "GB", "IE", "AT", "NO", "BE"}
+var fakeDomains = []string{"example.com", "example.org", "example.net"}
+
+// Alphabet used for the wildcards of patterns and for padding strings.
+const fakeAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// Depth from which optional properties are omitted and arrays are kept minimal, to end deep nestings.
+const fakeMaxDepth = 8
+
+// Depth at which generation stops with a null value, to end very deep schemas.
+const fakeHardMaxDepth = 32
+
+// Number of times a component schema may be nested in itself before a null value is generated, to end
+// schemas requiring themselves.
+const fakeMaxRecursion = 3
+
+// Number of strings generated from a pattern until one fits the length constraints.
+const fakePatternAttempts = 100
+
+// Width of the numeric ranges from which values are drawn exactly. Wider ranges are sampled with floats.
+const fakeMaxExactSpan = 1 << 53
+
+// Largest magnitude of a generated integer, the largest float64 that converts into an int64.
+const fakeMaxInteger = float64(1<<63 - 1024)
+
+// Faker builds random but realistic values matching the schemas of a specification. Two fakers created
+// with the same seed produce the same sequence of values.
+type Faker struct {
+	// DeclaredExamples returns the examples and defaults declared by the schemas instead of fake values.
+	DeclaredExamples bool
+
+	specification *OAS3Specification
+	random        *rand.Rand
+
+	// Component schemas being generated, and how many of them are entered recursively.
+	visiting  map[string]int
+	recursion int
+}
+
+func NewFaker(specification *OAS3Specification, seed int64) *Faker {
+	return &Faker{
+		specification: specification,
+		random:        rand.New(rand.NewSource(seed)),
+		visiting:      make(map[string]int),
+	}
+}
+
+// Fake builds a value matching a schema: enums, formats, bounds, lengths, patterns and required
+// properties are honoured; read-only (resp. write-only) properties are omitted from requests (resp. responses).
+// A Faker is not safe for concurrent use.
+func (f *Faker) Fake(schema *OAS3Schema, direction ValidationDirection) interface{} {
+	return f.fake(schema, "", direction, 0)
+}
+
+func (f *Faker) fake(schema *OAS3Schema, name string, direction ValidationDirection, depth int) interface{} {
+	if depth > fakeHardMaxDepth {
+		log.Warnf("Schema nesting deeper than %d levels, null generated.", fakeHardMaxDepth)
+		return nil
+	}
+	if refName, ok := SchemaRefName(schema); ok {
+		if f.visiting[refName] > fakeMaxRecursion {
+			log.Warnf("Schema <%s> nested more than %d times in itself, null generated: it probably requires itself.", refName, fakeMaxRecursion)
+			return nil
+		}
+		f.enter(refName)
+		defer f.leave(refName)
+	}
+	schema = f.specification.ResolveSchema(schema)
+	if schema == nil {
+		return nil
+	}
+
+	// Declared values
+	if f.DeclaredExamples && schema.Example != nil {
+		return normalizeGenericValue(schema.Example)
+	}
+	if f.DeclaredExamples && schema.Default != nil {
+		return normalizeGenericValue(schema.Default)
+	}
+
+	// Enumerations
+	if len(schema.Enum) > 0 {
+		return normalizeGenericValue(schema.Enum[f.random.Intn(len(schema.Enum))])
+	}
+
+	// Compositions
+	if len(schema.AllOf) > 0 {
+		result := make(map[string]interface{})
+		for _, subSchema := range schema.AllOf {
+			if object, ok := f.fake(subSchema, name, direction, depth+1).(map[string]interface{}); ok {
+				for key, value := range object {
+					result[key] = value
+				}
+			}
+		}
+		if len(schema.Properties) > 0 {
+			for key, value := range f.fakeObject(schema, direction, depth) {
+				result[key] = value
+			}
+		}
+		return result
+	}
+	if len(schema.OneOf) > 0 {
+		return f.fake(schema.OneOf[f.random.Intn(len(schema.OneOf))], name, direction, depth+1)
+	}
+	if len(schema.AnyOf) > 0 {
+		return f.fake(schema.AnyOf[f.random.Intn(len(schema.AnyOf))], name, direction, depth+1)
+	}
+
+	// Types
+	switch schema.Type {
+	case "string":
+		return f.fakeString(schema, name)
+	case "integer":
+		return f.fakeInteger(schema)
+	case "number":
+		return f.fakeNumber(schema)
+	case "boolean":
+		return f.random.Intn(2) == 0
+	case "array":
+		return f.fakeArray(schema, name, direction, depth)
+	default:
+		return f.fakeObject(schema, direction, depth)
+	}
+}
+
+func (f *Faker) fakeObject(schema *OAS3Schema, direction ValidationDirection, depth int) map[string]interface{} {
+	object := make(map[string]interface{})
+
+	// Sort properties so that the random sequence does not depend on the map order.
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	maxProperties := math.MaxInt32
+	if schema.MaxProperties != nil {
+		maxProperties = *schema.MaxProperties
+	}
+	minProperties := 0
+	if schema.MinProperties != nil {
+		minProperties = *schema.MinProperties
+	}
+
+	// Required properties first, then optional ones at random, less and less often in recursive schemas.
+	optionalNames := []string{}
+	for _, name := range names {
+		property := f.specification.ResolveSchema(schema.Properties[name])
+		if property != nil && ((direction == ValidationDirectionRequest && property.ReadOnly) || (direction == ValidationDirectionResponse && property.WriteOnly)) {
+			continue
+		}
+		if schema.IsRequired(name) {
+			object[name] = f.fake(schema.Properties[name], name, direction, depth+1)
+		} else {
+			optionalNames = append(optionalNames, name)
+		}
+	}
+	for i, name := range optionalNames {
+		if len(object) >= maxProperties {
+			break
+		}
+		missing := minProperties - len(object)
+		if missing >= len(optionalNames)-i || (depth < fakeMaxDepth && f.random.Intn(4<<f.recursion) < 3) {
+			object[name] = f.fake(schema.Properties[name], name, direction, depth+1)
+		}
+	}
+
+	// Maps
+	additionalSchema, _ := schema.AdditionalPropertiesSchema()
+	if additionalSchema != nil && len(schema.Properties) == 0 {
+		count := 1 + f.random.Intn(2)
+		if count < minProperties {
+			count = minProperties
+		}
+		for i := 0; len(object) < count && len(object) < maxProperties && i < 2*count; i++ {
+			key := fakeWords[f.random.Intn(len(fakeWords))]
+			if _, found := object[key]; !found {
+				object[key] = f.fake(additionalSchema, key, direction, depth+1)
+			}
+		}
+	}
+	return object
+}
+
+func (f *Faker) enter(name string) {
+	f.visiting[name]++
+	if f.visiting[name] > 1 {
+		f.recursion++
+	}
+}
+
+func (f *Faker) leave(name string) {
+	if f.visiting[name] > 1 {
+		f.recursion--
+	}
+	f.visiting[name]--
+}
+
+func (f *Faker) fakeArray(schema *OAS3Schema, name string, direction ValidationDirection, depth int) []interface{} {
+	minItems := 0
+	if schema.MinItems != nil {
+		minItems = *schema.MinItems
+	}
+	count := minItems
+	if depth < fakeMaxDepth && f.recursion == 0 {
+		count += 1 + f.random.Intn(2)
+	}
+	if schema.MaxItems != nil && count > *schema.MaxItems {
+		count = *schema.MaxItems
+	}
+
+	items := []interface{}{}
+	known := make(map[string]bool)
+	for attempts := 0; len(items) < count && attempts < 10*(count+1); attempts++ {
+		item := f.fake(schema.Items, name, direction, depth+1)
+		if schema.UniqueItems {
+			key, _ := json.Marshal(item)
+			if known[string(key)] {
+				continue
+			}
+			known[string(key)] = true
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (f *Faker) fakeInteger(schema *OAS3Schema) int64 {
+	lower, upper := f.bounds(schema, 1, 1000)
+	if schema.ExclusiveMinimum && schema.Minimum != nil {
+		lower = math.Floor(lower) + 1
+	}
+	if schema.ExclusiveMaximum && schema.Maximum != nil {
+		upper = math.Ceil(upper) - 1
+	}
+	lower, upper = math.Max(lower, -fakeMaxInteger), math.Min(upper, fakeMaxInteger)
+	step := 1.0
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		step = integerStep(*schema.MultipleOf)
+	}
+	first, last := math.Ceil(lower/step), math.Floor(upper/step)
+	if last < first {
+		return int64(math.Round(lower))
+	}
+	return int64(math.Min(math.Max(f.fakeFactor(first, last)*step, lower), upper))
+}
+
+func (f *Faker) fakeNumber(schema *OAS3Schema) float64 {
+	lower, upper := f.bounds(schema, 0, 1000)
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		step := *schema.MultipleOf
+		first, last := math.Ceil(lower/step), math.Floor(upper/step)
+		if schema.ExclusiveMinimum && first*step == lower {
+			first++
+		}
+		if schema.ExclusiveMaximum && last*step == upper {
+			last--
+		}
+		if last < first {
+			return lower
+		}
+		return f.fakeFactor(first, last) * step
+	}
+
+	// Round to two decimals for readability, staying within the bounds.
+	value := math.Round((lower+f.random.Float64()*(upper-lower))*100) / 100
+	if value < lower || value > upper || (schema.ExclusiveMinimum && value == lower) || (schema.ExclusiveMaximum && value == upper) {
+		value = (lower + upper) / 2
+	}
+	return value
+}
+
+// fakeFactor returns a random integral value between first and last. Ranges too wide to be drawn
+// exactly are sampled with floats, then snapped to an integral value.
+func (f *Faker) fakeFactor(first float64, last float64) float64 {
+	if span := last - first; span < fakeMaxExactSpan {
+		return first + float64(f.random.Int63n(int64(span)+1))
+	}
+	return math.Min(math.Floor(first+f.random.Float64()*(last-first)), last)
+}
+
+// integerStep returns the smallest integer multiple of a multipleOf constraint, which integers must be
+// multiples of. For instance 0.5 gives 1 and 2.5 gives 5.
+func integerStep(multipleOf float64) float64 {
+	for factor := 1.0; factor <= 1000; factor++ {
+		if step := multipleOf * factor; math.Abs(step-math.Round(step)) < 1e-9 {
+			return math.Round(step)
+		}
+	}
+	return math.Max(1, math.Round(multipleOf))
+}
+
+// bounds returns the range of a numeric schema, defaulting to a range of the given width.
+func (f *Faker) bounds(schema *OAS3Schema, defaultLower float64, width float64) (float64, float64) {
+	switch {
+	case schema.Minimum != nil && schema.Maximum != nil:
+		return *schema.Minimum, *schema.Maximum
+	case schema.Minimum != nil:
+		return *schema.Minimum, *schema.Minimum + width
+	case schema.Maximum != nil:
+		return *schema.Maximum - width, *schema.Maximum
+	default:
+		return defaultLower, defaultLower + width
+	}
+}
+
+func (f *Faker) fakeString(schema *OAS3Schema, name string) string {
+	if schema.Pattern != "" {
+		return f.fakePatternString(schema)
+	}
+
+	value := f.fakeFormat(schema.Format, name)
+	if schema.MinLength != nil && len(value) < *schema.MinLength {
+		for len(value) < *schema.MinLength {
+			value += string(fakeAlphabet[f.random.Intn(len(fakeAlphabet))])
+		}
+	}
+	if schema.MaxLength != nil && len(value) > *schema.MaxLength {
+		value = value[:*schema.MaxLength]
+	}
+	return value
+}
+
+func (f *Faker) fakeFormat(format string, name string) string {
+	first := fakeFirstNames[f.random.Intn(len(fakeFirstNames))]
+	last := fakeLastNames[f.random.Intn(len(fakeLastNames))]
+	date := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(f.random.Int63n(30*365*24*3600)) * time.Second)
+
+	switch format {
+	case "email":
+		return first + "." + last + "@" + fakeDomains[f.random.Intn(len(fakeDomains))]
+	case "uuid":
+		bytes := make([]byte, 16)
+		f.random.Read(bytes)
+		bytes[6] = (bytes[6] & 0x0f) | 0x40
+		bytes[8] = (bytes[8] & 0x3f) | 0x80
+		return fmt.Sprintf("%x-%x-%x-%x-%x", bytes[0:4], bytes[4:6], bytes[6:8], bytes[8:10], bytes[10:])
+	case "date-time":
+		return date.Format(time.RFC3339)
+	case "date":
+		return date.Format("2006-01-02")
+	case "time":
+		return date.Format("15:04:05")
+	case "uri", "url":
+		return "https://" + fakeDomains[f.random.Intn(len(fakeDomains))] + "/" + fakeWords[f.random.Intn(len(fakeWords))]
+	case "hostname":
+		return fakeWords[f.random.Intn(len(fakeWords))] + "." + fakeDomains[f.random.Intn(len(fakeDomains))]
+	case "ipv4":
+		return fmt.Sprintf("192.0.2.%d", 1+f.random.Intn(254))
+	case "ipv6":
+		return fmt.Sprintf("2001:db8::%x", 1+f.random.Intn(0xfffe))
+	case "byte":
+		return base64.StdEncoding.EncodeToString([]byte(fakeWords[f.random.Intn(len(fakeWords))]))
+	case "password":
+		return f.fakePattern("[A-Za-z0-9]{12}", 0)
+	}
+
+	// Guess from the property name.
+	lowerName := strings.ToLower(name)
+	switch {
+	case lowerName == "firstname" || lowerName == "givenname":
+		return strings.Title(first)
+	case lowerName == "lastname" || lowerName == "familyname":
+		return strings.Title(last)
+	case strings.HasSuffix(lowerName, "email"):
+		return first + "." + last + "@" + fakeDomains[f.random.Intn(len(fakeDomains))]
+	case strings.HasSuffix(lowerName, "name"):
+		return strings.Title(first) + " " + strings.Title(last)
+	case strings.HasSuffix(lowerName, "phone"):
+		return fmt.Sprintf("+3361%07d", f.random.Intn(10000000))
+	case lowerName == "city":
+		return fakeCities[f.random.Intn(len(fakeCities))]
+	case lowerName == "country":
+		return fakeCountries[f.random.Intn(len(fakeCountries))]
+	case strings.HasSuffix(lowerName, "url"):
+		return f.fakeFormat("uri", "")
+	}
+	return fakeWords[f.random.Intn(len(fakeWords))]
+}
+
+// fakePatternString builds a string matching the pattern of a schema and fitting its length constraints.
+// Padding or truncating would break the pattern, so strings are generated until one fits.
+func (f *Faker) fakePatternString(schema *OAS3Schema) string {
+	minLength, maxLength := 0, math.MaxInt32
+	if schema.MinLength != nil {
+		minLength = *schema.MinLength
+	}
+	if schema.MaxLength != nil {
+		maxLength = *schema.MaxLength
+	}
+
+	// Let unbounded repetitions reach the minimum length.
+	extraRepeats := 3 + minLength
+
+	var value string
+	for attempt := 0; attempt < fakePatternAttempts; attempt++ {
+		value = f.fakePattern(schema.Pattern, extraRepeats)
+		if length := utf8.RuneCountInString(value); length >= minLength && length <= maxLength {
+			return value
+		}
+	}
+	log.Warnf("No string matching pattern <%s> found with a length between %d and %d: the constraints probably conflict.", schema.Pattern, minLength, maxLength)
+	return value
+}
+
+// fakePattern builds a string matching a regular expression, or a word if the pattern cannot be parsed.
+// Unbounded repetitions occur at most extraRepeats times more than their minimum.
+func (f *Faker) fakePattern(pattern string, extraRepeats int) string {
+	regexp, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return fakeWords[f.random.Intn(len(fakeWords))]
+	}
+	builder := &strings.Builder{}
+	f.fakeRegexp(regexp.Simplify(), builder, extraRepeats)
+	return builder.String()
+}
+
+func (f *Faker) fakeRegexp(regexp *syntax.Regexp, builder *strings.Builder, extraRepeats int) {
+	switch regexp.Op {
+	case syntax.OpLiteral:
+		builder.WriteString(string(regexp.Rune))
+	case syntax.OpCharClass:
+		builder.WriteRune(f.fakeRune(regexp.Rune))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		builder.WriteByte(fakeAlphabet[f.random.Intn(len(fakeAlphabet))])
+	case syntax.OpCapture:
+		f.fakeRegexp(regexp.Sub[0], builder, extraRepeats)
+	case syntax.OpConcat:
+		for _, sub := range regexp.Sub {
+			f.fakeRegexp(sub, builder, extraRepeats)
+		}
+	case syntax.OpAlternate:
+		f.fakeRegexp(regexp.Sub[f.random.Intn(len(regexp.Sub))], builder, extraRepeats)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := 0, extraRepeats
+		switch regexp.Op {
+		case syntax.OpPlus:
+			min, max = 1, 1+extraRepeats
+		case syntax.OpQuest:
+			max = 1
+		case syntax.OpRepeat:
+			min, max = regexp.Min, regexp.Max
+			if max < 0 {
+				max = min + extraRepeats
+			}
+		}
+		for i := min + f.random.Intn(max-min+1); i > 0; i-- {
+			f.fakeRegexp(regexp.Sub[0], builder, extraRepeats)
+		}
+	}
+}
+
+// fakeRune picks a rune from the ranges of a character class, preferring printable ASCII characters.
+func (f *Faker) fakeRune(ranges []rune) rune {
+	printable := []rune{}
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lower, upper := ranges[i], ranges[i+1]
+		if lower < 0x21 {
+			lower = 0x21
+		}
+		if upper > 0x7e {
+			upper = 0x7e
+		}
+		if lower <= upper {
+			printable = append(printable, lower, upper)
+		}
+	}
+	if len(printable) > 0 {
+		ranges = printable
+	}
+	if len(ranges) == 0 {
+		return 'x'
+	}
+	i := 2 * f.random.Intn(len(ranges)/2)
+	return ranges[i] + rune(f.random.Intn(int(ranges[i+1]-ranges[i]+1)))
+}
@@ -0,0 +1,272 @@
+package oas
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+const fakerTestSpecification = `openapi: 3.0.3
+info:
+  title: Faker
+  version: 1.0.0
+paths:
+  /nodes:
+    get:
+      responses:
+        "200":
+          description: Node
+          headers:
+            X-Request-Id:
+              schema:
+                type: string
+                format: uuid
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Node'
+components:
+  schemas:
+    Node:
+      type: object
+      required: [id, next]
+      properties:
+        id:
+          type: integer
+          minimum: 1
+          maximum: 9
+        next:
+          $ref: '#/components/schemas/Node'
+    Tree:
+      type: object
+      required: [left, right]
+      properties:
+        left:
+          $ref: '#/components/schemas/Tree'
+        right:
+          $ref: '#/components/schemas/Tree'
+    Pet:
+      type: object
+      required: [email, status, secret, code]
+      properties:
+        email:
+          type: string
+          format: email
+        status:
+          type: string
+          enum: [available, sold]
+        secret:
+          type: string
+          writeOnly: true
+        code:
+          type: string
+          pattern: '^[a-z]+$'
+          minLength: 10
+          maxLength: 12
+        name:
+          type: string
+          example: Rex
+`
+
+func parseFakerTestSpecification(t *testing.T) *OAS3Specification {
+	t.Helper()
+	specification := &OAS3Specification{}
+	if err := yaml.Unmarshal([]byte(fakerTestSpecification), specification); err != nil {
+		t.Fatal(err)
+	}
+	return specification
+}
+
+func TestFakerSelfReference(t *testing.T) {
+	specification := parseFakerTestSpecification(t)
+	for _, name := range []string{"Node", "Tree"} {
+		t.Run(name, func(t *testing.T) {
+			value := NewFaker(specification, 1).Fake(specification.Components.Schemas[name], ValidationDirectionResponse)
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				t.Fatalf("got %T, want an object", value)
+			}
+
+			// The recursion ends with a null value after a few levels.
+			depth := 0
+			for ; object != nil; depth++ {
+				var property interface{}
+				if name == "Node" {
+					property = object["next"]
+				} else {
+					property = object["left"]
+				}
+				object, _ = property.(map[string]interface{})
+			}
+			if depth < 2 || depth > fakeMaxRecursion+2 {
+				t.Errorf("got %d nested levels", depth)
+			}
+		})
+	}
+}
+
+func TestFakerHardMaxDepth(t *testing.T) {
+	// Build an inline schema nesting objects deeper than the hard limit.
+	schema := &OAS3Schema{Type: "string"}
+	for i := 0; i < 2*fakeHardMaxDepth; i++ {
+		schema = &OAS3Schema{Type: "object", Required: []string{"child"}, Properties: map[string]*OAS3Schema{"child": schema}}
+	}
+	value := NewFaker(&OAS3Specification{}, 1).Fake(schema, ValidationDirectionResponse)
+	depth := 0
+	for object, ok := value.(map[string]interface{}); ok; object, ok = object["child"].(map[string]interface{}) {
+		depth++
+	}
+	if depth != fakeHardMaxDepth+1 {
+		t.Errorf("got %d nested levels, want %d", depth, fakeHardMaxDepth+1)
+	}
+}
+
+func TestFakerObject(t *testing.T) {
+	specification := parseFakerTestSpecification(t)
+	schema := specification.Components.Schemas["Pet"]
+	emailRegexp := regexp.MustCompile(`^[a-z]+\.[a-z]+@example\.(com|org|net)$`)
+	codeRegexp := regexp.MustCompile(`^[a-z]+$`)
+	for seed := int64(0); seed < 20; seed++ {
+		pet := NewFaker(specification, seed).Fake(schema, ValidationDirectionResponse).(map[string]interface{})
+		if email, _ := pet["email"].(string); !emailRegexp.MatchString(email) {
+			t.Errorf("seed %d: got email <%v>", seed, pet["email"])
+		}
+		if status := pet["status"]; status != "available" && status != "sold" {
+			t.Errorf("seed %d: got status <%v>", seed, status)
+		}
+		if _, found := pet["secret"]; found {
+			t.Errorf("seed %d: write-only property in a response", seed)
+		}
+		if code, _ := pet["code"].(string); !codeRegexp.MatchString(code) || len(code) < 10 || len(code) > 12 {
+			t.Errorf("seed %d: got code <%v>", seed, pet["code"])
+		}
+	}
+}
+
+func TestFakerSeed(t *testing.T) {
+	specification := parseFakerTestSpecification(t)
+	schema := specification.Components.Schemas["Pet"]
+	first := NewFaker(specification, 42).Fake(schema, ValidationDirectionRequest)
+	second := NewFaker(specification, 42).Fake(schema, ValidationDirectionRequest)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("same seed produced %v and %v", first, second)
+	}
+}
+
+func TestFakerDeclaredExamples(t *testing.T) {
+	specification := parseFakerTestSpecification(t)
+	schema := specification.Components.Schemas["Pet"].Properties["name"]
+	faker := NewFaker(specification, 1)
+	if value := faker.Fake(schema, ValidationDirectionResponse); value == "Rex" {
+		t.Errorf("got the declared example without DeclaredExamples")
+	}
+	faker.DeclaredExamples = true
+	if value := faker.Fake(schema, ValidationDirectionResponse); value != "Rex" {
+		t.Errorf("got <%v>, want the declared example", value)
+	}
+}
+
+func TestFakerPatternString(t *testing.T) {
+	cases := []struct {
+		name      string
+		pattern   string
+		minLength int
+		maxLength int
+	}{
+		{"fixed length", `^[A-Z]{3}-\d{2}$`, 0, 0},
+		{"fixed length conflicting with minLength", `^[A-Z]{3}-\d{2}$`, 10, 0},
+		{"unbounded with minLength", `^[a-z]+$`, 10, 0},
+		{"unbounded with both lengths", `^[a-z0-9]*$`, 5, 6},
+		{"bounded repeat", `^x{2,}y?$`, 8, 0},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			schema := &OAS3Schema{Type: "string", Pattern: c.pattern}
+			if c.minLength > 0 {
+				schema.MinLength = &c.minLength
+			}
+			if c.maxLength > 0 {
+				schema.MaxLength = &c.maxLength
+			}
+			conflicting := strings.Contains(c.name, "conflicting")
+			faker := NewFaker(&OAS3Specification{}, 3)
+			for i := 0; i < 20; i++ {
+				value := faker.Fake(schema, ValidationDirectionResponse).(string)
+				if !regexp.MustCompile(c.pattern).MatchString(value) {
+					t.Fatalf("got <%s>, not matching the pattern", value)
+				}
+				length := utf8.RuneCountInString(value)
+				if !conflicting && (length < c.minLength || (c.maxLength > 0 && length > c.maxLength)) {
+					t.Fatalf("got <%s>, not fitting the lengths", value)
+				}
+			}
+		})
+	}
+}
+
+func TestMockServerFakesPayloads(t *testing.T) {
+	specification := parseFakerTestSpecification(t)
+	opts := NewMockOpts()
+	opts.Seed = 5
+	server := httptest.NewServer(NewMockServer(specification, opts))
+	defer server.Close()
+
+	bodies := []string{}
+	for i := 0; i < 2; i++ {
+		res, err := http.Get(server.URL + "/nodes")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var node map[string]interface{}
+		err = json.NewDecoder(res.Body).Decode(&node)
+		res.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK || node["next"] == nil {
+			t.Fatalf("got %d %v", res.StatusCode, node)
+		}
+		if !regexp.MustCompile(`^[0-9a-f]{8}-`).MatchString(res.Header.Get("X-Request-Id")) {
+			t.Errorf("got header <%s>", res.Header.Get("X-Request-Id"))
+		}
+		marshalled, _ := json.Marshal(node)
+		bodies = append(bodies, string(marshalled))
+	}
+	if bodies[0] != bodies[1] {
+		t.Errorf("same seed produced %s and %s", bodies[0], bodies[1])
+	}
+}
+
+func TestFakerNumericRanges(t *testing.T) {
+	float := func(value float64) *float64 { return &value }
+	cases := []struct {
+		name   string
+		schema *OAS3Schema
+	}{
+		{"integer wider than int64 sampling", &OAS3Schema{Type: "integer", Minimum: float(-9e18), Maximum: float(9e18)}},
+		{"integer beyond int64", &OAS3Schema{Type: "integer", Minimum: float(-1e20), Maximum: float(1e20)}},
+		{"integer with fractional multipleOf", &OAS3Schema{Type: "integer", Minimum: float(1), Maximum: float(100), MultipleOf: float(2.5)}},
+		{"integer with multipleOf below one", &OAS3Schema{Type: "integer", Minimum: float(-50), Maximum: float(50), MultipleOf: float(0.3)}},
+		{"number with a fine multipleOf", &OAS3Schema{Type: "number", Minimum: float(0), Maximum: float(1e15), MultipleOf: float(0.00001)}},
+		{"number with multipleOf", &OAS3Schema{Type: "number", Minimum: float(0), Maximum: float(1), MultipleOf: float(0.25), ExclusiveMaximum: true}},
+	}
+	validator := NewValidator(&OAS3Specification{})
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			faker := NewFaker(&OAS3Specification{}, 7)
+			for i := 0; i < 50; i++ {
+				value := faker.Fake(c.schema, ValidationDirectionResponse)
+				if errs := validator.ValidateValue(c.schema, value, "value", ValidationDirectionResponse); len(errs) > 0 {
+					t.Fatalf("got <%v>: %v", value, errs)
+				}
+			}
+		})
+	}
+}
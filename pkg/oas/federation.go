@@ -0,0 +1,350 @@
+package oas
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// FederationConflictPolicy defines how specifications sharing the same identifier in several
+// federated repositories are handled.
+type FederationConflictPolicy string
+
+const (
+	// FederationConflictPolicyError fails the federation.
+	FederationConflictPolicyError FederationConflictPolicy = "error"
+
+	// FederationConflictPolicyFirst keeps the specification of the first repository listed.
+	FederationConflictPolicyFirst FederationConflictPolicy = "first"
+
+	// FederationConflictPolicyNamespace prefixes the conflicting identifiers with the name of their
+	// repository, e.g. "payments.orders" and "logistics.orders".
+	FederationConflictPolicyNamespace FederationConflictPolicy = "namespace"
+
+	// FederationConflictPolicyMerge combines the versions of all repositories under the identifier.
+	// A version published by several repositories is taken from the first one listed.
+	FederationConflictPolicyMerge FederationConflictPolicy = "merge"
+)
+
+// ParseFederationConflictPolicy converts a string into a federation conflict policy.
+func ParseFederationConflictPolicy(value string) (FederationConflictPolicy, error) {
+	switch policy := FederationConflictPolicy(value); policy {
+	case FederationConflictPolicyError, FederationConflictPolicyFirst, FederationConflictPolicyNamespace, FederationConflictPolicyMerge:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported federation conflict policy <%s>: expected one of error, first, namespace, merge", value)
+	}
+}
+
+// V1_RepositoryIndexSourceEntry attributes a federated entry to the repository publishing it.
+type V1_RepositoryIndexSourceEntry struct {
+	Name     string `yaml:"name" json:"name"`
+	Location string `yaml:"location" json:"location"`
+}
+
+type FederateOpts struct {
+	// Sources are the federated repositories, as "[name=]location" where the location is a directory,
+	// an http(s) URL or an index file. The name is an identifier and defaults to the host or directory
+	// name. The order of the sources gives their priority when resolving conflicts.
+	Sources        []string
+	ConflictPolicy FederationConflictPolicy
+
+	// Output of the federated index: a local directory, "-" for stdout or s3://bucket/prefix.
+	// Nothing is written when empty. The URLs of local repositories are made relative to the output
+	// directory, or to the working directory for other outputs.
+	Output    string
+	Formats   []string
+	Canonical bool
+
+	// Address on which the federated index is served, refreshed every RefreshInterval. The index is
+	// built once when empty.
+	Address         string
+	RefreshInterval time.Duration
+}
+
+func NewFederateOpts() *FederateOpts {
+	return &FederateOpts{
+		Sources:         []string{},
+		ConflictPolicy:  FederationConflictPolicyError,
+		Output:          "-",
+		Formats:         []string{"json"},
+		Canonical:       false,
+		RefreshInterval: 5 * time.Minute,
+	}
+}
+
+// federationSource is a repository taking part to a federation.
+type federationSource struct {
+	name       string
+	repository *Repository
+}
+
+// Federate combines the indexes of several repositories into one, and serves it when an address is given.
+func Federate(opts *FederateOpts) error {
+	log.Infof("Federating %d repositories.", len(opts.Sources))
+
+	// Build federated index
+	federatedIndex, err := FederateIndexes(opts)
+	if err != nil {
+		return err
+	}
+	err = writeFederatedIndex(opts, federatedIndex)
+	if err != nil {
+		return err
+	}
+	if opts.Address == "" {
+		return nil
+	}
+
+	// Serve and refresh periodically
+	server := &federationServer{}
+	server.update(federatedIndex)
+	if opts.RefreshInterval > 0 {
+		go func() {
+			for range time.Tick(opts.RefreshInterval) {
+				log.Debugf("Refreshing federated index.")
+				federatedIndex, err := FederateIndexes(opts)
+				if err == nil {
+					err = writeFederatedIndex(opts, federatedIndex)
+				}
+				if err != nil {
+					log.Errorf("Refreshing federated index failed, previous index kept: %v", err)
+					continue
+				}
+				server.update(federatedIndex)
+			}
+		}()
+	}
+	// The startup line goes to stderr so that it is visible whatever the log level.
+	fmt.Fprintf(os.Stderr, "Federation server listening on %s.\n", opts.Address)
+	return http.ListenAndServe(opts.Address, server)
+}
+
+// FederateIndexes reads the index of each source and combines their entries. Relative URLs are
+// rewritten to stay valid from the output of the federated index.
+func FederateIndexes(opts *FederateOpts) (*V1_RepositoryIndex, error) {
+	// Open repositories
+	sources := []*federationSource{}
+	names := make(map[string]bool)
+	providers := make(map[string][]string)
+	for _, source := range opts.Sources {
+		name, location := parseFederationSource(source)
+		repository, err := OpenRepository(location)
+		if err != nil {
+			return nil, err
+		}
+		if name == "" {
+			name = federationSourceName(location)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("several federated repositories are named <%s>", name)
+		}
+		names[name] = true
+		sources = append(sources, &federationSource{name: name, repository: repository})
+		for id := range repository.Index.Entries {
+			providers[id] = append(providers[id], name)
+		}
+	}
+
+	// Check conflicts
+	if opts.ConflictPolicy == FederationConflictPolicyError {
+		conflicts := []string{}
+		for id, names := range providers {
+			if len(names) > 1 {
+				conflicts = append(conflicts, fmt.Sprintf("<%s> in %s", id, strings.Join(names, ", ")))
+			}
+		}
+		if len(conflicts) > 0 {
+			sort.Strings(conflicts)
+			return nil, fmt.Errorf("specifications defined by several repositories: %s", strings.Join(conflicts, "; "))
+		}
+	}
+
+	// Combine entries
+	baseDirectory := "."
+	if opts.Output != "" && opts.Output != "-" && !strings.HasPrefix(opts.Output, "s3://") {
+		baseDirectory = opts.Output
+	}
+	federatedIndex := NewV1_RepositoryIndex()
+	ids := make(map[*federationSource]map[string]string)
+	for _, source := range sources {
+		ids[source] = make(map[string]string)
+		for id, entries := range source.repository.Index.Entries {
+			federatedId := id
+			if len(providers[id]) > 1 {
+				switch opts.ConflictPolicy {
+				case FederationConflictPolicyFirst:
+					if providers[id][0] != source.name {
+						log.Warnf("Specification <%s> of repository <%s> ignored, already defined by <%s>.", id, source.name, providers[id][0])
+						continue
+					}
+				case FederationConflictPolicyNamespace:
+					federatedId = source.name + "." + id
+				}
+			}
+			ids[source][id] = federatedId
+
+			for _, entry := range entries {
+				if existingEntry := federatedIndex.FindSpecificationEntry(federatedId, entryVersionKey(&entry)); existingEntry != nil {
+					log.Warnf("Version <%s> of specification <%s> of repository <%s> ignored, already defined by <%s>.", entry.Version, id, source.name, existingEntry.Source.Name)
+					continue
+				}
+				entry.Id = federatedId
+				entry.Url = source.federatedUrl(entry.Url, baseDirectory)
+				entry.ChangelogUrl = source.federatedUrl(entry.ChangelogUrl, baseDirectory)
+				entry.Image.Icon = source.federatedUrl(entry.Image.Icon, baseDirectory)
+				entry.Image.Logo = source.federatedUrl(entry.Image.Logo, baseDirectory)
+				entry.Image.Thumbnail = source.federatedUrl(entry.Image.Thumbnail, baseDirectory)
+				schemas := make([]V1_RepositoryIndexSchemaEntry, len(entry.Schemas))
+				for i, schema := range entry.Schemas {
+					schemas[i] = V1_RepositoryIndexSchemaEntry{Name: schema.Name, Url: source.federatedUrl(schema.Url, baseDirectory)}
+				}
+				if len(schemas) > 0 {
+					entry.Schemas = schemas
+				}
+				entry.Source = &V1_RepositoryIndexSourceEntry{Name: source.name, Location: source.repository.Location}
+				federatedIndex.AddSpecificationEntry(&entry)
+			}
+		}
+	}
+
+	// Combine aliases, dropping the ambiguous ones
+	ambiguousAliases := make(map[string]bool)
+	for _, source := range sources {
+		for alias, id := range source.repository.Index.Aliases {
+			federatedId, found := ids[source][id]
+			if !found || ambiguousAliases[alias] {
+				continue
+			}
+			if _, isId := federatedIndex.Entries[alias]; isId {
+				continue
+			}
+			if aliasedId, found := federatedIndex.Aliases[alias]; found && aliasedId != federatedId {
+				log.Warnf("Alias <%s> is shared by <%s> and <%s>, not federated.", alias, aliasedId, federatedId)
+				delete(federatedIndex.Aliases, alias)
+				ambiguousAliases[alias] = true
+				continue
+			}
+			federatedIndex.Aliases[alias] = federatedId
+		}
+	}
+
+	federatedIndex.SortByVersionDesc()
+	return federatedIndex, nil
+}
+
+// federatedUrl makes a relative URL of the source repository valid from the base directory of the
+// federated index: absolute for remote repositories, relative to the base directory for local ones.
+func (s *federationSource) federatedUrl(location string, baseDirectory string) string {
+	if location == "" || isHttpLocation(location) || strings.HasPrefix(location, "data:") {
+		return location
+	}
+	if isHttpLocation(s.repository.Location) {
+		base, err := url.Parse(strings.TrimSuffix(s.repository.Location, "/") + "/")
+		if err != nil {
+			return location
+		}
+		reference, err := url.Parse(strings.TrimPrefix(location, "/"))
+		if err != nil {
+			return location
+		}
+		return base.ResolveReference(reference).String()
+	}
+
+	target, err := filepath.Abs(filepath.Join(s.repository.Location, filepath.FromSlash(localUrlPath(location))))
+	if err != nil {
+		return location
+	}
+	base, err := filepath.Abs(baseDirectory)
+	if err != nil {
+		return location
+	}
+	relative, err := relativeSlashPath(base, target)
+	if err != nil {
+		return location
+	}
+	return escapeUrlPath(relative)
+}
+
+// parseFederationSource splits a "[name=]location" source. The prefix is a name only if it is a valid
+// identifier, so that URLs holding "=" in their query string are kept whole.
+func parseFederationSource(source string) (string, string) {
+	if i := strings.Index(source, "="); i > 0 && ValidateId(source[:i]) == nil {
+		return source[:i], source[i+1:]
+	}
+	return "", source
+}
+
+// federationSourceName derives the name of a repository from the host of its URL or its directory.
+func federationSourceName(location string) string {
+	if isHttpLocation(location) {
+		if parsed, err := url.Parse(location); err == nil {
+			return slugify(parsed.Hostname())
+		}
+	}
+	extension := strings.ToLower(filepath.Ext(location))
+	if extension == ".json" || extension == ".yaml" || extension == ".yml" {
+		location = filepath.Dir(location)
+	}
+	absolute, err := filepath.Abs(location)
+	if err != nil {
+		return slugify(location)
+	}
+	return slugify(filepath.Base(absolute))
+}
+
+func writeFederatedIndex(opts *FederateOpts, federatedIndex *V1_RepositoryIndex) error {
+	if opts.Output == "" {
+		return nil
+	}
+	sink, err := NewIndexSink(opts.Output)
+	if err != nil {
+		return err
+	}
+	if _, stdout := sink.(*StdoutSink); stdout && len(opts.Formats) > 1 {
+		return fmt.Errorf("a single index format can be written to stdout, got %s", strings.Join(opts.Formats, ", "))
+	}
+	if opts.Canonical {
+		federatedIndex.Canonicalize()
+	}
+	return marshallIndex(sink, opts.Formats, opts.Canonical, federatedIndex)
+}
+
+// federationServer serves the latest federated index at /index.json and /index.yaml, and searches
+// it at /search?q=.
+type federationServer struct {
+	mutex    sync.RWMutex
+	handlers map[string]http.Handler
+}
+
+func (f *federationServer) update(federatedIndex *V1_RepositoryIndex) {
+	handlers := map[string]http.Handler{
+		"/index.json": NewLocalizedIndexHandler(federatedIndex, "json"),
+		"/index.yaml": NewLocalizedIndexHandler(federatedIndex, "yaml"),
+		"/search":     NewSearchHandler(BuildSearchIndex(federatedIndex, nil)),
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	f.handlers = handlers
+}
+
+func (f *federationServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.RLock()
+	handler, found := f.handlers[r.URL.Path]
+	f.mutex.RUnlock()
+
+	if !found {
+		writeProblem(w, http.StatusNotFound, "only /index.json, /index.yaml and /search are served", nil)
+		return
+	}
+	handler.ServeHTTP(w, r)
+}
@@ -0,0 +1,114 @@
+package oas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseFederationSource(t *testing.T) {
+	cases := []struct {
+		source   string
+		name     string
+		location string
+	}{
+		{"payments=https://host/index.yaml", "payments", "https://host/index.yaml"},
+		{"team-a=repositories/a", "team-a", "repositories/a"},
+		{"https://host/index.yaml?token=a", "", "https://host/index.yaml?token=a"},
+		{"https://host/?a=b&c=d", "", "https://host/?a=b&c=d"},
+		{"Payments=repositories/a", "", "Payments=repositories/a"},
+		{"=repositories/a", "", "=repositories/a"},
+		{"repositories/a", "", "repositories/a"},
+	}
+	for _, c := range cases {
+		t.Run(c.source, func(t *testing.T) {
+			name, location := parseFederationSource(c.source)
+			if name != c.name || location != c.location {
+				t.Errorf("got <%s> <%s>, want <%s> <%s>", name, location, c.name, c.location)
+			}
+		})
+	}
+}
+
+func TestFederationSourceName(t *testing.T) {
+	for location, expected := range map[string]string{
+		"https://apis.example.com/index.json?token=a": "apis.example.com",
+		"repositories/Team A":                         "team-a",
+		"repositories/team-b/index.yaml":              "team-b",
+	} {
+		if name := federationSourceName(location); name != expected {
+			t.Errorf("<%s>: got <%s>, want <%s>", location, name, expected)
+		}
+	}
+}
+
+func TestFederateIndexes(t *testing.T) {
+	var query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		if r.URL.Path != "/repo/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("apiVersion: 1\nentries:\n  pets:\n    - id: pets\n      name: Pets\n      version: 2.0.0\n      url: pets/openapi.yaml\n"))
+	}))
+	defer server.Close()
+
+	directory := t.TempDir()
+	writeTestFile(t, filepath.Join(directory, "local", "pets", "openapi.yaml"), "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: 1.0.0\npaths: {}\n")
+	writeTestFile(t, filepath.Join(directory, "local", "orders", "openapi.yaml"), "openapi: 3.0.3\ninfo:\n  title: Orders\n  version: 1.0.0\npaths: {}\n")
+	indexOpts := NewIndexOpts()
+	indexOpts.Directory = filepath.Join(directory, "local")
+	indexOpts.Formats = []string{"json"}
+	if err := Index(indexOpts); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		policy FederationConflictPolicy
+		ids    []string
+		err    bool
+	}{
+		{FederationConflictPolicyError, nil, true},
+		{FederationConflictPolicyFirst, []string{"orders", "pets"}, false},
+		{FederationConflictPolicyNamespace, []string{"local.pets", "orders", "remote.pets"}, false},
+	}
+	for _, c := range cases {
+		t.Run(string(c.policy), func(t *testing.T) {
+			opts := NewFederateOpts()
+			opts.Sources = []string{"local=" + indexOpts.Directory, "remote=" + server.URL + "/repo/index.yaml?token=a"}
+			opts.ConflictPolicy = c.policy
+			index, err := FederateIndexes(opts)
+			if (err != nil) != c.err {
+				t.Fatalf("got error <%v>, want error <%t>", err, c.err)
+			}
+			if query != "token=a" {
+				t.Errorf("got query <%s>, want <token=a>", query)
+			}
+			if c.err {
+				return
+			}
+			if len(index.Entries) != len(c.ids) {
+				t.Fatalf("got entries %v, want %v", index.Entries, c.ids)
+			}
+			for _, id := range c.ids {
+				if len(index.Entries[id]) != 1 {
+					t.Errorf("missing entry <%s>", id)
+				}
+			}
+			if remote := index.Entries["remote.pets"]; len(remote) == 1 && remote[0].Url != server.URL+"/repo/pets/openapi.yaml" {
+				t.Errorf("got remote url <%s>", remote[0].Url)
+			}
+		})
+	}
+}
+
+func TestWriteFederatedIndexOnStdout(t *testing.T) {
+	opts := NewFederateOpts()
+	opts.Output = "-"
+	opts.Formats = []string{"json", "yaml"}
+	if err := writeFederatedIndex(opts, NewV1_RepositoryIndex()); err == nil {
+		t.Errorf("expected several formats to be refused on stdout")
+	}
+}
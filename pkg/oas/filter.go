@@ -0,0 +1,319 @@
+package oas
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Audiences lists the known values of the x-audience extension, from the widest to the narrowest
+// audience. A subset for an audience includes the operations of the wider audiences.
+var Audiences = []string{"public", "partner", "internal"}
+
+type FilterOpts struct {
+	// Repository used to resolve name@version references. Files are read directly.
+	Repository        string
+	SpecificationFile string
+
+	// Operations are kept when they match one of the tags, one of the path prefixes and one of the
+	// audiences. Empty criteria match every operation.
+	Tags         []string
+	PathPrefixes []string
+	Audiences    []string
+
+	// DefaultAudience of the operations without x-audience on themselves, their path item or the document.
+	DefaultAudience string
+
+	// OutputFile receiving the result, "-" for stdout. Its extension selects the format.
+	OutputFile string
+}
+
+func NewFilterOpts() *FilterOpts {
+	return &FilterOpts{
+		Repository:      ".",
+		Tags:            []string{},
+		PathPrefixes:    []string{},
+		Audiences:       []string{},
+		DefaultAudience: "internal",
+		OutputFile:      "-",
+	}
+}
+
+// Filter writes the subset of a specification matching the criteria, without unused components and tags.
+func Filter(opts *FilterOpts) error {
+	log.Infof("Filtering specification: %s.", opts.SpecificationFile)
+
+	// Load specification
+	repository, err := OpenRepositoryIfExists(opts.Repository)
+	if err != nil {
+		return err
+	}
+	_, content, err := repository.Load(opts.SpecificationFile)
+	if err != nil {
+		return err
+	}
+	var document yaml.Node
+	err = yaml.Unmarshal(content, &document)
+	if err != nil {
+		return err
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("specification <%s> is not an object", opts.SpecificationFile)
+	}
+
+	// Filter
+	kept, removed := FilterSpecification(document.Content[0], opts)
+	log.Infof("%d operations kept, %d removed.", kept, removed)
+
+	// Write result
+	format := opts.OutputFile
+	if format == "-" {
+		format = opts.SpecificationFile
+	}
+	content, err = marshallNode(&document, format)
+	if err != nil {
+		return err
+	}
+	if opts.OutputFile == "-" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+	log.Debugf("Writing %s.", opts.OutputFile)
+	return ioutil.WriteFile(opts.OutputFile, content, 0644)
+}
+
+// FilterSpecification removes from a specification the operations not matching the criteria, then the
+// path items, components and tags no longer used. It returns the number of operations kept and removed.
+func FilterSpecification(document *yaml.Node, opts *FilterOpts) (int, int) {
+	kept, removed := 0, 0
+	documentAudience := audienceOf(document, opts.DefaultAudience)
+
+	// Operations
+	if paths := mappingValue(document, "paths"); paths != nil {
+		for i := 0; i+1 < len(paths.Content); {
+			path, pathItem := paths.Content[i].Value, resolveAlias(paths.Content[i+1])
+			pathAudience := audienceOf(pathItem, documentAudience)
+			operations := 0
+			for j := 0; j+1 < len(pathItem.Content); {
+				method, operation := pathItem.Content[j].Value, pathItem.Content[j+1]
+				if !isHttpMethod(method) {
+					j += 2
+					continue
+				}
+				if matchesFilter(opts, path, operation, audienceOf(operation, pathAudience)) {
+					kept++
+					operations++
+					j += 2
+					continue
+				}
+				log.Debugf("Remove operation %s %s.", strings.ToUpper(method), path)
+				removed++
+				pathItem.Content = append(pathItem.Content[:j], pathItem.Content[j+2:]...)
+			}
+			if operations == 0 && mappingValue(pathItem, "$ref") == nil {
+				paths.Content = append(paths.Content[:i], paths.Content[i+2:]...)
+				continue
+			}
+			i += 2
+		}
+	}
+
+	pruneComponents(document)
+	pruneTags(document)
+	return kept, removed
+}
+
+func matchesFilter(opts *FilterOpts, path string, operation *yaml.Node, audience string) bool {
+	if len(opts.Tags) > 0 {
+		matched := false
+		if tags := mappingValue(operation, "tags"); tags != nil {
+			for _, tag := range tags.Content {
+				matched = matched || containsString(opts.Tags, tag.Value)
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(opts.PathPrefixes) > 0 {
+		matched := false
+		for _, prefix := range opts.PathPrefixes {
+			prefix = strings.TrimSuffix(prefix, "/")
+			matched = matched || path == prefix || strings.HasPrefix(path, prefix+"/")
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(opts.Audiences) > 0 {
+		matched := false
+		for _, filterAudience := range opts.Audiences {
+			matched = matched || audienceIncludes(filterAudience, audience)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// audienceOf returns the x-audience of a node, or the inherited audience.
+func audienceOf(node *yaml.Node, inherited string) string {
+	if audience := mappingValue(node, "x-audience"); audience != nil && audience.Kind == yaml.ScalarNode {
+		return audience.Value
+	}
+	if info := mappingValue(node, "info"); info != nil {
+		if audience := mappingValue(info, "x-audience"); audience != nil && audience.Kind == yaml.ScalarNode {
+			return audience.Value
+		}
+	}
+	return inherited
+}
+
+// audienceIncludes tells whether a subset for an audience includes an operation for another audience.
+func audienceIncludes(filterAudience string, audience string) bool {
+	filterRank, audienceRank := -1, -1
+	for i, known := range Audiences {
+		if known == filterAudience {
+			filterRank = i
+		}
+		if known == audience {
+			audienceRank = i
+		}
+	}
+	if filterRank < 0 || audienceRank < 0 {
+		return filterAudience == audience
+	}
+	return audienceRank <= filterRank
+}
+
+// pruneComponents removes the components which are not referenced, directly or not, by the paths
+// or the security requirements of the document.
+func pruneComponents(document *yaml.Node) {
+	components := mappingValue(document, "components")
+	if components == nil {
+		return
+	}
+
+	// Collect references reachable from everything but the components.
+	used := make(map[string]bool)
+	roots := &yaml.Node{Kind: yaml.MappingNode}
+	for i := 0; i+1 < len(document.Content); i += 2 {
+		if document.Content[i].Value != "components" {
+			roots.Content = append(roots.Content, document.Content[i], document.Content[i+1])
+		}
+	}
+	pending := []*yaml.Node{roots}
+	for len(pending) > 0 {
+		node := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, ref := range collectReferences(node, []string{}) {
+			if used[ref] {
+				continue
+			}
+			used[ref] = true
+			segments := strings.Split(strings.TrimPrefix(ref, "#/components/"), "/")
+			if len(segments) >= 2 {
+				if component := mappingValue(mappingValue(components, segments[0]), segments[1]); component != nil {
+					pending = append(pending, component)
+				}
+			}
+		}
+	}
+
+	// Remove the others.
+	for i := 0; i+1 < len(components.Content); {
+		componentType, section := components.Content[i].Value, resolveAlias(components.Content[i+1])
+		if section.Kind == yaml.MappingNode && containsString(ComponentTypes, componentType) {
+			for j := 0; j+1 < len(section.Content); {
+				if !used["#/components/"+componentType+"/"+section.Content[j].Value] {
+					log.Debugf("Remove unused component %s/%s.", componentType, section.Content[j].Value)
+					section.Content = append(section.Content[:j], section.Content[j+2:]...)
+					continue
+				}
+				j += 2
+			}
+			if len(section.Content) == 0 {
+				components.Content = append(components.Content[:i], components.Content[i+2:]...)
+				continue
+			}
+		}
+		i += 2
+	}
+	if len(components.Content) == 0 {
+		removeNode(document, components)
+	}
+}
+
+// collectReferences returns the components referenced by a node: $ref values, discriminator mappings
+// and security schemes of security requirements.
+func collectReferences(node *yaml.Node, refs []string) []string {
+	node = resolveAlias(node)
+	if node.Kind == yaml.ScalarNode && strings.HasPrefix(node.Value, "#/components/") {
+		return append(refs, node.Value)
+	}
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "security" && node.Content[i+1].Kind == yaml.SequenceNode {
+				for _, requirement := range node.Content[i+1].Content {
+					for j := 0; j+1 < len(requirement.Content); j += 2 {
+						refs = append(refs, "#/components/securitySchemes/"+requirement.Content[j].Value)
+					}
+				}
+			}
+		}
+	}
+	for _, child := range node.Content {
+		refs = collectReferences(child, refs)
+	}
+	return refs
+}
+
+// pruneTags removes the tag definitions which are no longer used by an operation.
+func pruneTags(document *yaml.Node) {
+	tags := mappingValue(document, "tags")
+	if tags == nil {
+		return
+	}
+
+	used := make(map[string]bool)
+	if paths := mappingValue(document, "paths"); paths != nil {
+		for i := 1; i < len(paths.Content); i += 2 {
+			pathItem := resolveAlias(paths.Content[i])
+			for j := 0; j+1 < len(pathItem.Content); j += 2 {
+				if operationTags := mappingValue(pathItem.Content[j+1], "tags"); isHttpMethod(pathItem.Content[j].Value) && operationTags != nil {
+					for _, tag := range operationTags.Content {
+						used[tag.Value] = true
+					}
+				}
+			}
+		}
+	}
+
+	for i := 0; i < len(tags.Content); {
+		if name := mappingValue(tags.Content[i], "name"); name != nil && !used[name.Value] {
+			tags.Content = append(tags.Content[:i], tags.Content[i+1:]...)
+			continue
+		}
+		i++
+	}
+	if len(tags.Content) == 0 {
+		removeNode(document, tags)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
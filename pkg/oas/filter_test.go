@@ -0,0 +1,253 @@
+package oas
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const filterTestSpecification = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+  x-audience: partner
+security:
+  - apiKey: []
+tags:
+  - name: pets
+  - name: admin
+  - name: unused
+paths:
+  /pets:
+    get:
+      tags: [pets]
+      x-audience: public
+      responses:
+        "200":
+          description: Pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+    post:
+      tags: [pets]
+      responses:
+        "201":
+          description: Created
+  /pets/{id}:
+    get:
+      tags: [pets]
+      responses:
+        default:
+          $ref: '#/components/responses/Error'
+  /admin/stats:
+    x-audience: internal
+    get:
+      tags: [admin]
+      security:
+        - oauth: [admin]
+      responses:
+        "200":
+          description: Stats
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Stats'
+  /administrators:
+    get:
+      tags: [admin]
+      x-audience: partner
+      responses:
+        "204":
+          description: None
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-Key
+    oauth:
+      type: oauth2
+      flows: {}
+    unused:
+      type: http
+      scheme: basic
+  responses:
+    Error:
+      description: Error
+      content:
+        application/json:
+          schema:
+            $ref: '#/components/schemas/Problem'
+  schemas:
+    Pet:
+      oneOf:
+        - $ref: '#/components/schemas/Cat'
+      discriminator:
+        propertyName: kind
+        mapping:
+          dog: '#/components/schemas/Dog'
+    Cat:
+      type: object
+    Dog:
+      type: object
+    Problem:
+      type: object
+      properties:
+        detail:
+          $ref: '#/components/schemas/Detail'
+    Detail:
+      type: string
+    Stats:
+      type: object
+      properties:
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: object
+`
+
+func parseFilterTestSpecification(t *testing.T) *yaml.Node {
+	t.Helper()
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(filterTestSpecification), &document); err != nil {
+		t.Fatal(err)
+	}
+	return document.Content[0]
+}
+
+// filterTestOperations lists the operations of a document as "METHOD path", sorted.
+func filterTestOperations(document *yaml.Node) []string {
+	operations := []string{}
+	paths := mappingValue(document, "paths")
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		pathItem := paths.Content[i+1]
+		for j := 0; j+1 < len(pathItem.Content); j += 2 {
+			if isHttpMethod(pathItem.Content[j].Value) {
+				operations = append(operations, strings.ToUpper(pathItem.Content[j].Value)+" "+paths.Content[i].Value)
+			}
+		}
+	}
+	sort.Strings(operations)
+	return operations
+}
+
+// filterTestNames lists the keys of a mapping node or the names of a sequence of named objects, sorted.
+func filterTestNames(node *yaml.Node) []string {
+	names := []string{}
+	if node == nil {
+		return names
+	}
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			names = append(names, node.Content[i].Value)
+		}
+	} else {
+		for _, item := range node.Content {
+			names = append(names, mappingValue(item, "name").Value)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestFilterSpecification(t *testing.T) {
+	cases := []struct {
+		name         string
+		tags         []string
+		pathPrefixes []string
+		audiences    []string
+		operations   []string
+	}{
+		{"no criteria", nil, nil, nil, []string{"GET /admin/stats", "GET /administrators", "GET /pets", "GET /pets/{id}", "POST /pets"}},
+		{"tag", []string{"admin"}, nil, nil, []string{"GET /admin/stats", "GET /administrators"}},
+		{"path prefix on segments", nil, []string{"/admin/"}, nil, []string{"GET /admin/stats"}},
+		{"path prefix matching the path", nil, []string{"/pets"}, nil, []string{"GET /pets", "GET /pets/{id}", "POST /pets"}},
+		{"public audience", nil, nil, []string{"public"}, []string{"GET /pets"}},
+		{"partner audience includes public", nil, nil, []string{"partner"}, []string{"GET /administrators", "GET /pets", "GET /pets/{id}", "POST /pets"}},
+		{"internal audience includes every audience", nil, nil, []string{"internal"}, []string{"GET /admin/stats", "GET /administrators", "GET /pets", "GET /pets/{id}", "POST /pets"}},
+		{"unknown audience", nil, nil, []string{"partners"}, []string{}},
+		{"every criterion", []string{"pets", "admin"}, []string{"/pets"}, []string{"public"}, []string{"GET /pets"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			document := parseFilterTestSpecification(t)
+			opts := NewFilterOpts()
+			opts.Tags = append(opts.Tags, c.tags...)
+			opts.PathPrefixes = append(opts.PathPrefixes, c.pathPrefixes...)
+			opts.Audiences = append(opts.Audiences, c.audiences...)
+
+			kept, removed := FilterSpecification(document, opts)
+			if operations := filterTestOperations(document); !reflect.DeepEqual(operations, c.operations) {
+				t.Errorf("got operations %v, want %v", operations, c.operations)
+			}
+			if kept != len(c.operations) || kept+removed != 5 {
+				t.Errorf("got %d kept and %d removed", kept, removed)
+			}
+		})
+	}
+}
+
+func TestFilterSpecificationPruning(t *testing.T) {
+	cases := []struct {
+		name            string
+		pathPrefixes    []string
+		schemas         []string
+		responses       []string
+		securitySchemes []string
+		tags            []string
+	}{
+		{"every operation", nil, []string{"Cat", "Detail", "Dog", "Owner", "Pet", "Problem", "Stats"}, []string{"Error"}, []string{"apiKey", "oauth"}, []string{"admin", "pets"}},
+		{"pets", []string{"/pets"}, []string{"Cat", "Detail", "Dog", "Pet", "Problem"}, []string{"Error"}, []string{"apiKey"}, []string{"pets"}},
+		{"single pet", []string{"/pets/{id}"}, []string{"Detail", "Problem"}, []string{"Error"}, []string{"apiKey"}, []string{"pets"}},
+		{"administration", []string{"/admin"}, []string{"Owner", "Stats"}, []string{}, []string{"apiKey", "oauth"}, []string{"admin"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			document := parseFilterTestSpecification(t)
+			opts := NewFilterOpts()
+			opts.PathPrefixes = append(opts.PathPrefixes, c.pathPrefixes...)
+			FilterSpecification(document, opts)
+
+			components := mappingValue(document, "components")
+			for _, section := range []struct {
+				name     string
+				expected []string
+			}{{"schemas", c.schemas}, {"responses", c.responses}, {"securitySchemes", c.securitySchemes}} {
+				if names := filterTestNames(mappingValue(components, section.name)); !reflect.DeepEqual(names, section.expected) {
+					t.Errorf("got %s %v, want %v", section.name, names, section.expected)
+				}
+			}
+			if names := filterTestNames(mappingValue(document, "tags")); !reflect.DeepEqual(names, c.tags) {
+				t.Errorf("got tags %v, want %v", names, c.tags)
+			}
+		})
+	}
+}
+
+func TestAudienceIncludes(t *testing.T) {
+	cases := []struct {
+		filterAudience string
+		audience       string
+		included       bool
+	}{
+		{"public", "public", true},
+		{"public", "partner", false},
+		{"partner", "public", true},
+		{"internal", "partner", true},
+		{"partner", "internal", false},
+		{"beta", "beta", true},
+		{"beta", "public", false},
+		{"internal", "beta", false},
+	}
+	for _, c := range cases {
+		if audienceIncludes(c.filterAudience, c.audience) != c.included {
+			t.Errorf("%s includes %s: want <%t>", c.filterAudience, c.audience, c.included)
+		}
+	}
+}
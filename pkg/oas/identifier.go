@@ -0,0 +1,109 @@
+package oas
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// IdStrategy defines how the stable identifier of a specification, used as index key, is computed.
+type IdStrategy string
+
+const (
+	// IdStrategyExtraInfo uses x-extra-info.id and falls back to the slugified title when absent.
+	IdStrategyExtraInfo IdStrategy = "extra-info"
+
+	// IdStrategyDirectory uses the first directory of the specification path, relative to the
+	// indexed directory (e.g. "petstore" for petstore/1.0.0/openapi.yaml), falling back to the file name.
+	IdStrategyDirectory IdStrategy = "directory"
+
+	// IdStrategyTitle uses the title, as done by indexes produced before identifiers were introduced.
+	// Titles which are not usable as a path segment are rejected.
+	IdStrategyTitle IdStrategy = "title"
+)
+
+var idRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+var idSlugRegexp = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// ParseIdStrategy converts a string into an identifier strategy.
+func ParseIdStrategy(value string) (IdStrategy, error) {
+	switch strategy := IdStrategy(value); strategy {
+	case IdStrategyExtraInfo, IdStrategyDirectory, IdStrategyTitle:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("unsupported id strategy <%s>: expected one of extra-info, directory, title", value)
+	}
+}
+
+// ValidateId checks that an identifier only contains lower-case alphanumerics, dots, dashes and underscores.
+func ValidateId(id string) error {
+	if !idRegexp.MatchString(id) {
+		return fmt.Errorf("invalid identifier <%s>: expected lower-case alphanumerics, '.', '-' or '_'", id)
+	}
+	return nil
+}
+
+// computeId returns the stable identifier of a specification according to the strategy. Identifiers
+// are part of the paths of the published artifacts, hence always usable as a path segment.
+func computeId(o *IndexOpts, oas3Source *OAS3Source) (string, error) {
+	info := &oas3Source.specification.Info
+
+	switch o.IdStrategy {
+	case IdStrategyTitle:
+		if err := validatePathSegment(info.Title); err != nil {
+			return "", fmt.Errorf("specification <%s>: title cannot be used as identifier: %v", oas3Source.path, err)
+		}
+		return info.Title, nil
+	case IdStrategyDirectory:
+		relativePath, err := filepath.Rel(o.Directory, oas3Source.path)
+		if err != nil {
+			return "", err
+		}
+		segments := strings.Split(filepath.ToSlash(relativePath), "/")
+		id := slugify(strings.TrimSuffix(segments[0], filepath.Ext(segments[0])))
+		if len(segments) > 1 {
+			id = slugify(segments[0])
+		}
+		if err := ValidateId(id); err != nil {
+			return "", fmt.Errorf("specification <%s>: no identifier can be derived from its path: %v", oas3Source.path, err)
+		}
+		return id, nil
+	default:
+		return extraInfoId(oas3Source)
+	}
+}
+
+// extraInfoId returns x-extra-info.id, or else the slugified title, once validated.
+func extraInfoId(oas3Source *OAS3Source) (string, error) {
+	info := &oas3Source.specification.Info
+
+	id := info.ExtraInfo.Id
+	if id == "" {
+		log.Debugf("Specification <%s> has no x-extra-info.id, slugified title used as identifier.", oas3Source.path)
+		id = slugify(info.Title)
+	}
+	if err := ValidateId(id); err != nil {
+		if info.ExtraInfo.Id == "" {
+			return "", fmt.Errorf("specification <%s>: no identifier can be derived from title <%s>, set x-extra-info.id", oas3Source.path, info.Title)
+		}
+		return "", fmt.Errorf("specification <%s>: %v", oas3Source.path, err)
+	}
+	return id, nil
+}
+
+// validatePathSegment checks that a value can be used as a single segment of an artifact path.
+func validatePathSegment(value string) error {
+	if value == "" || value == "." || value == ".." || strings.ContainsAny(value, "/\\") {
+		return fmt.Errorf("invalid path segment <%s>", value)
+	}
+	return nil
+}
+
+// slugify lower-cases a value and replaces its runs of characters not allowed in identifiers with a
+// dash, trimming the leading and trailing punctuation.
+func slugify(value string) string {
+	return strings.Trim(idSlugRegexp.ReplaceAllString(strings.ToLower(value), "-"), "-._")
+}
@@ -0,0 +1,102 @@
+package oas
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIdStrategy(t *testing.T) {
+	cases := []struct {
+		value    string
+		strategy IdStrategy
+		err      bool
+	}{
+		{"extra-info", IdStrategyExtraInfo, false},
+		{"directory", IdStrategyDirectory, false},
+		{"title", IdStrategyTitle, false},
+		{"", "", true},
+		{"Title", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			strategy, err := ParseIdStrategy(c.value)
+			if (err != nil) != c.err || strategy != c.strategy {
+				t.Errorf("got <%s> <%v>, want <%s> error <%t>", strategy, err, c.strategy, c.err)
+			}
+		})
+	}
+}
+
+func TestComputeId(t *testing.T) {
+	directory := filepath.Join("repo")
+	cases := []struct {
+		name     string
+		strategy IdStrategy
+		path     string
+		title    string
+		id       string
+		expected string
+		err      bool
+	}{
+		{"extra-info id", IdStrategyExtraInfo, "petstore/1.0.0/openapi.yaml", "Pet Store", "petstore", "petstore", false},
+		{"extra-info invalid id", IdStrategyExtraInfo, "petstore/1.0.0/openapi.yaml", "Pet Store", "Pet Store", "", true},
+		{"extra-info slugified title", IdStrategyExtraInfo, "petstore/1.0.0/openapi.yaml", "Pet Store", "", "pet-store", false},
+		{"extra-info title escaping the directory", IdStrategyExtraInfo, "a.yaml", "../../escaped", "", "escaped", false},
+		{"extra-info title without identifier", IdStrategyExtraInfo, "a.yaml", "../..", "", "", true},
+		{"directory", IdStrategyDirectory, "Pet Store/1.0.0/openapi.yaml", "Other", "", "pet-store", false},
+		{"directory root file", IdStrategyDirectory, "orders.yaml", "Other", "", "orders", false},
+		{"directory punctuation", IdStrategyDirectory, "_orders_/openapi.yaml", "Other", "", "orders", false},
+		{"directory without identifier", IdStrategyDirectory, "+++/openapi.yaml", "Other", "", "", true},
+		{"title", IdStrategyTitle, "a.yaml", "Pet Store", "", "Pet Store", false},
+		{"title with slash", IdStrategyTitle, "a.yaml", "../../escaped", "", "", true},
+		{"title empty", IdStrategyTitle, "a.yaml", "", "", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			source := &OAS3Source{path: filepath.Join(directory, filepath.FromSlash(c.path)), specification: &OAS3Specification{}}
+			source.specification.Info.Title = c.title
+			source.specification.Info.ExtraInfo.Id = c.id
+			opts := NewIndexOpts()
+			opts.Directory = directory
+			opts.IdStrategy = c.strategy
+
+			id, err := computeId(opts, source)
+			if (err != nil) != c.err {
+				t.Fatalf("got error <%v>, want error <%t>", err, c.err)
+			}
+			if id != c.expected {
+				t.Errorf("got id <%s>, want <%s>", id, c.expected)
+			}
+		})
+	}
+}
+
+func TestSlugify(t *testing.T) {
+	for value, expected := range map[string]string{
+		"Pet Store":     "pet-store",
+		"../../escaped": "escaped",
+		"_internal_":    "internal",
+		"v1.2 (beta)":   "v1.2-beta",
+		"+++":           "",
+	} {
+		if slug := slugify(value); slug != expected {
+			t.Errorf("<%s>: got <%s>, want <%s>", value, slug, expected)
+		}
+	}
+}
+
+func TestValidatePathSegment(t *testing.T) {
+	for value, valid := range map[string]bool{
+		"petstore": true,
+		"1.0.0":    true,
+		"":         false,
+		".":        false,
+		"..":       false,
+		"a/b":      false,
+		`a\b`:      false,
+	} {
+		if err := validatePathSegment(value); (err == nil) != valid {
+			t.Errorf("<%s>: got error <%v>, want valid <%t>", value, err, valid)
+		}
+	}
+}
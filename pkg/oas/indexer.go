@@ -1,14 +1,14 @@
 package oas
 
 import (
+	"bytes"
 	"encoding/json"
-	"io/ioutil"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
-	"github.com/Masterminds/semver/v3"
 	log "github.com/sirupsen/logrus"
 
 	"gopkg.in/yaml.v3"
@@ -19,57 +19,231 @@ type IndexOpts struct {
 	Extensions []string
 
 	Url string
+
+	// Sink receiving the index documents. Defaults to the indexed directory.
+	Sink IndexSink
+
+	// Formats of the index documents to produce (json, yaml).
+	Formats []string
+
+	// Canonical produces a pretty-printed, normalized and byte-reproducible index.
+	Canonical bool
+
+	// VersionPolicy defines how invalid semantic versions are handled.
+	VersionPolicy VersionPolicy
+
+	// ConflictPolicy defines how specifications declaring the same identifier and version are handled.
+	ConflictPolicy ConflictPolicy
+
+	// ConflictReport publishes the specifications declaring the same identifier and version, and the one kept.
+	ConflictReport bool
+
+	// IdStrategy defines how the stable identifier used as index key is computed.
+	IdStrategy IdStrategy
+
+	// Overlays applied, in order, to the specifications before building their entries.
+	Overlays []string
+
+	// Changelogs publishes the changelog of each specification across its versions.
+	Changelogs bool
+
+	// ExportSchemas publishes the component schemas of each specification as JSON Schema documents.
+	ExportSchemas bool
+
+	// Stats records the quality metrics of each specification in its entry.
+	Stats bool
+
+	// Search publishes a full-text search index of the latest specifications.
+	Search bool
+
+	// DefaultLanguage of the display names and descriptions given only as translations.
+	DefaultLanguage string
+
+	// Thumbnails generates the missing thumbnails from the logos, fitting in ThumbnailSize pixels.
+	Thumbnails    bool
+	ThumbnailSize int
+
+	// UrlMode tells whether the URLs of the index are relative or absolute. Defaults to absolute
+	// when a public URL is given.
+	UrlMode UrlMode
+
+	// UrlTemplate computes the URLs of the specifications, e.g. "{base}/{name}/{version}/spec.yaml".
+	// Defaults to their path in the indexed directory.
+	UrlTemplate string
 }
 
 func NewIndexOpts() *IndexOpts {
 	return &IndexOpts{
 		Extensions: []string{".json", ".yaml", ".yml"},
 		Url:        "",
+		Sink:       nil,
+		Formats:    []string{"json", "yaml"},
+		Canonical:  false,
+
+		VersionPolicy:  VersionPolicyWarn,
+		ConflictPolicy: ConflictPolicyKeepFirst,
+		ConflictReport: false,
+		IdStrategy:     IdStrategyExtraInfo,
+		Overlays:       []string{},
+		Changelogs:     false,
+		ExportSchemas:  false,
+		Stats:          false,
+		Search:         false,
+
+		DefaultLanguage: "en",
+		Thumbnails:      false,
+		ThumbnailSize:   128,
+		UrlMode:         "",
+		UrlTemplate:     "",
 	}
 }
 
 type V1_RepositoryIndex struct {
 	ApiVersion int                                               `yaml:"apiVersion" json:"apiVersion"`
 	Entries    map[string][]V1_RepositoryIndexSpecificationEntry `yaml:"entries" json:"entries"`
+
+	// Aliases maps the titles of the specifications to their identifier, so that clients
+	// still looking entries up by title (the key used before identifiers) find them.
+	Aliases map[string]string `yaml:"aliases,omitempty" json:"aliases,omitempty"`
 }
 
 func NewV1_RepositoryIndex() *V1_RepositoryIndex {
 	return &V1_RepositoryIndex{
 		ApiVersion: 1,
 		Entries:    make(map[string][]V1_RepositoryIndexSpecificationEntry),
+		Aliases:    make(map[string]string),
 	}
 }
 
 func (r V1_RepositoryIndex) AddSpecificationEntry(e *V1_RepositoryIndexSpecificationEntry) {
 	// Add entry
-	r.Entries[e.Name] = append(r.Entries[e.Name], *e)
+	r.Entries[e.Id] = append(r.Entries[e.Id], *e)
+}
+
+// GetSpecificationEntries returns the entries of an identifier, resolving aliases.
+func (r V1_RepositoryIndex) GetSpecificationEntries(id string) (string, []V1_RepositoryIndexSpecificationEntry) {
+	if entries, found := r.Entries[id]; found {
+		return id, entries
+	}
+	if aliasedId, found := r.Aliases[id]; found {
+		return aliasedId, r.Entries[aliasedId]
+	}
+	return id, nil
+}
+
+// BuildAliases records the titles of the entries as aliases of their identifier.
+// Titles shared by several identifiers are ambiguous and not aliased.
+func (r V1_RepositoryIndex) BuildAliases() {
+	ambiguousTitles := make(map[string]bool)
+	for id, entries := range r.Entries {
+		for _, entry := range entries {
+			if entry.Name == "" || entry.Name == id || ambiguousTitles[entry.Name] {
+				continue
+			}
+			if _, isId := r.Entries[entry.Name]; isId {
+				continue
+			}
+			if aliasedId, found := r.Aliases[entry.Name]; found && aliasedId != id {
+				log.Warnf("Title <%s> is shared by <%s> and <%s>, no alias recorded.", entry.Name, aliasedId, id)
+				delete(r.Aliases, entry.Name)
+				ambiguousTitles[entry.Name] = true
+				continue
+			}
+			r.Aliases[entry.Name] = id
+		}
+	}
+}
+
+// FindSpecificationEntry returns the entry matching the identifier and version, or nil if none.
+// The version is compared with the normalized version of the entries when available.
+func (r V1_RepositoryIndex) FindSpecificationEntry(id string, version string) *V1_RepositoryIndexSpecificationEntry {
+	entries := r.Entries[id]
+	for i := range entries {
+		if entries[i].Version == version || entryVersionKey(&entries[i]) == version {
+			return &entries[i]
+		}
+	}
+	return nil
 }
 
 func (r V1_RepositoryIndex) SortByVersionDesc() {
 	for _, entries := range r.Entries {
 		// Sort array by reverse version (latest on top)
 		sort.SliceStable(entries, func(i, j int) bool {
-			a, _ := semver.NewVersion(entries[i].Version)
-			b, _ := semver.NewVersion(entries[j].Version)
-			return a.GreaterThan(b)
+			return compareSpecificationEntries(&entries[i], &entries[j])
 		})
 	}
 }
 
+// Canonicalize normalizes the index so that identical inputs always produce identical outputs.
+func (r V1_RepositoryIndex) Canonicalize() {
+	for _, entries := range r.Entries {
+		for i := range entries {
+			entries[i].Keywords = canonicalStrings(entries[i].Keywords)
+			entries[i].Tags = canonicalStrings(entries[i].Tags)
+		}
+	}
+	r.SortByVersionDesc()
+}
+
+// compareSpecificationEntries returns true if a must be listed before b.
+// Valid versions come first by descending precedence, then entries are ordered by
+// version string and URL so that the result does not depend on the scan order.
+func compareSpecificationEntries(a *V1_RepositoryIndexSpecificationEntry, b *V1_RepositoryIndexSpecificationEntry) bool {
+	aVersion, aErr := parseEntryVersion(a)
+	bVersion, bErr := parseEntryVersion(b)
+
+	switch {
+	case aErr == nil && bErr == nil:
+		if !aVersion.Equal(bVersion) {
+			return aVersion.GreaterThan(bVersion)
+		}
+	case aErr == nil:
+		return true
+	case bErr == nil:
+		return false
+	}
+
+	if a.Version != b.Version {
+		return a.Version > b.Version
+	}
+	return a.Url < b.Url
+}
+
+// canonicalStrings returns a sorted copy of the values without duplicates, never nil.
+func canonicalStrings(values []string) []string {
+	result := []string{}
+	seen := make(map[string]bool)
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
 type V1_RepositoryIndexSpecificationEntry struct {
-	ApiVersion       int      `yaml:"apiVersion" json:"apiVersion"`
-	BusinessCategory string   `yaml:"businessCategory" json:"businessCategory"`
-	Deprecated       bool     `yaml:"deprecated" json:"deprecated"`
-	Description      string   `yaml:"description" json:"description"`
-	DisplayName      string   `yaml:"displayName" json:"displayName"`
-	Keywords         []string `yaml:"keywords" json:"keywords"`
-	LongDescription  string   `yaml:"longDescription" json:"longDescription"`
-	Name             string   `yaml:"name" json:"name"`
-	Starred          bool     `yaml:"starred" json:"starred"`
-	Tags             []string `yaml:"tags" json:"tags"`
-	TermsOfService   string   `yaml:"termsOfService" json:"termsOfService"`
-	Url              string   `yaml:"url" json:"url"`
-	Version          string   `yaml:"version" json:"version"`
+	ApiVersion        int      `yaml:"apiVersion" json:"apiVersion"`
+	BusinessCategory  string   `yaml:"businessCategory" json:"businessCategory"`
+	Deprecated        bool     `yaml:"deprecated" json:"deprecated"`
+	Description       string   `yaml:"description" json:"description"`
+	DisplayName       string   `yaml:"displayName" json:"displayName"`
+	Id                string   `yaml:"id" json:"id"`
+	Keywords          []string `yaml:"keywords" json:"keywords"`
+	LongDescription   string   `yaml:"longDescription" json:"longDescription"`
+	Name              string   `yaml:"name" json:"name"`
+	NormalizedVersion string   `yaml:"normalizedVersion" json:"normalizedVersion"`
+	Starred           bool     `yaml:"starred" json:"starred"`
+	Tags              []string `yaml:"tags" json:"tags"`
+	TermsOfService    string   `yaml:"termsOfService" json:"termsOfService"`
+	Url               string   `yaml:"url" json:"url"`
+	Version           string   `yaml:"version" json:"version"`
+
+	// Path of the specification file relative to the repository root, so that local repositories
+	// read it from disk whatever its URL.
+	Path string `yaml:"path,omitempty" json:"path,omitempty"`
 
 	Contact struct {
 		Name  string `yaml:"name" json:"name"`
@@ -92,6 +266,49 @@ type V1_RepositoryIndexSpecificationEntry struct {
 		GitUrl      string `yaml:"gitUrl" json:"gitUrl"`
 		GitRevision string `yaml:"gitRevision" json:"gitRevision"`
 	} `yaml:"vcs" json:"vcs"`
+
+	Owner struct {
+		Team   string `yaml:"team" json:"team"`
+		OnCall string `yaml:"onCall" json:"onCall"`
+	} `yaml:"owner" json:"owner"`
+
+	Lifecycle struct {
+		Stage      string `yaml:"stage" json:"stage"`
+		SunsetDate string `yaml:"sunsetDate" json:"sunsetDate"`
+		ReplacedBy string `yaml:"replacedBy" json:"replacedBy"`
+	} `yaml:"lifecycle" json:"lifecycle"`
+
+	// Deprecation summarizes the deprecated operations and their earliest sunset date.
+	Deprecation struct {
+		Operations int    `yaml:"operations" json:"operations"`
+		Sunset     string `yaml:"sunset" json:"sunset"`
+	} `yaml:"deprecation" json:"deprecation"`
+
+	Schemas      []V1_RepositoryIndexSchemaEntry `yaml:"schemas,omitempty" json:"schemas,omitempty"`
+	ChangelogUrl string                          `yaml:"changelogUrl,omitempty" json:"changelogUrl,omitempty"`
+	Stats        *V1_RepositoryIndexStatsEntry   `yaml:"stats,omitempty" json:"stats,omitempty"`
+	Source       *V1_RepositoryIndexSourceEntry  `yaml:"source,omitempty" json:"source,omitempty"`
+	Yanked       *V1_RepositoryIndexYankEntry    `yaml:"yanked,omitempty" json:"yanked,omitempty"`
+
+	// Localized holds the translations of the display name and descriptions by language.
+	Localized map[string]V1_RepositoryIndexLocalizedEntry `yaml:"localized,omitempty" json:"localized,omitempty"`
+}
+
+// V1_RepositoryIndexSchemaEntry references a component schema published as a JSON Schema document.
+type V1_RepositoryIndexSchemaEntry struct {
+	Name string `yaml:"name" json:"name"`
+	Url  string `yaml:"url" json:"url"`
+}
+
+// V1_RepositoryIndexStatsEntry holds the quality metrics of a specification, coverages and scores being percentages.
+type V1_RepositoryIndexStatsEntry struct {
+	Operations            int     `yaml:"operations" json:"operations"`
+	Schemas               int     `yaml:"schemas" json:"schemas"`
+	DocumentationCoverage float64 `yaml:"documentationCoverage" json:"documentationCoverage"`
+	ExamplesCoverage      float64 `yaml:"examplesCoverage" json:"examplesCoverage"`
+	LintScore             float64 `yaml:"lintScore" json:"lintScore"`
+	QualityScore          float64 `yaml:"qualityScore" json:"qualityScore"`
+	Grade                 string  `yaml:"grade" json:"grade"`
 }
 
 func NewV1_RepositoryIndexSpecificationEntry() *V1_RepositoryIndexSpecificationEntry {
@@ -114,6 +331,20 @@ func Index(opts *IndexOpts) error {
 		return err
 	}
 
+	// Check URL options
+	err = validateUrlOpts(opts)
+	if err != nil {
+		return err
+	}
+
+	// Check output
+	if _, stdout := opts.Sink.(*StdoutSink); stdout && (opts.ExportSchemas || opts.Changelogs || opts.Search || opts.Thumbnails || opts.ConflictReport) {
+		return fmt.Errorf("schemas, changelogs, search index, thumbnails and conflict report cannot be written to stdout: use a directory or S3 output")
+	}
+	if _, stdout := opts.Sink.(*StdoutSink); stdout && len(opts.Formats) > 1 {
+		return fmt.Errorf("a single index format can be written to stdout, got %s", strings.Join(opts.Formats, ", "))
+	}
+
 	// Scan files candidates.
 	candidateFiles, err := scanFiles(opts)
 	if err != nil {
@@ -123,14 +354,29 @@ func Index(opts *IndexOpts) error {
 
 	// Build repository index
 	log.Debugf("Read each file in directory to build repository data.")
-	repositoryData, err := buildRepositoryData(opts, candidateFiles)
+	repositoryData, artifacts, err := buildRepositoryData(opts, candidateFiles)
 	if err != nil {
 		return err
 	}
 
+	// Write artifacts before the index referencing them
+	sink := opts.Sink
+	if sink == nil {
+		sink = NewFileSystemSink(opts.Directory)
+	}
+	for _, name := range sortedKeys(artifacts) {
+		err = sink.Write(name, artifacts[name])
+		if err != nil {
+			return err
+		}
+	}
+
 	// Marshall repository content
 	log.Debugf("Marshall repository data into index and yaml files.")
-	err = marshallIndex(opts.Directory, repositoryData)
+	if opts.Canonical {
+		repositoryData.Canonicalize()
+	}
+	err = marshallIndex(sink, opts.Formats, opts.Canonical, repositoryData)
 	if err != nil {
 		return err
 	}
@@ -156,6 +402,33 @@ func scanFiles(o *IndexOpts) ([]string, error) {
 				return nil
 			}
 
+			// Skip overlays
+			for _, overlay := range o.Overlays {
+				if filepath.Clean(overlay) == filepath.Clean(path) {
+					return nil
+				}
+			}
+
+			// Skip yanked versions
+			if path == filepath.Join(o.Directory, yanksFile) {
+				return nil
+			}
+
+			// Skip published conflict report
+			if path == filepath.Join(o.Directory, conflictReportFile) {
+				return nil
+			}
+
+			// Skip published search index
+			if path == filepath.Join(o.Directory, searchIndexFile) {
+				return nil
+			}
+
+			// Skip published JSON schemas
+			if strings.HasPrefix(path, filepath.Join(o.Directory, schemasDirectory)+string(filepath.Separator)) {
+				return nil
+			}
+
 			// Analyze subfiles.
 			log.Tracef("> Checking file <%s>.", path)
 			extension := strings.ToLower(filepath.Ext(path))
@@ -172,103 +445,275 @@ func scanFiles(o *IndexOpts) ([]string, error) {
 	return files, nil
 }
 
-func buildRepositoryData(o *IndexOpts, candidateFiles []string) (*V1_RepositoryIndex, error) {
+func buildRepositoryData(o *IndexOpts, candidateFiles []string) (*V1_RepositoryIndex, map[string][]byte, error) {
 	// Initialize repo index.
 	repositoryIndex := NewV1_RepositoryIndex()
+	resolver := newConflictResolver(o.ConflictPolicy)
+	sources := make(map[string]*OAS3Source)
+
+	// Parse overlays
+	overlays, err := ParseOverlayFiles(o.Overlays)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Process files in lexical order, so that the index and the conflict resolution do not depend on the scan order.
+	candidateFiles = append([]string{}, candidateFiles...)
+	sort.Strings(candidateFiles)
 
 	// Scan each file and accumulate content in the structure.
 	for _, candidateFile := range candidateFiles {
 		log.Debugf("Processing file <%s>.", candidateFile)
 
 		// Parse specification
-		oas3Source, err := ParseFile(candidateFile)
+		relativePath, err := filepath.Rel(o.Directory, candidateFile)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		oas3Source, err := parseOverlaidFile(candidateFile, relativePath, overlays)
+		if err != nil {
+			return nil, nil, err
+		}
+		sources[candidateFile] = oas3Source
 
 		// Convert to entry
 		specificationEntry, err := buildSpecificationEntry(o, oas3Source)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Add spec entry to repo index.
 		if specificationEntry != nil {
-			repositoryIndex.AddSpecificationEntry(specificationEntry)
+			err = resolver.add(repositoryIndex, specificationEntry, candidateFile)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	// Report conflicts
+	conflicts, err := resolver.report()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Record titles as aliases
+	repositoryIndex.BuildAliases()
+
+	// Mark yanked versions
+	err = applyYanks(o, repositoryIndex)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Publish schemas of the retained specifications
+	artifacts := make(map[string][]byte)
+	if o.ExportSchemas {
+		artifacts, err = buildSchemaArtifacts(o, repositoryIndex, resolver, sources)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Publish conflict report
+	if o.ConflictReport {
+		artifacts[conflictReportFile], err = marshallCanonicalJson(conflicts)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// Generate thumbnails of the retained specifications
+	if o.Thumbnails {
+		thumbnails, err := buildThumbnailArtifacts(o, repositoryIndex, resolver, sources)
+		if err != nil {
+			return nil, nil, err
+		}
+		for name, content := range thumbnails {
+			artifacts[name] = content
 		}
 	}
 
 	// Force sort
 	repositoryIndex.SortByVersionDesc()
 
+	// Publish changelogs across the sorted versions
+	if o.Changelogs {
+		changelogs, err := buildChangelogArtifacts(o, repositoryIndex, resolver, sources)
+		if err != nil {
+			return nil, nil, err
+		}
+		for name, content := range changelogs {
+			artifacts[name] = content
+		}
+	}
+
+	// Publish search index of the latest versions
+	if o.Search {
+		artifacts[searchIndexFile], err = buildSearchArtifact(repositoryIndex, resolver, sources)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
 	// Return result.
-	return repositoryIndex, nil
+	return repositoryIndex, artifacts, nil
 }
 
 func buildSpecificationEntry(o *IndexOpts, oas3Source *OAS3Source) (*V1_RepositoryIndexSpecificationEntry, error) {
+	// Normalize version
+	normalizedVersion, err := normalizeVersion(oas3Source.path, oas3Source.specification.Info.Version, o.VersionPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute identifier
+	id, err := computeId(o, oas3Source)
+	if err != nil {
+		return nil, err
+	}
+
 	// Compute specification URL
-	specificationUrl := strings.TrimPrefix(oas3Source.path, o.Directory)
-	if o.Url != "" {
-		specificationUrl = strings.Join([]string{o.Url, specificationUrl}, "/")
+	relativePath, err := relativeSlashPath(o.Directory, oas3Source.path)
+	if err != nil {
+		return nil, err
+	}
+	specificationUrl := o.specificationUrl(relativePath, id, oas3Source.specification.Info.Title, oas3Source.specification.Info.Version)
+
+	// Validate lifecycle
+	lifecycleStage, err := validateLifecycle(oas3Source)
+	if err != nil {
+		return nil, err
 	}
 
+	// Gather translations
+	extraInfo := &oas3Source.specification.Info.ExtraInfo
+	localized := buildLocalizedEntries(oas3Source.specification)
+
 	// Build entry from file.
 	specificationEntry := NewV1_RepositoryIndexSpecificationEntry()
 	specificationEntry.BusinessCategory = oas3Source.specification.Info.ExtraInfo.BusinessCategory
 	specificationEntry.Contact.Email = oas3Source.specification.Info.Contact.Email
 	specificationEntry.Contact.Name = oas3Source.specification.Info.Contact.Name
 	specificationEntry.Contact.Url = oas3Source.specification.Info.Contact.Url
-	specificationEntry.Deprecated = oas3Source.specification.Info.ExtraInfo.Deprecated
+	specificationEntry.Deprecated = oas3Source.specification.Info.ExtraInfo.Deprecated || lifecycleStage.Ended()
+	specificationEntry.Deprecation.Operations, specificationEntry.Deprecation.Sunset = summarizeDeprecations(oas3Source.specification.DeprecatedOperations())
 	specificationEntry.Description = oas3Source.specification.Info.Description
-	specificationEntry.DisplayName = oas3Source.specification.Info.ExtraInfo.DisplayName
+	if specificationEntry.Description == "" {
+		specificationEntry.Description = localizedText(extraInfo.Description, localized, o.DefaultLanguage, func(e V1_RepositoryIndexLocalizedEntry) string { return e.Description })
+	}
+	specificationEntry.DisplayName = localizedText(extraInfo.DisplayName, localized, o.DefaultLanguage, func(e V1_RepositoryIndexLocalizedEntry) string { return e.DisplayName })
+	specificationEntry.Id = id
 	specificationEntry.Image.Icon = oas3Source.specification.Info.ExtraInfo.IconUrl
 	specificationEntry.Image.Logo = oas3Source.specification.Info.ExtraInfo.LogoUrl
 	specificationEntry.Image.Thumbnail = oas3Source.specification.Info.ExtraInfo.ThumbnailUrl
 	specificationEntry.Keywords = oas3Source.specification.Info.ExtraInfo.Keywords
 	specificationEntry.License.Name = oas3Source.specification.Info.License.Name
 	specificationEntry.License.Url = oas3Source.specification.Info.License.Url
-	specificationEntry.LongDescription = oas3Source.specification.Info.ExtraInfo.LongDescription
+	specificationEntry.Lifecycle.ReplacedBy = oas3Source.specification.Info.ExtraInfo.ReplacedBy
+	specificationEntry.Lifecycle.Stage = string(lifecycleStage)
+	specificationEntry.Lifecycle.SunsetDate = oas3Source.specification.Info.ExtraInfo.SunsetDate
+	specificationEntry.Localized = localized
+	specificationEntry.LongDescription = localizedText(extraInfo.LongDescription, localized, o.DefaultLanguage, func(e V1_RepositoryIndexLocalizedEntry) string { return e.LongDescription })
 	specificationEntry.Name = oas3Source.specification.Info.Title
+	specificationEntry.NormalizedVersion = normalizedVersion
+	specificationEntry.Owner.OnCall = oas3Source.specification.Info.ExtraInfo.OnCall
+	specificationEntry.Owner.Team = oas3Source.specification.Info.ExtraInfo.OwnerTeam
 	specificationEntry.Starred = oas3Source.specification.Info.ExtraInfo.Starred
 	specificationEntry.Tags = oas3Source.specification.Info.ExtraInfo.Tags
 	specificationEntry.Url = specificationUrl
+	specificationEntry.Path = relativePath
 	specificationEntry.Vcs.GitRevision = oas3Source.specification.Info.ExtraInfo.VcsGitRevision
 	specificationEntry.Vcs.GitUrl = oas3Source.specification.Info.ExtraInfo.VcsGitUrl
 	specificationEntry.Version = oas3Source.specification.Info.Version
-	return specificationEntry, nil
-}
-
-func marshallIndex(directory string, data *V1_RepositoryIndex) error {
-	// Marshalling into JSON
-	indexJsonFilePath := filepath.Join(directory, "index.json")
 
-	log.Debugf("Marshalling repository index to %s.", indexJsonFilePath)
-	jsonMarshalled, err := json.Marshal(&data)
+	// Resolve images
+	err = resolveImages(o, oas3Source, specificationEntry)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	log.Tracef("index.json dump: \n%s\n", string(jsonMarshalled))
-	err = ioutil.WriteFile(filepath.Join(directory, "index.json"), jsonMarshalled, 0644)
-	if err != nil {
-		return err
+	// Record quality metrics
+	if o.Stats {
+		stats := oas3Source.specification.Stats()
+		specificationEntry.Stats = &V1_RepositoryIndexStatsEntry{
+			Operations:            stats.Operations,
+			Schemas:               stats.Schemas,
+			DocumentationCoverage: stats.DocumentationCoverage,
+			ExamplesCoverage:      stats.ExamplesCoverage,
+			LintScore:             stats.LintScore,
+			QualityScore:          stats.QualityScore,
+			Grade:                 stats.Grade,
+		}
 	}
+	return specificationEntry, nil
+}
 
-	// Marshalling into YAML
-	indexYamlFilePath := filepath.Join(directory, "index.yaml")
+func marshallIndex(sink IndexSink, formats []string, canonical bool, data *V1_RepositoryIndex) error {
+	for _, format := range formats {
+		marshalled, err := marshallIndexDocument(format, canonical, data)
+		if err != nil {
+			return err
+		}
 
-	log.Debugf("Marshalling repository index into %s.", indexYamlFilePath)
-	yamlMarshalled, err := yaml.Marshal(&data)
-	if err != nil {
-		return err
-	}
-	log.Tracef("index.yaml dump: \n%s\n", string(yamlMarshalled))
-	err = ioutil.WriteFile(indexYamlFilePath, yamlMarshalled, 0644)
-	if err != nil {
-		return err
+		log.Tracef("index.%s dump: \n%s\n", format, string(marshalled))
+		err = sink.Write("index."+format, marshalled)
+		if err != nil {
+			return err
+		}
 	}
 
 	log.Debug("Marshalling complete.")
 
 	return nil
 }
+
+// marshallIndexDocument marshalls the index in one format (json, yaml).
+func marshallIndexDocument(format string, canonical bool, data *V1_RepositoryIndex) ([]byte, error) {
+	switch format {
+	case "json":
+		// Marshalling into JSON
+		log.Debugf("Marshalling repository index to index.json.")
+		if canonical {
+			return marshallCanonicalJson(data)
+		}
+		return json.Marshal(&data)
+	case "yaml":
+		// Marshalling into YAML
+		log.Debugf("Marshalling repository index into index.yaml.")
+		if canonical {
+			return marshallCanonicalYaml(data)
+		}
+		return yaml.Marshal(&data)
+	default:
+		return nil, fmt.Errorf("unsupported index format <%s>", format)
+	}
+}
+
+// marshallCanonicalJson produces an indented JSON document terminated by a new line.
+// Map keys are sorted by encoding/json and struct fields keep their declaration order.
+func marshallCanonicalJson(data interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
+
+// marshallCanonicalYaml produces a YAML document indented with two spaces.
+// Map keys are sorted by yaml.v3 and struct fields keep their declaration order.
+func marshallCanonicalYaml(data interface{}) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(data); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
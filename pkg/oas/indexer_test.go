@@ -0,0 +1,190 @@
+package oas
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// memorySink keeps the documents written to it, by name.
+type memorySink map[string][]byte
+
+func (s memorySink) Write(name string, data []byte) error {
+	s[name] = append([]byte{}, data...)
+	return nil
+}
+
+func TestIndexCanonicalOutput(t *testing.T) {
+	directory := t.TempDir()
+	files := map[string]string{
+		"pets/1.0.0.yaml":  "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: 1.0.0\n  x-extra-info:\n    keywords: [pets, animals, pets]\n    tags: [public, beta]\npaths: {}\n",
+		"pets/2.0.0.yaml":  "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: 2.0.0\n  x-extra-info:\n    keywords: [zoo, animals]\npaths: {}\n",
+		"pets/latest.yaml": "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: latest\npaths: {}\n",
+		"pets/main.yaml":   "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: latest\npaths: {}\n",
+		"orders.yaml":      "openapi: 3.0.3\ninfo:\n  title: Orders\n  version: 1.0.0-rc.1\npaths: {}\n",
+	}
+	for name, content := range files {
+		writeTestFile(t, filepath.Join(directory, name), content)
+	}
+
+	opts := NewIndexOpts()
+	opts.Directory = directory
+	opts.Url = "https://apis.example.com"
+	opts.Canonical = true
+	opts.Formats = []string{"json", "yaml"}
+
+	// Index the repository once with its own scan order.
+	if err := Index(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := memorySink{}
+	for _, name := range []string{"index.json", "index.yaml"} {
+		content, err := os.ReadFile(filepath.Join(directory, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected[name] = content
+	}
+
+	// Index it again with other modification times, scanning the files in reverse order.
+	candidateFiles, err := scanFiles(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, path := range candidateFiles {
+		modTime := time.Now().Add(time.Duration(i) * time.Hour)
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i, j := 0, len(candidateFiles)-1; i < j; i, j = i+1, j-1 {
+		candidateFiles[i], candidateFiles[j] = candidateFiles[j], candidateFiles[i]
+	}
+	repositoryData, _, err := buildRepositoryData(opts, candidateFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repositoryData.Canonicalize()
+	actual := memorySink{}
+	if err := marshallIndex(actual, opts.Formats, opts.Canonical, repositoryData); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range expected {
+		if !bytes.Equal(actual[name], content) {
+			t.Errorf("%s differs between runs:\n%s\n---\n%s", name, content, actual[name])
+		}
+		if !bytes.HasSuffix(content, []byte("\n")) || bytes.Contains(content, []byte("\t")) {
+			t.Errorf("%s is not pretty-printed with spaces:\n%s", name, content)
+		}
+	}
+	if !bytes.Contains(expected["index.json"], []byte(`"keywords": [
+          "animals",
+          "pets"
+        ]`)) {
+		t.Errorf("keywords not canonicalized:\n%s", expected["index.json"])
+	}
+}
+
+const indexerTestDeprecations = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets:
+    get:
+      deprecated: true
+      responses:
+        "200":
+          description: Pets
+    post:
+      responses:
+        "201":
+          description: Created
+          headers:
+            Sunset:
+              schema:
+                type: string
+                example: Sat, 01 May 2021 00:00:00 GMT
+  /pets/{id}:
+    get:
+      responses:
+        "200":
+          description: Pet
+          headers:
+            Deprecation:
+              schema:
+                type: string
+        default:
+          $ref: '#/components/responses/Gone'
+    delete:
+      responses:
+        "204":
+          description: Deleted
+          headers:
+            sunset:
+              schema:
+                type: string
+                enum: ["2021-02-15T23:00:00-02:00"]
+  /orders:
+    get:
+      responses:
+        "200":
+          description: Orders
+components:
+  responses:
+    Gone:
+      description: Gone
+      headers:
+        Sunset:
+          $ref: '#/components/headers/Sunset'
+  headers:
+    Sunset:
+      example: 2021-03-01
+`
+
+func TestIndexDeprecatedOperations(t *testing.T) {
+	directory := t.TempDir()
+	writeTestFile(t, filepath.Join(directory, "pets.yaml"), indexerTestDeprecations)
+	writeTestFile(t, filepath.Join(directory, "orders.yaml"), "openapi: 3.0.3\ninfo:\n  title: Orders\n  version: 1.0.0\npaths: {}\n")
+	opts := NewIndexOpts()
+	opts.Directory = directory
+	if err := Index(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	index := readTestIndex(t, directory)
+	cases := []struct {
+		id         string
+		operations int
+		sunset     string
+	}{
+		{"pets", 4, "2021-02-16"},
+		{"orders", 0, ""},
+	}
+	for _, c := range cases {
+		if len(index.Entries[c.id]) != 1 {
+			t.Fatalf("got entries %+v for %s", index.Entries[c.id], c.id)
+		}
+		deprecation := index.Entries[c.id][0].Deprecation
+		if deprecation.Operations != c.operations || deprecation.Sunset != c.sunset {
+			t.Errorf("got deprecation %+v for %s, want %d operations and sunset <%s>", deprecation, c.id, c.operations, c.sunset)
+		}
+	}
+
+	source, err := ParseBytes("pets.yaml", []byte(indexerTestDeprecations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sunsets := map[string]string{}
+	for _, deprecation := range source.specification.DeprecatedOperations() {
+		sunsets[deprecation.Method+" "+deprecation.Path] = deprecation.Sunset
+	}
+	expected := map[string]string{"get /pets": "", "post /pets": "2021-05-01", "get /pets/{id}": "2021-03-01", "delete /pets/{id}": "2021-02-16"}
+	if !reflect.DeepEqual(sunsets, expected) {
+		t.Errorf("got deprecated operations %v, want %v", sunsets, expected)
+	}
+}
@@ -0,0 +1,481 @@
+package oas
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JsonPath is a compiled JSONPath expression selecting nodes of a YAML or JSON document.
+//
+// Supported syntax: root ($), child members (.name, ['name'], ["a","b"]), wildcards (.*, [*]),
+// array indexes ([0], [-1]), recursive descent (..name, ..*) and filters such as
+// [?(@.name == 'value')], [?@.deprecated] or [?(@.a.b >= 2 && @.c != null)].
+type JsonPath struct {
+	expression string
+	segments   []jsonPathSegment
+}
+
+type jsonPathSegment struct {
+	recursive bool
+	wildcard  bool
+	names     []string
+	indexes   []int
+	filter    *jsonPathFilter
+}
+
+// jsonPathFilter is a disjunction of conjunctions of conditions.
+type jsonPathFilter struct {
+	alternatives [][]jsonPathCondition
+}
+
+type jsonPathCondition struct {
+	negated  bool
+	path     []string
+	operator string
+	value    interface{}
+}
+
+// jsonPathMatch is a selected node, with its parent to allow removals.
+type jsonPathMatch struct {
+	node   *yaml.Node
+	parent *yaml.Node
+}
+
+// CompileJsonPath parses a JSONPath expression.
+func CompileJsonPath(expression string) (*JsonPath, error) {
+	parser := &jsonPathParser{input: strings.TrimSpace(expression)}
+	segments, err := parser.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSONPath <%s>: %v", expression, err)
+	}
+	return &JsonPath{expression: expression, segments: segments}, nil
+}
+
+func (p *JsonPath) String() string {
+	return p.expression
+}
+
+// Select returns the nodes of a document matching the expression, in document order.
+func (p *JsonPath) Select(document *yaml.Node) []*yaml.Node {
+	nodes := []*yaml.Node{}
+	for _, match := range p.selectMatches(document) {
+		nodes = append(nodes, match.node)
+	}
+	return nodes
+}
+
+func (p *JsonPath) selectMatches(document *yaml.Node) []jsonPathMatch {
+	root := document
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	matches := []jsonPathMatch{{node: root}}
+	for _, segment := range p.segments {
+		next := []jsonPathMatch{}
+		seen := make(map[*yaml.Node]bool)
+		for _, match := range matches {
+			candidates := []*yaml.Node{match.node}
+			if segment.recursive {
+				candidates = descendants(match.node, candidates)
+			}
+			for _, candidate := range candidates {
+				for _, child := range segment.children(candidate) {
+					if !seen[child] {
+						seen[child] = true
+						next = append(next, jsonPathMatch{node: child, parent: candidate})
+					}
+				}
+			}
+		}
+		matches = next
+	}
+	return matches
+}
+
+// descendants appends all the container nodes under a node, depth-first.
+func descendants(node *yaml.Node, result []*yaml.Node) []*yaml.Node {
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(node.Content); i += 2 {
+			result = append(result, node.Content[i])
+			result = descendants(node.Content[i], result)
+		}
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			result = append(result, item)
+			result = descendants(item, result)
+		}
+	}
+	return result
+}
+
+func (s *jsonPathSegment) children(node *yaml.Node) []*yaml.Node {
+	node = resolveAlias(node)
+	children := []*yaml.Node{}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for _, name := range s.names {
+			if value := mappingValue(node, name); value != nil {
+				children = append(children, value)
+			}
+		}
+		for i := 1; i < len(node.Content); i += 2 {
+			if s.wildcard || (s.filter != nil && s.filter.matches(node.Content[i])) {
+				children = append(children, node.Content[i])
+			}
+		}
+	case yaml.SequenceNode:
+		for _, index := range s.indexes {
+			if index < 0 {
+				index += len(node.Content)
+			}
+			if index >= 0 && index < len(node.Content) {
+				children = append(children, node.Content[index])
+			}
+		}
+		for _, item := range node.Content {
+			if s.wildcard || (s.filter != nil && s.filter.matches(item)) {
+				children = append(children, item)
+			}
+		}
+	}
+	return children
+}
+
+func (f *jsonPathFilter) matches(node *yaml.Node) bool {
+	for _, conditions := range f.alternatives {
+		matched := true
+		for _, condition := range conditions {
+			if !condition.matches(node) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *jsonPathCondition) matches(node *yaml.Node) bool {
+	for _, name := range c.path {
+		node = mappingValue(resolveAlias(node), name)
+		if node == nil {
+			return c.negated || c.operator == "!="
+		}
+	}
+
+	result := true
+	if c.operator != "" {
+		var value interface{}
+		if node.Decode(&value) != nil {
+			return false
+		}
+		value = normalizeGenericValue(value)
+		switch c.operator {
+		case "==":
+			result = valuesEqual(value, c.value)
+		case "!=":
+			result = !valuesEqual(value, c.value)
+		default:
+			left, leftOk := toNumber(value)
+			right, rightOk := toNumber(c.value)
+			if !leftOk || !rightOk {
+				return false
+			}
+			switch c.operator {
+			case "<":
+				result = left < right
+			case "<=":
+				result = left <= right
+			case ">":
+				result = left > right
+			case ">=":
+				result = left >= right
+			}
+		}
+	}
+	return result != c.negated
+}
+
+// mappingValue returns the value of a key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node != nil && node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	return node
+}
+
+type jsonPathParser struct {
+	input    string
+	position int
+}
+
+func (p *jsonPathParser) parse() ([]jsonPathSegment, error) {
+	if !strings.HasPrefix(p.input, "$") {
+		return nil, fmt.Errorf("expression must start with $")
+	}
+	p.position = 1
+
+	segments := []jsonPathSegment{}
+	for p.position < len(p.input) {
+		segment := jsonPathSegment{}
+		switch {
+		case strings.HasPrefix(p.input[p.position:], ".."):
+			segment.recursive = true
+			p.position += 2
+			if p.peek() != '[' {
+				err := p.parseMember(&segment)
+				if err != nil {
+					return nil, err
+				}
+				break
+			}
+			err := p.parseBracket(&segment)
+			if err != nil {
+				return nil, err
+			}
+		case p.peek() == '.':
+			p.position++
+			err := p.parseMember(&segment)
+			if err != nil {
+				return nil, err
+			}
+		case p.peek() == '[':
+			err := p.parseBracket(&segment)
+			if err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("unexpected character '%c' at position %d", p.peek(), p.position)
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+func (p *jsonPathParser) peek() byte {
+	if p.position < len(p.input) {
+		return p.input[p.position]
+	}
+	return 0
+}
+
+func (p *jsonPathParser) skipSpaces() {
+	for p.peek() == ' ' {
+		p.position++
+	}
+}
+
+// parseMember parses a dot-notation member name or wildcard.
+func (p *jsonPathParser) parseMember(segment *jsonPathSegment) error {
+	if p.peek() == '*' {
+		p.position++
+		segment.wildcard = true
+		return nil
+	}
+	name := p.parseName()
+	if name == "" {
+		return fmt.Errorf("missing member name at position %d", p.position)
+	}
+	segment.names = []string{name}
+	return nil
+}
+
+func (p *jsonPathParser) parseName() string {
+	start := p.position
+	for p.position < len(p.input) && !strings.ContainsRune(".[]()=!<>&| ", rune(p.input[p.position])) {
+		p.position++
+	}
+	return p.input[start:p.position]
+}
+
+// parseBracket parses a bracket selector: names, indexes, wildcard or filter.
+func (p *jsonPathParser) parseBracket(segment *jsonPathSegment) error {
+	p.position++
+	p.skipSpaces()
+
+	switch p.peek() {
+	case '*':
+		p.position++
+		segment.wildcard = true
+	case '?':
+		p.position++
+		p.skipSpaces()
+		parenthesized := p.peek() == '('
+		if parenthesized {
+			p.position++
+		}
+		filter, err := p.parseFilter()
+		if err != nil {
+			return err
+		}
+		segment.filter = filter
+		p.skipSpaces()
+		if parenthesized {
+			if p.peek() != ')' {
+				return fmt.Errorf("missing ')' at position %d", p.position)
+			}
+			p.position++
+		}
+	default:
+		for {
+			p.skipSpaces()
+			value, err := p.parseLiteral()
+			if err != nil {
+				return err
+			}
+			switch v := value.(type) {
+			case string:
+				segment.names = append(segment.names, v)
+			case float64:
+				segment.indexes = append(segment.indexes, int(v))
+			default:
+				return fmt.Errorf("invalid selector at position %d", p.position)
+			}
+			p.skipSpaces()
+			if p.peek() != ',' {
+				break
+			}
+			p.position++
+		}
+	}
+
+	p.skipSpaces()
+	if p.peek() != ']' {
+		return fmt.Errorf("missing ']' at position %d", p.position)
+	}
+	p.position++
+	return nil
+}
+
+func (p *jsonPathParser) parseFilter() (*jsonPathFilter, error) {
+	filter := &jsonPathFilter{}
+	conditions := []jsonPathCondition{}
+	for {
+		condition, err := p.parseCondition()
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+
+		p.skipSpaces()
+		switch {
+		case strings.HasPrefix(p.input[p.position:], "&&"):
+			p.position += 2
+		case strings.HasPrefix(p.input[p.position:], "||"):
+			p.position += 2
+			filter.alternatives = append(filter.alternatives, conditions)
+			conditions = []jsonPathCondition{}
+		default:
+			filter.alternatives = append(filter.alternatives, conditions)
+			return filter, nil
+		}
+	}
+}
+
+func (p *jsonPathParser) parseCondition() (jsonPathCondition, error) {
+	condition := jsonPathCondition{}
+	p.skipSpaces()
+	if p.peek() == '!' {
+		condition.negated = true
+		p.position++
+		p.skipSpaces()
+	}
+
+	// Relative path
+	if p.peek() != '@' {
+		return condition, fmt.Errorf("filter condition must start with @ at position %d", p.position)
+	}
+	p.position++
+	for p.peek() == '.' || p.peek() == '[' {
+		if p.peek() == '.' {
+			p.position++
+			name := p.parseName()
+			if name == "" {
+				return condition, fmt.Errorf("missing member name at position %d", p.position)
+			}
+			condition.path = append(condition.path, name)
+			continue
+		}
+		p.position++
+		value, err := p.parseLiteral()
+		name, ok := value.(string)
+		if err != nil || !ok || p.peek() != ']' {
+			return condition, fmt.Errorf("invalid member name at position %d", p.position)
+		}
+		p.position++
+		condition.path = append(condition.path, name)
+	}
+
+	// Comparison
+	p.skipSpaces()
+	for _, operator := range []string{"==", "!=", "<=", ">=", "<", ">"} {
+		if strings.HasPrefix(p.input[p.position:], operator) {
+			if condition.negated {
+				return condition, fmt.Errorf("negation applies to existence tests only, at position %d", p.position)
+			}
+			p.position += len(operator)
+			p.skipSpaces()
+			value, err := p.parseLiteral()
+			if err != nil {
+				return condition, err
+			}
+			condition.operator = operator
+			condition.value = value
+			break
+		}
+	}
+	return condition, nil
+}
+
+// parseLiteral parses a quoted string, a number, true, false or null.
+func (p *jsonPathParser) parseLiteral() (interface{}, error) {
+	quote := p.peek()
+	if quote == '\'' || quote == '"' {
+		end := strings.IndexByte(p.input[p.position+1:], quote)
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated string at position %d", p.position)
+		}
+		value := p.input[p.position+1 : p.position+1+end]
+		p.position += end + 2
+		return value, nil
+	}
+
+	start := p.position
+	for p.position < len(p.input) && !strings.ContainsRune(",]) &|", rune(p.input[p.position])) {
+		p.position++
+	}
+	token := p.input[start:p.position]
+	switch token {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	number, err := strconv.ParseFloat(token, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid literal <%s> at position %d", token, start)
+	}
+	return number, nil
+}
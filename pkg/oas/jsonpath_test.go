@@ -0,0 +1,82 @@
+package oas
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const jsonPathTestDocument = `
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      tags: [pets]
+    post:
+      operationId: createPet
+      deprecated: true
+      x-rank: 3
+  /stores:
+    get:
+      operationId: listStores
+      x-rank: 1
+tags:
+  - name: pets
+  - name: stores
+`
+
+func TestJsonPathSelect(t *testing.T) {
+	var document yaml.Node
+	if err := yaml.Unmarshal([]byte(jsonPathTestDocument), &document); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		expression string
+		expected   []string
+	}{
+		{"$.paths['/pets'].get.operationId", []string{"listPets"}},
+		{`$.paths["/pets","/stores"].get.operationId`, []string{"listPets", "listStores"}},
+		{"$.paths.*.*.operationId", []string{"listPets", "createPet", "listStores"}},
+		{"$..operationId", []string{"listPets", "createPet", "listStores"}},
+		{"$.tags[0].name", []string{"pets"}},
+		{"$.tags[-1].name", []string{"stores"}},
+		{"$.tags[*].name", []string{"pets", "stores"}},
+		{"$.paths.*[?(@.deprecated == true)].operationId", []string{"createPet"}},
+		{"$.paths.*[?@.deprecated].operationId", []string{"createPet"}},
+		{"$.paths.*[?!@.deprecated].operationId", []string{"listPets", "listStores"}},
+		{"$.paths.*[?(@['x-rank'] >= 2 || @.operationId == 'listPets')].operationId", []string{"listPets", "createPet"}},
+		{"$.paths.*[?(@.x-rank < 3 && @.x-rank != null)].operationId", []string{"listStores"}},
+		{"$.missing.*", []string{}},
+	}
+	for _, c := range cases {
+		t.Run(c.expression, func(t *testing.T) {
+			path, err := CompileJsonPath(c.expression)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			values := []string{}
+			for _, node := range path.Select(&document) {
+				values = append(values, node.Value)
+			}
+			if !reflect.DeepEqual(values, c.expected) {
+				t.Errorf("got %v, want %v", values, c.expected)
+			}
+		})
+	}
+}
+
+func TestCompileJsonPathErrors(t *testing.T) {
+	for _, expression := range []string{
+		"$.tags[0",
+		"$.tags['name]",
+		"$.paths[?(@.deprecated == true]",
+		"$.paths[?(deprecated)]",
+		"$.paths[?(!@.a == 1)]",
+	} {
+		if _, err := CompileJsonPath(expression); err == nil {
+			t.Errorf("<%s>: expected an error", expression)
+		}
+	}
+}
@@ -0,0 +1,288 @@
+package oas
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// JsonSchemaDialect is the meta-schema of the exported JSON Schema documents.
+const JsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Directory of the repository receiving the published JSON Schema documents.
+const schemasDirectory = "schemas"
+
+type SchemaExportOpts struct {
+	SpecificationFile string
+	OutputDirectory   string
+
+	// BaseUrl used to build the $id of the exported documents. No $id is set when empty.
+	BaseUrl string
+}
+
+func NewSchemaExportOpts() *SchemaExportOpts {
+	return &SchemaExportOpts{
+		OutputDirectory: ".",
+		BaseUrl:         "",
+	}
+}
+
+// ExportSchemas writes each component schema of a specification as a standalone JSON Schema document.
+func ExportSchemas(opts *SchemaExportOpts) error {
+	log.Infof("Exporting schemas of specification: %s.", opts.SpecificationFile)
+
+	// Parse specification
+	oas3Source, err := ParseFile(opts.SpecificationFile)
+	if err != nil {
+		return err
+	}
+
+	// Convert schemas
+	documents, err := oas3Source.specification.JsonSchemas(opts.BaseUrl)
+	if err != nil {
+		return err
+	}
+
+	// Write documents
+	sink := NewFileSystemSink(opts.OutputDirectory)
+	for _, name := range sortedKeys(documents) {
+		if err := validatePathSegment(name); err != nil {
+			return fmt.Errorf("schema name cannot be used as file name: %v", err)
+		}
+		err = sink.Write(name+".json", documents[name])
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Infof("%d schemas exported.", len(documents))
+	return nil
+}
+
+// JsonSchemas converts every component schema into a marshalled JSON Schema 2020-12 document, by name.
+// Referenced component schemas are embedded under $defs, so that each document is self-contained.
+func (s *OAS3Specification) JsonSchemas(baseUrl string) (map[string][]byte, error) {
+	documents := make(map[string][]byte)
+	for name := range s.Components.Schemas {
+		document := s.JsonSchema(name)
+		if baseUrl != "" {
+			document["$id"] = strings.TrimSuffix(baseUrl, "/") + "/" + name + ".json"
+		}
+		marshalled, err := marshallCanonicalJson(document)
+		if err != nil {
+			return nil, fmt.Errorf("schema <%s>: %v", name, err)
+		}
+		documents[name] = marshalled
+	}
+	return documents, nil
+}
+
+// JsonSchema converts a component schema into a JSON Schema 2020-12 document.
+func (s *OAS3Specification) JsonSchema(name string) map[string]interface{} {
+	converter := &jsonSchemaConverter{specification: s, defs: make(map[string]interface{})}
+	document := converter.convert(s.Components.Schemas[name], name)
+	document["$schema"] = JsonSchemaDialect
+
+	// The root schema references itself through "#" rather than through $defs.
+	delete(converter.defs, name)
+	if len(converter.defs) > 0 {
+		document["$defs"] = converter.defs
+	}
+	return document
+}
+
+type jsonSchemaConverter struct {
+	specification *OAS3Specification
+	defs          map[string]interface{}
+}
+
+// convert converts an OAS 3.0 schema object into a JSON Schema 2020-12 schema. The root name is
+// used to turn recursive references to the root schema into "#".
+func (c *jsonSchemaConverter) convert(schema *OAS3Schema, rootName string) map[string]interface{} {
+	result := make(map[string]interface{})
+	if schema == nil {
+		return result
+	}
+
+	// References
+	if name, ok := SchemaRefName(schema); ok {
+		if name == rootName {
+			result["$ref"] = "#"
+			return result
+		}
+		if _, found := c.defs[name]; !found {
+			c.defs[name] = map[string]interface{}{}
+			c.defs[name] = c.convert(c.specification.Components.Schemas[name], rootName)
+		}
+		result["$ref"] = "#/$defs/" + name
+		return result
+	}
+
+	// Annotations
+	setIfNotEmpty(result, "title", schema.Title)
+	setIfNotEmpty(result, "description", schema.Description)
+	setIfNotEmpty(result, "format", schema.Format)
+	setIfNotEmpty(result, "pattern", schema.Pattern)
+	if schema.Default != nil {
+		result["default"] = normalizeGenericValue(schema.Default)
+	}
+	if schema.Example != nil {
+		result["examples"] = []interface{}{normalizeGenericValue(schema.Example)}
+	}
+	if schema.Deprecated {
+		result["deprecated"] = true
+	}
+	if schema.ReadOnly {
+		result["readOnly"] = true
+	}
+	if schema.WriteOnly {
+		result["writeOnly"] = true
+	}
+
+	// Type and nullability
+	if schema.Type != "" {
+		if schema.Nullable {
+			result["type"] = []interface{}{schema.Type, "null"}
+		} else {
+			result["type"] = schema.Type
+		}
+	}
+	if len(schema.Enum) > 0 {
+		enum := []interface{}{}
+		hasNull := false
+		for _, value := range schema.Enum {
+			hasNull = hasNull || value == nil
+			enum = append(enum, normalizeGenericValue(value))
+		}
+		if schema.Nullable && !hasNull {
+			enum = append(enum, nil)
+		}
+		result["enum"] = enum
+	}
+
+	// Numeric constraints: exclusive bounds are booleans in OAS 3.0 and numbers in JSON Schema 2020-12.
+	if schema.Minimum != nil {
+		if schema.ExclusiveMinimum {
+			result["exclusiveMinimum"] = *schema.Minimum
+		} else {
+			result["minimum"] = *schema.Minimum
+		}
+	}
+	if schema.Maximum != nil {
+		if schema.ExclusiveMaximum {
+			result["exclusiveMaximum"] = *schema.Maximum
+		} else {
+			result["maximum"] = *schema.Maximum
+		}
+	}
+	if schema.MultipleOf != nil {
+		result["multipleOf"] = *schema.MultipleOf
+	}
+
+	// Other constraints
+	setIfNotNil(result, "minLength", schema.MinLength)
+	setIfNotNil(result, "maxLength", schema.MaxLength)
+	setIfNotNil(result, "minItems", schema.MinItems)
+	setIfNotNil(result, "maxItems", schema.MaxItems)
+	setIfNotNil(result, "minProperties", schema.MinProperties)
+	setIfNotNil(result, "maxProperties", schema.MaxProperties)
+	if schema.UniqueItems {
+		result["uniqueItems"] = true
+	}
+	if len(schema.Required) > 0 {
+		result["required"] = schema.Required
+	}
+
+	// Sub-schemas
+	if len(schema.Properties) > 0 {
+		properties := make(map[string]interface{})
+		for name, property := range schema.Properties {
+			properties[name] = c.convert(property, rootName)
+		}
+		result["properties"] = properties
+	}
+	if schema.AdditionalProperties != nil {
+		if additionalSchema, allowed := schema.AdditionalPropertiesSchema(); additionalSchema != nil {
+			result["additionalProperties"] = c.convert(additionalSchema, rootName)
+		} else {
+			result["additionalProperties"] = allowed
+		}
+	}
+	if schema.Items != nil {
+		result["items"] = c.convert(schema.Items, rootName)
+	}
+	for keyword, subSchemas := range map[string][]*OAS3Schema{"allOf": schema.AllOf, "oneOf": schema.OneOf, "anyOf": schema.AnyOf} {
+		if len(subSchemas) == 0 {
+			continue
+		}
+		converted := []interface{}{}
+		for _, subSchema := range subSchemas {
+			converted = append(converted, c.convert(subSchema, rootName))
+		}
+		result[keyword] = converted
+	}
+	if schema.Not != nil {
+		result["not"] = c.convert(schema.Not, rootName)
+	}
+
+	return result
+}
+
+// buildSchemaArtifacts converts the component schemas of the indexed specifications into JSON Schema
+// documents named schemas/<id>/<version>/<schema>.json, and lists them in the index entries.
+func buildSchemaArtifacts(o *IndexOpts, index *V1_RepositoryIndex, resolver *conflictResolver, sources map[string]*OAS3Source) (map[string][]byte, error) {
+	artifacts := make(map[string][]byte)
+	for id, entries := range index.Entries {
+		for i := range entries {
+			entry := &entries[i]
+			source := sources[resolver.sources[id+"@"+entryVersionKey(entry)]]
+			if source == nil {
+				continue
+			}
+
+			if err := validatePathSegment(entryVersionKey(entry)); err != nil {
+				return nil, fmt.Errorf("specification <%s>: version cannot be used in the path of the schemas: %v", source.path, err)
+			}
+			directory := strings.Join([]string{schemasDirectory, id, entryVersionKey(entry)}, "/")
+			documents, err := source.specification.JsonSchemas(o.publicUrl(directory))
+			if err != nil {
+				return nil, fmt.Errorf("specification <%s>: %v", source.path, err)
+			}
+			entry.Schemas = []V1_RepositoryIndexSchemaEntry{}
+			for _, name := range sortedKeys(documents) {
+				if err := validatePathSegment(name); err != nil {
+					return nil, fmt.Errorf("specification <%s>: schema name cannot be used in the path of the schemas: %v", source.path, err)
+				}
+				artifacts[directory+"/"+name+".json"] = documents[name]
+				entry.Schemas = append(entry.Schemas, V1_RepositoryIndexSchemaEntry{
+					Name: name,
+					Url:  o.publicUrl(directory + "/" + name + ".json"),
+				})
+			}
+		}
+	}
+	return artifacts, nil
+}
+
+func setIfNotEmpty(target map[string]interface{}, key string, value string) {
+	if value != "" {
+		target[key] = value
+	}
+}
+
+func setIfNotNil(target map[string]interface{}, key string, value *int) {
+	if value != nil {
+		target[key] = *value
+	}
+}
+
+func sortedKeys(values map[string][]byte) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,165 @@
+package oas
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestIndexExportSchemasPaths(t *testing.T) {
+	cases := []struct {
+		name     string
+		title    string
+		version  string
+		schema   string
+		err      string
+		expected string
+	}{
+		{"valid", "Pet Store", "1.0.0", "Pet", "", "schemas/pet-store/1.0.0/Pet.json"},
+		{"title escaping the directory", "../../escaped", "1.0.0", "Pet", "", "schemas/escaped/1.0.0/Pet.json"},
+		{"version escaping the directory", "Pet Store", "../../1", "Pet", "version cannot be used", ""},
+		{"schema name escaping the directory", "Pet Store", "1.0.0", "../../Pet", "schema name cannot be used", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			root := t.TempDir()
+			directory := filepath.Join(root, "repository")
+			content := "openapi: 3.0.3\ninfo:\n  title: '" + c.title + "'\n  version: '" + c.version + "'\npaths: {}\ncomponents:\n  schemas:\n    '" + c.schema + "':\n      type: string\n"
+			writeTestFile(t, filepath.Join(directory, "openapi.yaml"), content)
+
+			opts := NewIndexOpts()
+			opts.Directory = directory
+			opts.ExportSchemas = true
+			err := Index(opts)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("got error <%v>, want <%s>", err, c.err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if c.expected != "" {
+				if _, err := os.Stat(filepath.Join(directory, filepath.FromSlash(c.expected))); err != nil {
+					t.Errorf("schema not exported at <%s>: %v", c.expected, err)
+				}
+			}
+			entries, _ := ioutil.ReadDir(root)
+			if len(entries) != 1 {
+				t.Errorf("files written outside of the indexed directory: %v", entries)
+			}
+		})
+	}
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const jsonSchemaTestSpecification = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+          nullable: true
+        status:
+          type: string
+          enum: [available, sold]
+          nullable: true
+        age:
+          type: integer
+          minimum: 0
+          exclusiveMinimum: true
+          maximum: 30
+        weight:
+          type: number
+          maximum: 100
+          exclusiveMaximum: true
+        owner:
+          $ref: '#/components/schemas/Owner'
+        parent:
+          $ref: '#/components/schemas/Pet'
+    Owner:
+      type: object
+      properties:
+        pets:
+          type: array
+          items:
+            $ref: '#/components/schemas/Pet'
+        address:
+          $ref: '#/components/schemas/Address'
+    Address:
+      type: string
+`
+
+func TestJsonSchemas(t *testing.T) {
+	specification := &OAS3Specification{}
+	if err := yaml.Unmarshal([]byte(jsonSchemaTestSpecification), specification); err != nil {
+		t.Fatal(err)
+	}
+	documents, err := specification.JsonSchemas("https://apis.example.com/schemas/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pet map[string]interface{}
+	if err := json.Unmarshal(documents["Pet"], &pet); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		keys     []string
+		expected interface{}
+	}{
+		{"dialect", []string{"$schema"}, JsonSchemaDialect},
+		{"identifier", []string{"$id"}, "https://apis.example.com/schemas/Pet.json"},
+		{"nullable type", []string{"properties", "name", "type"}, []interface{}{"string", "null"}},
+		{"nullable enum", []string{"properties", "status", "enum"}, []interface{}{"available", "sold", nil}},
+		{"exclusive minimum", []string{"properties", "age", "exclusiveMinimum"}, 0.0},
+		{"no inclusive minimum", []string{"properties", "age", "minimum"}, nil},
+		{"inclusive maximum", []string{"properties", "age", "maximum"}, 30.0},
+		{"exclusive maximum", []string{"properties", "weight", "exclusiveMaximum"}, 100.0},
+		{"reference to a component", []string{"properties", "owner", "$ref"}, "#/$defs/Owner"},
+		{"self-reference", []string{"properties", "parent", "$ref"}, "#"},
+		{"self-reference from a component", []string{"$defs", "Owner", "properties", "pets", "items", "$ref"}, "#"},
+		{"transitive component", []string{"$defs", "Owner", "properties", "address", "$ref"}, "#/$defs/Address"},
+		{"transitive component definition", []string{"$defs", "Address", "type"}, "string"},
+		{"root not in definitions", []string{"$defs", "Pet"}, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if value := lookupTestValue(pet, c.keys...); !reflect.DeepEqual(value, c.expected) {
+				t.Errorf("got %#v at %s, want %#v", value, strings.Join(c.keys, "."), c.expected)
+			}
+		})
+	}
+
+	// Documents without references have no definitions.
+	var address map[string]interface{}
+	if err := json.Unmarshal(documents["Address"], &address); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := address["$defs"]; found || address["type"] != "string" {
+		t.Errorf("unexpected document %v", address)
+	}
+}
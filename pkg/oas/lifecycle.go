@@ -0,0 +1,270 @@
+package oas
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LifecycleStage is the maturity of an API, declared by x-extra-info.lifecycle.
+type LifecycleStage string
+
+const (
+	LifecycleStageExperimental LifecycleStage = "experimental"
+	LifecycleStageBeta         LifecycleStage = "beta"
+	LifecycleStageGA           LifecycleStage = "ga"
+	LifecycleStageDeprecated   LifecycleStage = "deprecated"
+	LifecycleStageRetired      LifecycleStage = "retired"
+)
+
+// Layout of the sunset dates.
+const sunsetDateLayout = "2006-01-02"
+
+// ParseLifecycleStage converts a string into a lifecycle stage, ignoring the case. An empty string
+// is an undeclared stage.
+func ParseLifecycleStage(value string) (LifecycleStage, error) {
+	switch stage := LifecycleStage(strings.ToLower(value)); stage {
+	case "", LifecycleStageExperimental, LifecycleStageBeta, LifecycleStageGA, LifecycleStageDeprecated, LifecycleStageRetired:
+		return stage, nil
+	default:
+		return "", fmt.Errorf("unsupported lifecycle stage <%s>: expected one of experimental, beta, ga, deprecated, retired", value)
+	}
+}
+
+// Ended tells whether the stage is deprecated or retired.
+func (s LifecycleStage) Ended() bool {
+	return s == LifecycleStageDeprecated || s == LifecycleStageRetired
+}
+
+// validateLifecycle checks the lifecycle metadata of a specification and returns its normalized stage.
+// Sunset dates and replacements are only allowed on deprecated or retired specifications.
+func validateLifecycle(oas3Source *OAS3Source) (LifecycleStage, error) {
+	extraInfo := &oas3Source.specification.Info.ExtraInfo
+
+	stage, err := ParseLifecycleStage(extraInfo.Lifecycle)
+	if err != nil {
+		return "", fmt.Errorf("specification <%s>: %v", oas3Source.path, err)
+	}
+	if extraInfo.SunsetDate != "" {
+		if _, err := time.Parse(sunsetDateLayout, extraInfo.SunsetDate); err != nil {
+			return "", fmt.Errorf("specification <%s>: invalid sunset date <%s>: expected YYYY-MM-DD", oas3Source.path, extraInfo.SunsetDate)
+		}
+	}
+	if stage != "" && !stage.Ended() {
+		if extraInfo.Deprecated {
+			return "", fmt.Errorf("specification <%s>: deprecated but declared in lifecycle stage <%s>", oas3Source.path, stage)
+		}
+		if extraInfo.SunsetDate != "" || extraInfo.ReplacedBy != "" {
+			return "", fmt.Errorf("specification <%s>: sunset date and replacement require the deprecated or retired lifecycle stage, not <%s>", oas3Source.path, stage)
+		}
+	}
+	return stage, nil
+}
+
+// OperationDeprecation is an operation flagged as deprecated, or announcing its deprecation with
+// the Deprecation or Sunset response headers.
+type OperationDeprecation struct {
+	Method string
+	Path   string
+
+	// Sunset date of the operation, as YYYY-MM-DD, when a Sunset header declares one.
+	Sunset string
+}
+
+// DeprecatedOperations lists the deprecated operations of a specification.
+func (s *OAS3Specification) DeprecatedOperations() []OperationDeprecation {
+	deprecations := []OperationDeprecation{}
+	for _, operation := range s.Operations() {
+		deprecated, sunset := operation.Operation.Deprecated, ""
+		for _, code := range SortedResponseCodes(operation.Operation) {
+			response := s.ResolveResponse(operation.Operation.Responses[code])
+			if response == nil {
+				continue
+			}
+			for name, header := range response.Headers {
+				switch strings.ToLower(name) {
+				case "deprecation":
+					deprecated = true
+				case "sunset":
+					deprecated = true
+					if date := s.headerDate(s.ResolveHeader(header)); date != "" && (sunset == "" || date < sunset) {
+						sunset = date
+					}
+				}
+			}
+		}
+		if deprecated {
+			deprecations = append(deprecations, OperationDeprecation{Method: operation.Method, Path: operation.Path, Sunset: sunset})
+		}
+	}
+	return deprecations
+}
+
+// headerDate returns the date declared by the example, default or single enum value of a header, as YYYY-MM-DD.
+// Values are HTTP dates as sent in Sunset headers, RFC 3339 timestamps or plain dates.
+func (s *OAS3Specification) headerDate(header *OAS3Header) string {
+	if header == nil {
+		return ""
+	}
+	values := []interface{}{header.Example}
+	if schema := s.ResolveSchema(header.Schema); schema != nil {
+		values = append(values, schema.Example, schema.Default)
+		if len(schema.Enum) == 1 {
+			values = append(values, schema.Enum[0])
+		}
+	}
+	for _, value := range values {
+		switch value := value.(type) {
+		case time.Time:
+			return value.UTC().Format(sunsetDateLayout)
+		case string:
+			if date, err := http.ParseTime(value); err == nil {
+				return date.UTC().Format(sunsetDateLayout)
+			}
+			if date, err := time.Parse(time.RFC3339, value); err == nil {
+				return date.UTC().Format(sunsetDateLayout)
+			}
+			if date, err := time.Parse(sunsetDateLayout, value); err == nil {
+				return date.Format(sunsetDateLayout)
+			}
+		}
+	}
+	return ""
+}
+
+// summarizeDeprecations returns the number of deprecated operations and their earliest sunset date.
+func summarizeDeprecations(deprecations []OperationDeprecation) (int, string) {
+	sunset := ""
+	for _, deprecation := range deprecations {
+		if deprecation.Sunset != "" && (sunset == "" || deprecation.Sunset < sunset) {
+			sunset = deprecation.Sunset
+		}
+	}
+	return len(deprecations), sunset
+}
+
+// Scopes of the lifecycle report entries.
+const (
+	// LifecycleScopeApi reports a specification past its own sunset date.
+	LifecycleScopeApi = "api"
+
+	// LifecycleScopeOperations reports a specification with operations past their sunset date.
+	LifecycleScopeOperations = "operations"
+)
+
+// LifecycleReportEntry is a specification, or some of its operations, past its sunset date.
+type LifecycleReportEntry struct {
+	Id                   string `yaml:"id" json:"id"`
+	Version              string `yaml:"version" json:"version"`
+	Scope                string `yaml:"scope" json:"scope"`
+	Stage                string `yaml:"stage" json:"stage"`
+	SunsetDate           string `yaml:"sunsetDate" json:"sunsetDate"`
+	DaysPast             int    `yaml:"daysPast" json:"daysPast"`
+	DeprecatedOperations int    `yaml:"deprecatedOperations" json:"deprecatedOperations"`
+	ReplacedBy           string `yaml:"replacedBy" json:"replacedBy"`
+	OwnerTeam            string `yaml:"ownerTeam" json:"ownerTeam"`
+	OnCall               string `yaml:"onCall" json:"onCall"`
+}
+
+type LifecycleOpts struct {
+	// Repository whose index is reported: a directory or an http(s) URL.
+	Repository string
+
+	// Date compared to the sunset dates. Defaults to today.
+	Date time.Time
+
+	// Format of the report: text or json.
+	Format string
+	Writer io.Writer
+}
+
+func NewLifecycleOpts() *LifecycleOpts {
+	return &LifecycleOpts{
+		Repository: ".",
+		Date:       time.Now(),
+		Format:     "text",
+		Writer:     os.Stdout,
+	}
+}
+
+// Lifecycle reports the specifications of a repository, or their operations, past their sunset date.
+func Lifecycle(opts *LifecycleOpts) error {
+	if opts.Format != "text" && opts.Format != "json" {
+		return fmt.Errorf("unsupported lifecycle format <%s>", opts.Format)
+	}
+
+	// Open repository
+	repository, err := OpenRepository(opts.Repository)
+	if err != nil {
+		return err
+	}
+
+	// Collect specifications past their sunset date
+	report := PastSunset(repository.Index, opts.Date)
+	log.Infof("%d specifications past their sunset date.", len(report))
+
+	// Write report
+	if opts.Format == "json" {
+		content, err := marshallCanonicalJson(report)
+		if err != nil {
+			return err
+		}
+		_, err = opts.Writer.Write(content)
+		return err
+	}
+	table := tabwriter.NewWriter(opts.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "ID\tVERSION\tSCOPE\tSTAGE\tSUNSET\tDAYS PAST\tDEPRECATED OPS\tREPLACED BY\tOWNER\tON-CALL")
+	for _, entry := range report {
+		fmt.Fprintf(table, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\t%s\n", entry.Id, entry.Version, entry.Scope, entry.Stage, entry.SunsetDate, entry.DaysPast, entry.DeprecatedOperations, entry.ReplacedBy, entry.OwnerTeam, entry.OnCall)
+	}
+	return table.Flush()
+}
+
+// PastSunset returns the entries of an index whose sunset date, or the earliest sunset date of
+// their operations, is before the given date, the longest past first.
+func PastSunset(index *V1_RepositoryIndex, date time.Time) []LifecycleReportEntry {
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.UTC)
+	report := []LifecycleReportEntry{}
+	for id, entries := range index.Entries {
+		for _, entry := range entries {
+			for scope, sunsetDate := range map[string]string{LifecycleScopeApi: entry.Lifecycle.SunsetDate, LifecycleScopeOperations: entry.Deprecation.Sunset} {
+				sunset, err := time.Parse(sunsetDateLayout, sunsetDate)
+				if err != nil || !sunset.Before(day) {
+					continue
+				}
+				report = append(report, LifecycleReportEntry{
+					Id:                   id,
+					Version:              entry.Version,
+					Scope:                scope,
+					Stage:                entry.Lifecycle.Stage,
+					SunsetDate:           sunsetDate,
+					DaysPast:             int(day.Sub(sunset).Hours() / 24),
+					DeprecatedOperations: entry.Deprecation.Operations,
+					ReplacedBy:           entry.Lifecycle.ReplacedBy,
+					OwnerTeam:            entry.Owner.Team,
+					OnCall:               entry.Owner.OnCall,
+				})
+			}
+		}
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].DaysPast != report[j].DaysPast {
+			return report[i].DaysPast > report[j].DaysPast
+		}
+		if report[i].Id != report[j].Id {
+			return report[i].Id < report[j].Id
+		}
+		if report[i].Version != report[j].Version {
+			return report[i].Version < report[j].Version
+		}
+		return report[i].Scope < report[j].Scope
+	})
+	return report
+}
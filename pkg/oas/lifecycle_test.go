@@ -0,0 +1,177 @@
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLifecycleStage(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected LifecycleStage
+		ended    bool
+		valid    bool
+	}{
+		{"", "", false, true},
+		{"experimental", LifecycleStageExperimental, false, true},
+		{"Beta", LifecycleStageBeta, false, true},
+		{"GA", LifecycleStageGA, false, true},
+		{"deprecated", LifecycleStageDeprecated, true, true},
+		{"Retired", LifecycleStageRetired, true, true},
+		{"stable", "", false, false},
+	}
+	for _, c := range cases {
+		stage, err := ParseLifecycleStage(c.value)
+		if (err == nil) != c.valid || stage != c.expected || stage.Ended() != c.ended {
+			t.Errorf("ParseLifecycleStage(%s): got <%s> ended <%t> and error <%v>", c.value, stage, stage.Ended(), err)
+		}
+	}
+}
+
+func TestIndexLifecycle(t *testing.T) {
+	cases := []struct {
+		name      string
+		extraInfo string
+		valid     bool
+	}{
+		{"no lifecycle", "", true},
+		{"sunset date without stage", "sunsetDate: 2021-01-10", true},
+		{"retired with sunset date", "lifecycle: retired\n    sunsetDate: 2021-01-10\n    replacedBy: pets@2", true},
+		{"unknown stage", "lifecycle: stable", false},
+		{"invalid sunset date", "lifecycle: deprecated\n    sunsetDate: 2021/01/10", false},
+		{"sunset date before deprecation", "lifecycle: beta\n    sunsetDate: 2021-01-10", false},
+		{"replacement before deprecation", "lifecycle: ga\n    replacedBy: pets@2", false},
+		{"deprecated in general availability", "lifecycle: ga\n    deprecated: true", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			directory := t.TempDir()
+			writeTestFile(t, filepath.Join(directory, "pets.yaml"), "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: 1.0.0\n  x-extra-info:\n    ownerTeam: pets\n    "+c.extraInfo+"\npaths: {}\n")
+			opts := NewIndexOpts()
+			opts.Directory = directory
+			if err := Index(opts); (err == nil) != c.valid {
+				t.Errorf("got error <%v>, want valid <%t>", err, c.valid)
+			}
+		})
+	}
+
+	directory := t.TempDir()
+	writeTestFile(t, filepath.Join(directory, "pets/1.0.0.yaml"), `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+  x-extra-info:
+    lifecycle: Deprecated
+    sunsetDate: 2021-01-10
+    replacedBy: pets@2.0.0
+    ownerTeam: pets
+    onCall: pets-oncall
+paths: {}
+`)
+	writeTestFile(t, filepath.Join(directory, "pets/2.0.0.yaml"), "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: 2.0.0\n  x-extra-info:\n    lifecycle: GA\n    ownerTeam: pets\npaths: {}\n")
+	opts := NewIndexOpts()
+	opts.Directory = directory
+	if err := Index(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	entries := readTestIndex(t, directory).Entries["pets"]
+	if len(entries) != 2 {
+		t.Fatalf("got entries %+v, want 2 versions", entries)
+	}
+	for _, entry := range entries {
+		expected := []string{"deprecated", "2021-01-10", "pets@2.0.0", "pets", "pets-oncall", "true"}
+		if entry.Version == "2.0.0" {
+			expected = []string{"ga", "", "", "pets", "", "false"}
+		}
+		actual := []string{entry.Lifecycle.Stage, entry.Lifecycle.SunsetDate, entry.Lifecycle.ReplacedBy, entry.Owner.Team, entry.Owner.OnCall, strconv.FormatBool(entry.Deprecated)}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("got lifecycle %v for version %s, want %v", actual, entry.Version, expected)
+		}
+	}
+}
+
+func newTestLifecycleIndex() *V1_RepositoryIndex {
+	entry := func(version string, sunsetDate string, operationsSunset string) V1_RepositoryIndexSpecificationEntry {
+		entry := V1_RepositoryIndexSpecificationEntry{Version: version}
+		entry.Lifecycle.Stage = "deprecated"
+		entry.Lifecycle.SunsetDate = sunsetDate
+		entry.Lifecycle.ReplacedBy = "pets@2.0.0"
+		entry.Deprecation.Operations = 2
+		entry.Deprecation.Sunset = operationsSunset
+		entry.Owner.Team = "pets"
+		entry.Owner.OnCall = "pets-oncall"
+		return entry
+	}
+	index := NewV1_RepositoryIndex()
+	index.Entries["pets"] = []V1_RepositoryIndexSpecificationEntry{entry("1.0.0", "2021-01-10", "2021-02-20"), entry("1.1.0", "2030-01-01", "")}
+	index.Entries["orders"] = []V1_RepositoryIndexSpecificationEntry{entry("1.0.0", "2021-03-01", "2021-02-28"), entry("1.1.0", "soon", "")}
+	return index
+}
+
+func TestPastSunset(t *testing.T) {
+	report := PastSunset(newTestLifecycleIndex(), time.Date(2021, 3, 1, 23, 0, 0, 0, time.UTC))
+	actual := []string{}
+	for _, entry := range report {
+		actual = append(actual, entry.Id+"@"+entry.Version+" "+entry.Scope+" "+entry.SunsetDate+" "+strconv.Itoa(entry.DaysPast))
+		if entry.Stage != "deprecated" || entry.DeprecatedOperations != 2 || entry.ReplacedBy != "pets@2.0.0" || entry.OwnerTeam != "pets" || entry.OnCall != "pets-oncall" {
+			t.Errorf("unexpected report entry %+v", entry)
+		}
+	}
+	expected := []string{
+		"pets@1.0.0 api 2021-01-10 50",
+		"pets@1.0.0 operations 2021-02-20 9",
+		"orders@1.0.0 operations 2021-02-28 1",
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got report %v, want %v", actual, expected)
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	directory := t.TempDir()
+	content, err := json.Marshal(newTestLifecycleIndex())
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, filepath.Join(directory, "index.json"), string(content))
+
+	opts := NewLifecycleOpts()
+	opts.Repository = directory
+	opts.Date = time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// JSON report
+	var output bytes.Buffer
+	opts.Format, opts.Writer = "json", &output
+	if err := Lifecycle(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var report []LifecycleReportEntry
+	if err := json.Unmarshal(output.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if expected := PastSunset(newTestLifecycleIndex(), opts.Date); !reflect.DeepEqual(report, expected) {
+		t.Errorf("got report %+v, want %+v", report, expected)
+	}
+
+	// Text report
+	output.Reset()
+	opts.Format = "text"
+	if err := Lifecycle(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "ID ") || strings.Join(strings.Fields(lines[1]), " ") != "pets 1.0.0 api deprecated 2021-01-10 50 2 pets@2.0.0 pets pets-oncall" {
+		t.Errorf("unexpected text report:\n%s", output.String())
+	}
+
+	opts.Format = "yaml"
+	if err := Lifecycle(opts); err == nil {
+		t.Error("got no error for an unsupported format")
+	}
+}
@@ -0,0 +1,257 @@
+package oas
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Prefix of the info extensions holding the metadata translated in one language, e.g. x-extra-info-fr.
+const localizedExtraInfoPrefix = "x-extra-info-"
+
+// LocalizedText is a text given either as a plain string, or as translations by language such as
+// {en: "Pets", fr: "Animaux"}. A plain string is stored under the empty language.
+type LocalizedText map[string]string
+
+func (t *LocalizedText) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*t = LocalizedText{"": value.Value}
+		return nil
+	}
+	translations := make(map[string]string)
+	err := value.Decode(&translations)
+	if err != nil {
+		return fmt.Errorf("expected a text or translations by language: %v", err)
+	}
+	*t = normalizeTranslations(translations)
+	return nil
+}
+
+func (t *LocalizedText) UnmarshalJSON(data []byte) error {
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		*t = LocalizedText{"": text}
+		return nil
+	}
+	translations := make(map[string]string)
+	err := json.Unmarshal(data, &translations)
+	if err != nil {
+		return fmt.Errorf("expected a text or translations by language: %v", err)
+	}
+	*t = normalizeTranslations(translations)
+	return nil
+}
+
+func (t LocalizedText) MarshalYAML() (interface{}, error) {
+	if text, plain := t[""]; plain && len(t) == 1 {
+		return text, nil
+	}
+	return map[string]string(t), nil
+}
+
+func (t LocalizedText) MarshalJSON() ([]byte, error) {
+	if text, plain := t[""]; plain && len(t) == 1 {
+		return json.Marshal(text)
+	}
+	return json.Marshal(map[string]string(t))
+}
+
+// Text returns the plain text, or else the translation in the language, or else the translation
+// in the first language by alphabetical order.
+func (t LocalizedText) Text(language string) string {
+	if text := t[""]; text != "" {
+		return text
+	}
+	if text := t[strings.ToLower(language)]; text != "" {
+		return text
+	}
+	languages := make([]string, 0, len(t))
+	for language := range t {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	for _, language := range languages {
+		if t[language] != "" {
+			return t[language]
+		}
+	}
+	return ""
+}
+
+// OAS3LocalizedExtraInfo is the metadata of a specification translated in one language.
+type OAS3LocalizedExtraInfo struct {
+	DisplayName     string `yaml:"displayName" json:"displayName"`
+	Description     string `yaml:"description" json:"description"`
+	LongDescription string `yaml:"longDescription" json:"longDescription"`
+}
+
+// parseLocalizedExtraInfo reads the x-extra-info-<language> extensions of the info object. JSON
+// documents are read as YAML, of which they are a subset.
+func parseLocalizedExtraInfo(content []byte) (map[string]OAS3LocalizedExtraInfo, error) {
+	var document struct {
+		Info map[string]yaml.Node `yaml:"info"`
+	}
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	localized := make(map[string]OAS3LocalizedExtraInfo)
+	for key, value := range document.Info {
+		if !strings.HasPrefix(key, localizedExtraInfoPrefix) {
+			continue
+		}
+		var extraInfo OAS3LocalizedExtraInfo
+		err = value.Decode(&extraInfo)
+		if err != nil {
+			return nil, fmt.Errorf("info.%s: %v", key, err)
+		}
+		localized[strings.ToLower(strings.TrimPrefix(key, localizedExtraInfoPrefix))] = extraInfo
+	}
+	return localized, nil
+}
+
+// V1_RepositoryIndexLocalizedEntry holds the metadata of an entry translated in one language.
+type V1_RepositoryIndexLocalizedEntry struct {
+	DisplayName     string `yaml:"displayName,omitempty" json:"displayName,omitempty"`
+	Description     string `yaml:"description,omitempty" json:"description,omitempty"`
+	LongDescription string `yaml:"longDescription,omitempty" json:"longDescription,omitempty"`
+}
+
+// buildLocalizedEntries gathers the translations of the translatable fields of x-extra-info and of
+// the x-extra-info-<language> extensions, the latter taking precedence.
+func buildLocalizedEntries(specification *OAS3Specification) map[string]V1_RepositoryIndexLocalizedEntry {
+	extraInfo := &specification.Info.ExtraInfo
+	localized := make(map[string]V1_RepositoryIndexLocalizedEntry)
+	translate := func(text LocalizedText, set func(entry *V1_RepositoryIndexLocalizedEntry, value string)) {
+		for language, value := range text {
+			if language != "" && value != "" {
+				entry := localized[language]
+				set(&entry, value)
+				localized[language] = entry
+			}
+		}
+	}
+	translate(extraInfo.DisplayName, func(e *V1_RepositoryIndexLocalizedEntry, v string) { e.DisplayName = v })
+	translate(extraInfo.Description, func(e *V1_RepositoryIndexLocalizedEntry, v string) { e.Description = v })
+	translate(extraInfo.LongDescription, func(e *V1_RepositoryIndexLocalizedEntry, v string) { e.LongDescription = v })
+	for language, translation := range specification.Info.LocalizedExtraInfo {
+		translate(LocalizedText{language: translation.DisplayName}, func(e *V1_RepositoryIndexLocalizedEntry, v string) { e.DisplayName = v })
+		translate(LocalizedText{language: translation.Description}, func(e *V1_RepositoryIndexLocalizedEntry, v string) { e.Description = v })
+		translate(LocalizedText{language: translation.LongDescription}, func(e *V1_RepositoryIndexLocalizedEntry, v string) { e.LongDescription = v })
+	}
+	if len(localized) == 0 {
+		return nil
+	}
+	return localized
+}
+
+// localizedText returns the plain text, or else its translation in the language.
+func localizedText(text LocalizedText, localized map[string]V1_RepositoryIndexLocalizedEntry, language string, field func(entry V1_RepositoryIndexLocalizedEntry) string) string {
+	if value := text[""]; value != "" {
+		return value
+	}
+	if value := field(localized[strings.ToLower(language)]); value != "" {
+		return value
+	}
+	return text.Text(language)
+}
+
+// Localize returns a copy of the entry whose display name and descriptions are translated in the
+// first of the languages having a translation.
+func (e V1_RepositoryIndexSpecificationEntry) Localize(languages []string) V1_RepositoryIndexSpecificationEntry {
+	for _, language := range languages {
+		translation, found := e.Localized[language]
+		if !found {
+			continue
+		}
+		if translation.DisplayName != "" {
+			e.DisplayName = translation.DisplayName
+		}
+		if translation.Description != "" {
+			e.Description = translation.Description
+		}
+		if translation.LongDescription != "" {
+			e.LongDescription = translation.LongDescription
+		}
+		break
+	}
+	return e
+}
+
+// Localize returns a copy of the index whose entries are translated in the first of the languages
+// having a translation.
+func (r V1_RepositoryIndex) Localize(languages []string) *V1_RepositoryIndex {
+	localizedIndex := NewV1_RepositoryIndex()
+	for id, entries := range r.Entries {
+		localizedEntries := make([]V1_RepositoryIndexSpecificationEntry, len(entries))
+		for i := range entries {
+			localizedEntries[i] = entries[i].Localize(languages)
+		}
+		localizedIndex.Entries[id] = localizedEntries
+	}
+	for alias, id := range r.Aliases {
+		localizedIndex.Aliases[alias] = id
+	}
+	return localizedIndex
+}
+
+// ParseAcceptLanguage returns the languages of an Accept-Language header by decreasing preference,
+// lower-cased, each regional language being followed by its base language (e.g. "fr-ca", "fr").
+func ParseAcceptLanguage(header string) []string {
+	type weightedLanguage struct {
+		language string
+		weight   float64
+	}
+	weightedLanguages := []weightedLanguage{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		language := strings.ToLower(strings.TrimSpace(fields[0]))
+		if language == "" || language == "*" {
+			continue
+		}
+		weight := 1.0
+		for _, parameter := range fields[1:] {
+			parameter = strings.TrimSpace(parameter)
+			if strings.HasPrefix(parameter, "q=") {
+				if parsed, err := strconv.ParseFloat(parameter[2:], 64); err == nil {
+					weight = parsed
+				}
+			}
+		}
+		if weight > 0 {
+			weightedLanguages = append(weightedLanguages, weightedLanguage{language, weight})
+		}
+	}
+	sort.SliceStable(weightedLanguages, func(i, j int) bool {
+		return weightedLanguages[i].weight > weightedLanguages[j].weight
+	})
+
+	languages := []string{}
+	seen := make(map[string]bool)
+	for _, weightedLanguage := range weightedLanguages {
+		candidates := []string{weightedLanguage.language}
+		if i := strings.Index(weightedLanguage.language, "-"); i > 0 {
+			candidates = append(candidates, weightedLanguage.language[:i])
+		}
+		for _, candidate := range candidates {
+			if !seen[candidate] {
+				seen[candidate] = true
+				languages = append(languages, candidate)
+			}
+		}
+	}
+	return languages
+}
+
+func normalizeTranslations(translations map[string]string) LocalizedText {
+	text := make(LocalizedText, len(translations))
+	for language, value := range translations {
+		text[strings.ToLower(language)] = value
+	}
+	return text
+}
@@ -0,0 +1,180 @@
+package oas
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestLocalizedText(t *testing.T) {
+	cases := []struct {
+		name     string
+		yaml     string
+		json     string
+		text     LocalizedText
+		language string
+		expected string
+	}{
+		{"plain text", "Pets", `"Pets"`, LocalizedText{"": "Pets"}, "fr", "Pets"},
+		{"translation", "{en: Pets, FR: Animaux}", `{"en":"Pets","FR":"Animaux"}`, LocalizedText{"en": "Pets", "fr": "Animaux"}, "FR", "Animaux"},
+		{"fallback on the first language", "{fr: Animaux, de: Haustiere}", `{"fr":"Animaux","de":"Haustiere"}`, LocalizedText{"fr": "Animaux", "de": "Haustiere"}, "en", "Haustiere"},
+		{"empty translation skipped", "{de: '', fr: Animaux}", `{"de":"","fr":"Animaux"}`, LocalizedText{"de": "", "fr": "Animaux"}, "de", "Animaux"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var fromYaml, fromJson LocalizedText
+			if err := yaml.Unmarshal([]byte(c.yaml), &fromYaml); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(c.json), &fromJson); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(fromYaml, c.text) || !reflect.DeepEqual(fromJson, c.text) {
+				t.Errorf("got %v from YAML and %v from JSON, want %v", fromYaml, fromJson, c.text)
+			}
+			if text := c.text.Text(c.language); text != c.expected {
+				t.Errorf("got text <%s> in %s, want <%s>", text, c.language, c.expected)
+			}
+		})
+	}
+
+	var text LocalizedText
+	if err := yaml.Unmarshal([]byte("[Pets]"), &text); err == nil {
+		t.Error("got no error for a sequence")
+	}
+	if content, err := json.Marshal(LocalizedText{"": "Pets"}); err != nil || string(content) != `"Pets"` {
+		t.Errorf("got %s %v for a plain text", content, err)
+	}
+	if content, err := yaml.Marshal(LocalizedText{"fr": "Animaux"}); err != nil || string(content) != "fr: Animaux\n" {
+		t.Errorf("got %s %v for a translation", content, err)
+	}
+}
+
+const localizationTestSpecification = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+  x-extra-info:
+    displayName:
+      en: Pets
+      FR: Animaux
+    description:
+      fr: Des animaux
+  x-extra-info-DE:
+    displayName: Haustiere
+    description: Tiere
+    longDescription: Viele Tiere
+paths: {}
+`
+
+func TestIndexLocalizedEntries(t *testing.T) {
+	localized := map[string]V1_RepositoryIndexLocalizedEntry{
+		"en": {DisplayName: "Pets"},
+		"fr": {DisplayName: "Animaux", Description: "Des animaux"},
+		"de": {DisplayName: "Haustiere", Description: "Tiere", LongDescription: "Viele Tiere"},
+	}
+	cases := []struct {
+		defaultLanguage string
+		displayName     string
+		description     string
+		longDescription string
+	}{
+		{"en", "Pets", "Des animaux", ""},
+		{"fr", "Animaux", "Des animaux", ""},
+		{"de", "Haustiere", "Tiere", "Viele Tiere"},
+		{"it", "Pets", "Des animaux", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.defaultLanguage, func(t *testing.T) {
+			directory := t.TempDir()
+			writeTestFile(t, filepath.Join(directory, "pets.yaml"), localizationTestSpecification)
+			opts := NewIndexOpts()
+			opts.Directory = directory
+			opts.DefaultLanguage = c.defaultLanguage
+			if err := Index(opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			entries := readTestIndex(t, directory).Entries["pets"]
+			if len(entries) != 1 {
+				t.Fatalf("got entries %+v", entries)
+			}
+			entry := entries[0]
+			if entry.DisplayName != c.displayName || entry.Description != c.description || entry.LongDescription != c.longDescription {
+				t.Errorf("got <%s> <%s> <%s>, want <%s> <%s> <%s>", entry.DisplayName, entry.Description, entry.LongDescription, c.displayName, c.description, c.longDescription)
+			}
+			if !reflect.DeepEqual(entry.Localized, localized) {
+				t.Errorf("got translations %+v, want %+v", entry.Localized, localized)
+			}
+		})
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	entry := V1_RepositoryIndexSpecificationEntry{DisplayName: "Pets", Description: "Pets", LongDescription: "Many pets"}
+	entry.Localized = map[string]V1_RepositoryIndexLocalizedEntry{
+		"fr": {DisplayName: "Animaux"},
+		"de": {DisplayName: "Haustiere", Description: "Tiere", LongDescription: "Viele Tiere"},
+	}
+	document := SearchDocument{DisplayName: entry.DisplayName, Description: entry.Description, Localized: localizedSearchEntries(entry.Localized)}
+
+	cases := []struct {
+		name      string
+		languages []string
+		expected  []string
+	}{
+		{"no language", nil, []string{"Pets", "Pets", "Many pets"}},
+		{"untranslated language", []string{"it"}, []string{"Pets", "Pets", "Many pets"}},
+		{"partial translation", []string{"fr", "de"}, []string{"Animaux", "Pets", "Many pets"}},
+		{"first translated language", []string{"it", "de", "fr"}, []string{"Haustiere", "Tiere", "Viele Tiere"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			localizedEntry := entry.Localize(c.languages)
+			if actual := []string{localizedEntry.DisplayName, localizedEntry.Description, localizedEntry.LongDescription}; !reflect.DeepEqual(actual, c.expected) {
+				t.Errorf("got entry %v, want %v", actual, c.expected)
+			}
+			if !reflect.DeepEqual(localizedEntry.Localized, entry.Localized) {
+				t.Errorf("got entry translations %+v", localizedEntry.Localized)
+			}
+
+			index := NewV1_RepositoryIndex()
+			index.Entries["pets"] = []V1_RepositoryIndexSpecificationEntry{entry}
+			index.Aliases["Pets"] = "pets"
+			localizedIndex := index.Localize(c.languages)
+			if localizedIndex.Entries["pets"][0].DisplayName != c.expected[0] || localizedIndex.Aliases["Pets"] != "pets" || index.Entries["pets"][0].DisplayName != "Pets" {
+				t.Errorf("got index %+v from %+v", localizedIndex, index)
+			}
+
+			localizedDocument := document.Localize(c.languages)
+			if actual := []string{localizedDocument.DisplayName, localizedDocument.Description}; !reflect.DeepEqual(actual, c.expected[:2]) || localizedDocument.Localized != nil {
+				t.Errorf("got document %+v, want %v", localizedDocument, c.expected[:2])
+			}
+			if document.DisplayName != "Pets" || len(document.Localized) != 2 {
+				t.Errorf("document modified: %+v", document)
+			}
+		})
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	cases := []struct {
+		header   string
+		expected []string
+	}{
+		{"", []string{}},
+		{"*", []string{}},
+		{"fr", []string{"fr"}},
+		{"fr-CA, fr;q=0.8, en;q=0.9", []string{"fr-ca", "fr", "en"}},
+		{"de;q=0.5, en-GB;q=0.7, en-US;q=0.7", []string{"en-gb", "en", "en-us", "de"}},
+		{"it;q=0, es;q=invalid", []string{"es"}},
+	}
+	for _, c := range cases {
+		if languages := ParseAcceptLanguage(c.header); !reflect.DeepEqual(languages, c.expected) {
+			t.Errorf("ParseAcceptLanguage(%s): got %v, want %v", c.header, languages, c.expected)
+		}
+	}
+}
@@ -0,0 +1,493 @@
+package oas
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComponentTypes lists the sections of the components object, in the order in which they are written.
+var ComponentTypes = []string{"schemas", "responses", "parameters", "examples", "requestBodies", "headers", "securitySchemes", "links", "callbacks"}
+
+var componentNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+type MergeOpts struct {
+	// Repository containing the specifications: a directory or an http(s) URL.
+	Repository string
+
+	// Sources are references such as "name@version", optionally followed by "=/prefix" to choose the
+	// prefix of their paths. The prefix defaults to the base path of the first server of the source.
+	Sources []string
+
+	Title       string
+	Version     string
+	Description string
+
+	// ServerUrl of the gateway. Defaults to the origin of the first server of the first source.
+	ServerUrl string
+
+	// OutputFile receiving the result, "-" for stdout. Its extension selects the format.
+	OutputFile string
+}
+
+func NewMergeOpts() *MergeOpts {
+	return &MergeOpts{
+		Repository: ".",
+		Sources:    []string{},
+		Title:      "Merged API",
+		Version:    "1.0.0",
+		OutputFile: "-",
+	}
+}
+
+// MergeSource is a specification to merge, whose paths are prefixed and whose conflicting components
+// are renamed with its namespace.
+type MergeSource struct {
+	Name      string
+	Namespace string
+	Prefix    string
+	Source    *OAS3Source
+	Document  *yaml.Node
+}
+
+// MergeConflict reports two sources defining the same element. Conflicts which are not resolved
+// prevent the merge.
+type MergeConflict struct {
+	Location string
+	Message  string
+	Resolved bool
+}
+
+func (c MergeConflict) String() string {
+	return c.Location + ": " + c.Message
+}
+
+// Merge combines several specifications into one document.
+func Merge(opts *MergeOpts) error {
+	log.Infof("Merging %d specifications.", len(opts.Sources))
+
+	// Open repository
+	repository, err := OpenRepositoryIfExists(opts.Repository)
+	if err != nil {
+		return err
+	}
+
+	// Load sources
+	sources := []*MergeSource{}
+	for _, reference := range opts.Sources {
+		source, err := LoadMergeSource(repository, reference)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source)
+	}
+
+	// Merge
+	document, conflicts := MergeSpecifications(opts, sources)
+	unresolved := 0
+	for _, conflict := range conflicts {
+		if conflict.Resolved {
+			log.Warnf("Merge conflict resolved: %s", conflict)
+		} else {
+			log.Errorf("Merge conflict: %s", conflict)
+			unresolved++
+		}
+	}
+	if unresolved > 0 {
+		return fmt.Errorf("%d irreconcilable merge conflicts", unresolved)
+	}
+
+	// Write result
+	content, err := marshallNode(document, opts.OutputFile)
+	if err != nil {
+		return err
+	}
+	if opts.OutputFile == "-" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+	log.Debugf("Writing %s.", opts.OutputFile)
+	return ioutil.WriteFile(opts.OutputFile, content, 0644)
+}
+
+// LoadMergeSource loads a reference such as "name@version=/prefix" from a repository, or from a file.
+func LoadMergeSource(repository *Repository, reference string) (*MergeSource, error) {
+	name, prefix := reference, ""
+	prefixSet := false
+	if i := strings.Index(reference, "="); i >= 0 {
+		name, prefix, prefixSet = reference[:i], reference[i+1:], true
+	}
+
+	source, content, err := repository.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	var document yaml.Node
+	err = yaml.Unmarshal(content, &document)
+	if err != nil {
+		return nil, fmt.Errorf("specification <%s>: %v", name, err)
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("specification <%s> is not an object", name)
+	}
+
+	id, err := computeId(NewIndexOpts(), source)
+	if err != nil {
+		return nil, err
+	}
+	if !prefixSet {
+		prefix = source.specification.BasePath()
+	}
+	return &MergeSource{
+		Name:      name,
+		Namespace: componentNameRegexp.ReplaceAllString(id, "_"),
+		Prefix:    "/" + strings.Trim(prefix, "/"),
+		Source:    source,
+		Document:  document.Content[0],
+	}, nil
+}
+
+// MergeSpecifications merges sources in order. Paths are prefixed, components with the same name but a
+// different definition are renamed with the namespace of their source, tags are merged by name and the
+// root security requirements are moved to the operations.
+func MergeSpecifications(opts *MergeOpts, sources []*MergeSource) (*yaml.Node, []MergeConflict) {
+	merger := &specificationMerger{
+		paths:        newMappingNode(),
+		components:   make(map[string]*yaml.Node),
+		tags:         newSequenceNode(),
+		operationIds: make(map[string]string),
+	}
+	for _, componentType := range ComponentTypes {
+		merger.components[componentType] = newMappingNode()
+	}
+
+	openApi := ""
+	descriptions := []string{}
+	for _, source := range sources {
+		log.Debugf("Merge <%s> under <%s>.", source.Name, source.Prefix)
+		version := source.Source.specification.OpenApi
+		if openApi == "" {
+			openApi = version
+		} else if majorMinor(version) != majorMinor(openApi) {
+			merger.conflict(source.Name, fmt.Sprintf("OpenAPI version %s differs from %s", version, openApi), false)
+			continue
+		}
+		merger.merge(source)
+		info := source.Source.specification.Info
+		descriptions = append(descriptions, fmt.Sprintf("- %s %s, under `%s`", info.Title, info.Version, source.Prefix))
+	}
+
+	// Info
+	info := newMappingNode()
+	setMappingValue(info, "title", newScalarNode(opts.Title))
+	setMappingValue(info, "version", newScalarNode(opts.Version))
+	description := opts.Description
+	if description == "" {
+		description = "Aggregation of:\n" + strings.Join(descriptions, "\n") + "\n"
+	}
+	setMappingValue(info, "description", newScalarNode(description))
+
+	// Document
+	root := newMappingNode()
+	setMappingValue(root, "openapi", newScalarNode(openApi))
+	setMappingValue(root, "info", info)
+	serverUrl := opts.ServerUrl
+	if serverUrl == "" && len(sources) > 0 {
+		serverUrl = serverOrigin(sources[0].Source.specification)
+	}
+	if serverUrl != "" {
+		server := newMappingNode()
+		setMappingValue(server, "url", newScalarNode(serverUrl))
+		setMappingValue(root, "servers", &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{server}})
+	}
+	if len(merger.tags.Content) > 0 {
+		setMappingValue(root, "tags", merger.tags)
+	}
+	setMappingValue(root, "paths", merger.paths)
+	components := newMappingNode()
+	for _, componentType := range ComponentTypes {
+		if len(merger.components[componentType].Content) > 0 {
+			setMappingValue(components, componentType, merger.components[componentType])
+		}
+	}
+	if len(components.Content) > 0 {
+		setMappingValue(root, "components", components)
+	}
+
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}, merger.conflicts
+}
+
+type specificationMerger struct {
+	paths        *yaml.Node
+	components   map[string]*yaml.Node
+	tags         *yaml.Node
+	operationIds map[string]string
+	conflicts    []MergeConflict
+}
+
+func (m *specificationMerger) conflict(location string, message string, resolved bool) {
+	m.conflicts = append(m.conflicts, MergeConflict{Location: location, Message: message, Resolved: resolved})
+}
+
+func (m *specificationMerger) merge(source *MergeSource) {
+	document := cloneNode(source.Document)
+	pushDownSecurity(document)
+
+	// Rename conflicting components. Renaming a component rewrites the references to it, which may make
+	// components referencing it differ from their merged counterpart: repeat until nothing is renamed.
+	renamed := make(map[*yaml.Node]bool)
+	sourceComponents := mappingValue(document, "components")
+	for {
+		renames := make(map[string]string)
+		securitySchemeRenames := make(map[string]string)
+		for _, componentType := range ComponentTypes {
+			section := mappingValue(sourceComponents, componentType)
+			if section == nil {
+				continue
+			}
+			for i := 0; i+1 < len(section.Content); i += 2 {
+				name := section.Content[i].Value
+				existing := mappingValue(m.components[componentType], name)
+				if renamed[section.Content[i]] || existing == nil || nodesEqual(existing, section.Content[i+1]) {
+					continue
+				}
+				renamed[section.Content[i]] = true
+				newName := source.Namespace + "." + name
+				if mappingValue(m.components[componentType], newName) != nil {
+					m.conflict(source.Name+" #/components/"+componentType+"/"+name, "component already defined, also under the namespaced name "+newName, false)
+					continue
+				}
+				m.conflict(source.Name+" #/components/"+componentType+"/"+name, "component defined differently by another source, renamed "+newName, true)
+				renames["#/components/"+componentType+"/"+name] = "#/components/" + componentType + "/" + newName
+				if componentType == "securitySchemes" {
+					securitySchemeRenames[name] = newName
+				}
+				section.Content[i].Value = newName
+			}
+		}
+		if len(renames) == 0 {
+			break
+		}
+		rewriteReferences(document, renames)
+		renameSecurityRequirements(document, securitySchemeRenames)
+	}
+
+	// Components
+	for _, componentType := range ComponentTypes {
+		section := mappingValue(sourceComponents, componentType)
+		if section == nil {
+			continue
+		}
+		for i := 0; i+1 < len(section.Content); i += 2 {
+			if mappingValue(m.components[componentType], section.Content[i].Value) == nil {
+				m.components[componentType].Content = append(m.components[componentType].Content, section.Content[i], section.Content[i+1])
+			}
+		}
+	}
+
+	// Tags
+	if tags := mappingValue(document, "tags"); tags != nil {
+		for _, tag := range tags.Content {
+			name := mappingValue(tag, "name")
+			if name == nil {
+				continue
+			}
+			var existing *yaml.Node
+			for _, mergedTag := range m.tags.Content {
+				if mergedName := mappingValue(mergedTag, "name"); mergedName != nil && mergedName.Value == name.Value {
+					existing = mergedTag
+				}
+			}
+			if existing == nil {
+				m.tags.Content = append(m.tags.Content, tag)
+			} else if !nodesEqual(existing, tag) {
+				m.conflict(source.Name+" tag "+name.Value, "tag defined differently by another source, first definition kept", true)
+			}
+		}
+	}
+
+	// Paths
+	paths := mappingValue(document, "paths")
+	if paths == nil {
+		return
+	}
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		path := strings.TrimSuffix(source.Prefix, "/") + paths.Content[i].Value
+		pathItem := resolveAlias(paths.Content[i+1])
+		m.renameOperationIds(source, path, pathItem)
+
+		existing := mappingValue(m.paths, path)
+		if existing == nil {
+			m.paths.Content = append(m.paths.Content, newScalarNode(path), pathItem)
+			continue
+		}
+		for j := 0; j+1 < len(pathItem.Content); j += 2 {
+			key, value := pathItem.Content[j].Value, pathItem.Content[j+1]
+			existingValue := mappingValue(existing, key)
+			switch {
+			case existingValue == nil:
+				existing.Content = append(existing.Content, pathItem.Content[j], value)
+			case isHttpMethod(key):
+				m.conflict(source.Name+" "+strings.ToUpper(key)+" "+path, "operation already defined by another source", false)
+			case !nodesEqual(existingValue, value):
+				m.conflict(source.Name+" "+path+" "+key, "path item property defined differently by another source", false)
+			}
+		}
+	}
+}
+
+// renameOperationIds prefixes the operation ids already used by another source with the namespace.
+func (m *specificationMerger) renameOperationIds(source *MergeSource, path string, pathItem *yaml.Node) {
+	for j := 0; j+1 < len(pathItem.Content); j += 2 {
+		if !isHttpMethod(pathItem.Content[j].Value) {
+			continue
+		}
+		operationId := mappingValue(pathItem.Content[j+1], "operationId")
+		if operationId == nil {
+			continue
+		}
+		if owner, found := m.operationIds[operationId.Value]; found && owner != source.Name {
+			newOperationId := source.Namespace + "_" + operationId.Value
+			m.conflict(source.Name+" "+strings.ToUpper(pathItem.Content[j].Value)+" "+path, "operation id "+operationId.Value+" already used by "+owner+", renamed "+newOperationId, true)
+			operationId.Value = newOperationId
+		}
+		m.operationIds[operationId.Value] = source.Name
+	}
+}
+
+// pushDownSecurity copies the root security requirements to the operations without their own, and
+// removes them from the root, since they would apply to the operations of every source once merged.
+func pushDownSecurity(document *yaml.Node) {
+	security := mappingValue(document, "security")
+	if security == nil {
+		return
+	}
+	removeNode(document, security)
+
+	paths := mappingValue(document, "paths")
+	if paths == nil {
+		return
+	}
+	for i := 1; i < len(paths.Content); i += 2 {
+		pathItem := resolveAlias(paths.Content[i])
+		for j := 0; j+1 < len(pathItem.Content); j += 2 {
+			operation := pathItem.Content[j+1]
+			if isHttpMethod(pathItem.Content[j].Value) && operation.Kind == yaml.MappingNode && mappingValue(operation, "security") == nil {
+				setMappingValue(operation, "security", cloneNode(security))
+			}
+		}
+	}
+}
+
+// rewriteReferences replaces the references to renamed components, in $ref and discriminator mappings.
+func rewriteReferences(node *yaml.Node, renames map[string]string) {
+	if node.Kind == yaml.ScalarNode && strings.HasPrefix(node.Value, "#/components/") {
+		for oldRef, newRef := range renames {
+			if node.Value == oldRef || strings.HasPrefix(node.Value, oldRef+"/") {
+				node.Value = newRef + strings.TrimPrefix(node.Value, oldRef)
+				return
+			}
+		}
+	}
+	for _, child := range node.Content {
+		rewriteReferences(child, renames)
+	}
+}
+
+// renameSecurityRequirements renames the security schemes used by the security requirements of operations.
+func renameSecurityRequirements(document *yaml.Node, renames map[string]string) {
+	paths := mappingValue(document, "paths")
+	if paths == nil || len(renames) == 0 {
+		return
+	}
+	for i := 1; i < len(paths.Content); i += 2 {
+		pathItem := resolveAlias(paths.Content[i])
+		for j := 0; j+1 < len(pathItem.Content); j += 2 {
+			if !isHttpMethod(pathItem.Content[j].Value) {
+				continue
+			}
+			security := mappingValue(pathItem.Content[j+1], "security")
+			if security == nil {
+				continue
+			}
+			for _, requirement := range security.Content {
+				for k := 0; k+1 < len(requirement.Content); k += 2 {
+					if newName, found := renames[requirement.Content[k].Value]; found {
+						requirement.Content[k].Value = newName
+					}
+				}
+			}
+		}
+	}
+}
+
+// serverOrigin returns the scheme and host of the first server of a specification, if absolute.
+func serverOrigin(specification *OAS3Specification) string {
+	if len(specification.Servers) == 0 {
+		return ""
+	}
+	serverUrl := specification.Servers[0].Url
+	i := strings.Index(serverUrl, "://")
+	if i < 0 {
+		return ""
+	}
+	if j := strings.Index(serverUrl[i+3:], "/"); j >= 0 {
+		return serverUrl[:i+3+j]
+	}
+	return serverUrl
+}
+
+func majorMinor(version string) string {
+	parts := strings.SplitN(version, ".", 3)
+	if len(parts) < 2 {
+		return version
+	}
+	return parts[0] + "." + parts[1]
+}
+
+func isHttpMethod(key string) bool {
+	for _, method := range HttpMethods {
+		if key == method {
+			return true
+		}
+	}
+	return false
+}
+
+func nodesEqual(a *yaml.Node, b *yaml.Node) bool {
+	var aValue, bValue interface{}
+	if resolveAlias(a).Decode(&aValue) != nil || resolveAlias(b).Decode(&bValue) != nil {
+		return false
+	}
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+func newMappingNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+func newSequenceNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+}
+
+func newScalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// setMappingValue sets the value of a key in a mapping node, appending the key if missing.
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, newScalarNode(key), value)
+}
@@ -0,0 +1,217 @@
+package oas
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const mergeTestPets = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/pets/v1
+security:
+  - apiKey: []
+paths:
+  /pets:
+    get:
+      operationId: list
+      responses:
+        "200":
+          description: Pets
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+  /pets/{id}:
+    get:
+      operationId: getPet
+      security: []
+      responses:
+        default:
+          description: Error
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: header
+      name: X-Key
+  schemas:
+    Pet:
+      type: object
+      properties:
+        tag:
+          $ref: '#/components/schemas/Tag'
+    Tag:
+      type: string
+    Error:
+      type: object
+`
+
+const mergeTestOrders = `openapi: 3.0.1
+info:
+  title: Orders
+  version: 2.0.0
+servers:
+  - url: https://api.example.com/orders
+security:
+  - apiKey: []
+paths:
+  /orders:
+    get:
+      operationId: list
+      responses:
+        "200":
+          description: Pets of the orders
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+        default:
+          description: Error
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+components:
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: query
+      name: key
+  schemas:
+    Pet:
+      type: object
+      properties:
+        tag:
+          $ref: '#/components/schemas/Tag'
+    Tag:
+      type: integer
+    Error:
+      type: object
+`
+
+// lookupTestValue returns the value at a path of keys within a decoded document.
+func lookupTestValue(value interface{}, keys ...string) interface{} {
+	for _, key := range keys {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[key]
+	}
+	return value
+}
+
+func TestMerge(t *testing.T) {
+	directory := t.TempDir()
+	writeTestFile(t, filepath.Join(directory, "pets.yaml"), mergeTestPets)
+	writeTestFile(t, filepath.Join(directory, "orders.yaml"), mergeTestOrders)
+
+	opts := NewMergeOpts()
+	opts.Repository = directory
+	opts.Sources = []string{filepath.Join(directory, "pets.yaml"), filepath.Join(directory, "orders.yaml") + "=/shop/"}
+	opts.OutputFile = filepath.Join(directory, "merged.yaml")
+	if err := Merge(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	content, err := ioutil.ReadFile(opts.OutputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name     string
+		keys     []string
+		expected interface{}
+	}{
+		{"prefix from the server base path", []string{"paths", "/pets/v1/pets", "get", "operationId"}, "list"},
+		{"explicit prefix", []string{"paths", "/shop/orders", "get", "operationId"}, "orders_list"},
+		{"renamed component", []string{"components", "schemas", "orders.Tag", "type"}, "integer"},
+		{"component kept", []string{"components", "schemas", "Tag", "type"}, "string"},
+		{"reference to a renamed component", []string{"components", "schemas", "orders.Pet", "properties", "tag", "$ref"}, "#/components/schemas/orders.Tag"},
+		{"reference to a component renamed transitively", []string{"paths", "/shop/orders", "get", "responses", "200", "content", "application/json", "schema", "$ref"}, "#/components/schemas/orders.Pet"},
+		{"reference to a deduplicated component", []string{"paths", "/shop/orders", "get", "responses", "default", "content", "application/json", "schema", "$ref"}, "#/components/schemas/Error"},
+		{"deduplicated component", []string{"components", "schemas", "orders.Error"}, nil},
+		{"renamed security scheme", []string{"components", "securitySchemes", "orders.apiKey", "in"}, "query"},
+		{"security pushed down", []string{"paths", "/pets/v1/pets", "get", "security"}, []interface{}{map[string]interface{}{"apiKey": []interface{}{}}}},
+		{"operation security kept", []string{"paths", "/pets/v1/pets/{id}", "get", "security"}, []interface{}{}},
+		{"security pushed down and renamed", []string{"paths", "/shop/orders", "get", "security"}, []interface{}{map[string]interface{}{"orders.apiKey": []interface{}{}}}},
+		{"no root security", []string{"security"}, nil},
+		{"server origin", []string{"servers"}, []interface{}{map[string]interface{}{"url": "https://api.example.com"}}},
+		{"openapi version of the first source", []string{"openapi"}, "3.0.3"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if value := lookupTestValue(document, c.keys...); !reflect.DeepEqual(value, c.expected) {
+				t.Errorf("got %#v at %s, want %#v", value, strings.Join(c.keys, "."), c.expected)
+			}
+		})
+	}
+}
+
+func TestMergeSpecificationsConflicts(t *testing.T) {
+	cases := []struct {
+		name       string
+		second     string
+		prefix     string
+		unresolved bool
+	}{
+		{"colliding operation", mergeTestPets, "/pets/v1", true},
+		{"other prefix", mergeTestPets, "/other", false},
+		{"other OpenAPI version", strings.Replace(mergeTestPets, "3.0.3", "3.1.0", 1), "/other", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			directory := t.TempDir()
+			writeTestFile(t, filepath.Join(directory, "first.yaml"), mergeTestPets)
+			writeTestFile(t, filepath.Join(directory, "second.yaml"), c.second)
+			first, err := LoadMergeSource(nil, filepath.Join(directory, "first.yaml"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			second, err := LoadMergeSource(nil, filepath.Join(directory, "second.yaml")+"="+c.prefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+			second.Name = "second"
+
+			_, conflicts := MergeSpecifications(NewMergeOpts(), []*MergeSource{first, second})
+			unresolved := false
+			for _, conflict := range conflicts {
+				unresolved = unresolved || !conflict.Resolved
+			}
+			if unresolved != c.unresolved {
+				t.Errorf("got conflicts %v, want unresolved <%t>", conflicts, c.unresolved)
+			}
+		})
+	}
+}
+
+func TestMergeCollidingPaths(t *testing.T) {
+	directory := t.TempDir()
+	writeTestFile(t, filepath.Join(directory, "pets.yaml"), mergeTestPets)
+	writeTestFile(t, filepath.Join(directory, "orders.yaml"), strings.Replace(mergeTestOrders, "/orders:", "/pets:", 1))
+
+	opts := NewMergeOpts()
+	opts.Repository = directory
+	opts.Sources = []string{filepath.Join(directory, "pets.yaml"), filepath.Join(directory, "orders.yaml") + "=/pets/v1"}
+	opts.OutputFile = filepath.Join(directory, "merged.yaml")
+	if err := Merge(opts); err == nil || !strings.Contains(err.Error(), "1 irreconcilable merge conflicts") {
+		t.Errorf("got error <%v>, want an irreconcilable conflict", err)
+	}
+}
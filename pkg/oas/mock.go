@@ -0,0 +1,227 @@
+package oas
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type MockOpts struct {
+	SpecificationFile string
+	Address           string
+
+	// BasePath under which the operations are served. Defaults to the path of the first server URL.
+	BasePath string
+
+	// ValidateRequests rejects the requests not matching the parameters and request bodies.
+	ValidateRequests bool
+
+	// Seed of the random generator of the payloads not given as examples. The same seed always produces the same payloads.
+	Seed int64
+}
+
+func NewMockOpts() *MockOpts {
+	return &MockOpts{
+		Address:          "localhost:8080",
+		ValidateRequests: true,
+		Seed:             0,
+	}
+}
+
+// MockServer answers every operation of a specification with its examples or with generated payloads.
+//
+// Clients may select the response with the Prefer header, e.g. "Prefer: code=404" or "Prefer: example=empty".
+type MockServer struct {
+	specification *OAS3Specification
+	validator     *Validator
+	opts          *MockOpts
+}
+
+func NewMockServer(specification *OAS3Specification, opts *MockOpts) *MockServer {
+	validator := NewValidator(specification)
+	if opts.BasePath != "" {
+		validator.BasePath = strings.TrimSuffix(opts.BasePath, "/")
+	}
+
+	return &MockServer{
+		specification: specification,
+		validator:     validator,
+		opts:          opts,
+	}
+}
+
+// Mock starts a mock server for the specification file and blocks until it fails.
+func Mock(opts *MockOpts) error {
+	log.Infof("Starting mock server for specification: %s.", opts.SpecificationFile)
+
+	// Parse specification
+	oas3Source, err := ParseFile(opts.SpecificationFile)
+	if err != nil {
+		return err
+	}
+
+	// Start server
+	mockServer := NewMockServer(oas3Source.specification, opts)
+	// Startup lines go to stderr so that they are visible whatever the log level.
+	for _, operation := range oas3Source.specification.Operations() {
+		fmt.Fprintf(os.Stderr, "> %-7s %s%s\n", strings.ToUpper(operation.Method), mockServer.validator.BasePath, operation.Path)
+	}
+	fmt.Fprintf(os.Stderr, "Mock server listening on %s.\n", opts.Address)
+	return http.ListenAndServe(opts.Address, mockServer)
+}
+
+func (m *MockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	log.Debugf("Mock request: %s %s", r.Method, r.URL)
+
+	// Allow browsers to call the mock from any origin.
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+		w.Header().Set("Access-Control-Allow-Methods", "GET, PUT, POST, DELETE, OPTIONS, HEAD, PATCH")
+		w.Header().Set("Access-Control-Allow-Headers", r.Header.Get("Access-Control-Request-Headers"))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Find and validate operation
+	var operation *OAS3OperationRef
+	var err error
+	if m.opts.ValidateRequests {
+		operation, err = m.validator.ValidateRequest(r)
+	} else {
+		operation, _, err = m.validator.FindOperation(r)
+	}
+	if err == ErrOperationNotFound {
+		writeProblem(w, http.StatusNotFound, err.Error(), nil)
+		return
+	}
+	if validationErrors, ok := err.(ValidationErrors); ok {
+		log.Infof("Mock request %s %s rejected: %s", r.Method, r.URL, validationErrors)
+		writeProblem(w, http.StatusBadRequest, "request does not match the specification", validationErrors)
+		return
+	}
+	if err != nil {
+		writeProblem(w, http.StatusInternalServerError, err.Error(), nil)
+		return
+	}
+
+	// Select response
+	preferences := parsePreferHeader(r.Header.Get("Prefer"))
+	code, response := m.selectResponse(operation.Operation, preferences["code"])
+	if response == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	status := statusOf(code)
+
+	// Fake missing examples, with a generator per request as fakers are not safe for concurrent use
+	faker := NewFaker(m.specification, m.opts.Seed)
+	faker.DeclaredExamples = true
+
+	// Headers
+	headerNames := make([]string, 0, len(response.Headers))
+	for name := range response.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		header := m.specification.ResolveHeader(response.Headers[name])
+		if header == nil || strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		value := header.Example
+		if value == nil && header.Schema != nil {
+			value = faker.Fake(header.Schema, ValidationDirectionResponse)
+		}
+		if value != nil {
+			w.Header().Set(name, fmt.Sprint(value))
+		}
+	}
+
+	// Body
+	mediaTypeName, mediaType := PreferredMediaType(response.Content)
+	if mediaType == nil {
+		w.WriteHeader(status)
+		return
+	}
+	body := faker.MediaTypeExample(mediaType, preferences["example"], ValidationDirectionResponse)
+	w.Header().Set("Content-Type", mediaTypeName)
+	w.WriteHeader(status)
+	if r.Method == http.MethodHead {
+		return
+	}
+	if text, ok := body.(string); ok && !IsJsonMediaType(mediaTypeName) {
+		w.Write([]byte(text))
+		return
+	}
+	json.NewEncoder(w).Encode(body)
+}
+
+// selectResponse returns the preferred response code if documented, otherwise the first success response.
+func (m *MockServer) selectResponse(operation *OAS3Operation, preferredCode string) (string, *OAS3Response) {
+	if preferredCode != "" {
+		if response := m.specification.ResolveResponse(operation.Responses[preferredCode]); response != nil {
+			return preferredCode, response
+		}
+	}
+	for _, code := range SortedResponseCodes(operation) {
+		if response := m.specification.ResolveResponse(operation.Responses[code]); response != nil {
+			return code, response
+		}
+	}
+	return "", nil
+}
+
+// MediaTypeExample returns the named example of a media type, or its first example in alphabetical
+// order, or a value faked from its schema.
+func (f *Faker) MediaTypeExample(mediaType *OAS3MediaType, name string, direction ValidationDirection) interface{} {
+	if name != "" {
+		if example := f.specification.ResolveExample(mediaType.Examples[name]); example != nil {
+			return normalizeGenericValue(example.Value)
+		}
+	}
+	if mediaType.Example != nil {
+		return normalizeGenericValue(mediaType.Example)
+	}
+
+	names := make([]string, 0, len(mediaType.Examples))
+	for exampleName := range mediaType.Examples {
+		names = append(names, exampleName)
+	}
+	sort.Strings(names)
+	for _, exampleName := range names {
+		if example := f.specification.ResolveExample(mediaType.Examples[exampleName]); example != nil && example.Value != nil {
+			return normalizeGenericValue(example.Value)
+		}
+	}
+
+	return f.Fake(mediaType.Schema, direction)
+}
+
+// statusOf converts a response code (e.g. "200", "4XX", "default") into an HTTP status.
+func statusOf(code string) int {
+	if status, err := strconv.Atoi(code); err == nil {
+		return status
+	}
+	if len(code) == 3 && strings.HasSuffix(strings.ToUpper(code), "XX") {
+		return int(code[0]-'0') * 100
+	}
+	return http.StatusOK
+}
+
+// parsePreferHeader parses a Prefer header such as "code=404, example=empty".
+func parsePreferHeader(value string) map[string]string {
+	preferences := make(map[string]string)
+	for _, preference := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ';' }) {
+		parts := strings.SplitN(strings.TrimSpace(preference), "=", 2)
+		if len(parts) == 2 {
+			preferences[strings.ToLower(parts[0])] = strings.Trim(parts[1], `"`)
+		}
+	}
+	return preferences
+}
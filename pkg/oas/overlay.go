@@ -0,0 +1,396 @@
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Overlay is an OpenAPI Overlay 1.0 document: a list of actions updating or removing the nodes of a
+// specification selected by JSONPath expressions.
+type Overlay struct {
+	Overlay string `yaml:"overlay" json:"overlay"`
+
+	Info struct {
+		Title   string `yaml:"title" json:"title"`
+		Version string `yaml:"version" json:"version"`
+	} `yaml:"info" json:"info"`
+
+	// Extends is the URL of the document the overlay was written for. When indexing, an overlay
+	// extending a local document, by a relative reference or a file URL, only applies to it.
+	Extends string `yaml:"extends,omitempty" json:"extends,omitempty"`
+
+	// TargetPaths restricts the specifications the overlay applies to when indexing, as a path pattern
+	// relative to the indexed directory, e.g. "payments/*/openapi.yaml". It takes precedence over Extends.
+	TargetPaths string `yaml:"x-target-paths,omitempty" json:"x-target-paths,omitempty"`
+
+	Actions []OverlayAction `yaml:"actions" json:"actions"`
+
+	path string
+}
+
+type OverlayAction struct {
+	Target      string    `yaml:"target" json:"target"`
+	Description string    `yaml:"description,omitempty" json:"description,omitempty"`
+	Update      yaml.Node `yaml:"update,omitempty" json:"update,omitempty"`
+	Remove      bool      `yaml:"remove,omitempty" json:"remove,omitempty"`
+}
+
+type OverlayOpts struct {
+	SpecificationFile string
+	OverlayFiles      []string
+
+	// OutputFile receiving the result, "-" for stdout. Its extension selects the format, defaulting
+	// to the format of the specification.
+	OutputFile string
+}
+
+func NewOverlayOpts() *OverlayOpts {
+	return &OverlayOpts{
+		OverlayFiles: []string{},
+		OutputFile:   "-",
+	}
+}
+
+// ApplyOverlays applies overlay files in order to a specification file.
+func ApplyOverlays(opts *OverlayOpts) error {
+	log.Infof("Applying %d overlays to specification: %s.", len(opts.OverlayFiles), opts.SpecificationFile)
+
+	// Parse overlays
+	overlays, err := ParseOverlayFiles(opts.OverlayFiles)
+	if err != nil {
+		return err
+	}
+
+	// Read specification
+	content, err := ioutil.ReadFile(opts.SpecificationFile)
+	if err != nil {
+		return err
+	}
+
+	// Apply overlays
+	format := opts.SpecificationFile
+	if opts.OutputFile != "-" {
+		format = opts.OutputFile
+	}
+	content, err = applyOverlays(content, format, overlays)
+	if err != nil {
+		return err
+	}
+
+	// Write result
+	if opts.OutputFile == "-" {
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+	log.Debugf("Writing %s.", opts.OutputFile)
+	return ioutil.WriteFile(opts.OutputFile, content, 0644)
+}
+
+// ParseOverlayFiles parses overlay documents, in YAML or JSON.
+func ParseOverlayFiles(paths []string) ([]*Overlay, error) {
+	overlays := []*Overlay{}
+	for _, overlayPath := range paths {
+		log.Debugf("Parse overlay <%s>.", overlayPath)
+		content, err := ioutil.ReadFile(overlayPath)
+		if err != nil {
+			return nil, err
+		}
+
+		overlay := &Overlay{path: overlayPath}
+		err = yaml.Unmarshal(content, overlay)
+		if err != nil {
+			return nil, fmt.Errorf("overlay <%s>: %v", overlayPath, err)
+		}
+		if !strings.HasPrefix(overlay.Overlay, "1.") {
+			return nil, fmt.Errorf("overlay <%s>: unsupported overlay version <%s>", overlayPath, overlay.Overlay)
+		}
+		if _, err := path.Match(overlay.TargetPaths, ""); err != nil {
+			return nil, fmt.Errorf("overlay <%s>: invalid x-target-paths <%s>: %v", overlayPath, overlay.TargetPaths, err)
+		}
+		for i, action := range overlay.Actions {
+			if action.Update.Kind == 0 && !action.Remove {
+				return nil, fmt.Errorf("overlay <%s>: action %d has neither update nor remove", overlayPath, i)
+			}
+		}
+		overlays = append(overlays, overlay)
+	}
+	return overlays, nil
+}
+
+// Extended tells whether the overlay applies to a specification, given its path and its path relative
+// to the indexed directory. Overlays extending a remote document apply to every specification.
+func (o *Overlay) Extended(specificationPath string, relativePath string) bool {
+	if o.TargetPaths != "" {
+		matched, err := path.Match(o.TargetPaths, filepath.ToSlash(relativePath))
+		return err == nil && matched
+	}
+
+	extendedPath, local := o.extendedPath()
+	if !local {
+		return true
+	}
+	absoluteExtendedPath, err := filepath.Abs(extendedPath)
+	if err != nil {
+		return false
+	}
+	absoluteSpecificationPath, err := filepath.Abs(specificationPath)
+	return err == nil && absoluteExtendedPath == absoluteSpecificationPath
+}
+
+// extendedPath returns the path of the document extended by the overlay, resolving relative references
+// against the location of the overlay, and false if the overlay does not extend a local document.
+func (o *Overlay) extendedPath() (string, bool) {
+	if o.Extends == "" {
+		return "", false
+	}
+	extendsUrl, err := url.Parse(o.Extends)
+	if err != nil {
+		return "", false
+	}
+	switch {
+	case extendsUrl.Scheme == "file":
+		return filepath.FromSlash(extendsUrl.Path), true
+	case extendsUrl.Scheme == "" && extendsUrl.Host == "" && path.IsAbs(extendsUrl.Path):
+		return filepath.FromSlash(extendsUrl.Path), true
+	case extendsUrl.Scheme == "" && extendsUrl.Host == "":
+		return filepath.Join(filepath.Dir(o.path), filepath.FromSlash(extendsUrl.Path)), true
+	default:
+		return "", false
+	}
+}
+
+// Apply runs the actions of the overlay, in order, on a document.
+func (o *Overlay) Apply(document *yaml.Node) error {
+	for i, action := range o.Actions {
+		target, err := CompileJsonPath(action.Target)
+		if err != nil {
+			return fmt.Errorf("overlay <%s>: action %d: %v", o.path, i, err)
+		}
+
+		matches := target.selectMatches(document)
+		if len(matches) == 0 {
+			log.Warnf("Overlay <%s>: target %s of action %d matches no node.", o.path, action.Target, i)
+			continue
+		}
+		for _, match := range matches {
+			if action.Remove {
+				removeNode(match.parent, match.node)
+			} else {
+				mergeNode(match.node, &action.Update)
+			}
+		}
+	}
+	return nil
+}
+
+// applyOverlays applies overlays to the content of a specification, whose format is given by the
+// extension of the path.
+func applyOverlays(content []byte, format string, overlays []*Overlay) ([]byte, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, overlay := range overlays {
+		err = overlay.Apply(&document)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return marshallNode(&document, format)
+}
+
+// marshallNode marshalls a document in JSON or YAML, depending on the extension of the path.
+func marshallNode(document *yaml.Node, format string) ([]byte, error) {
+	if filepath.Ext(format) == ".json" {
+		return marshallNodeJson(document)
+	}
+	buffer := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(buffer)
+	encoder.SetIndent(2)
+	err := encoder.Encode(document)
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), encoder.Close()
+}
+
+// mergeNode merges an update into a node: objects are merged recursively, values are appended to
+// arrays, and other values are replaced.
+func mergeNode(target *yaml.Node, update *yaml.Node) {
+	target = resolveAlias(target)
+	if update.Kind == yaml.DocumentNode && len(update.Content) > 0 {
+		update = update.Content[0]
+	}
+
+	switch {
+	case target.Kind == yaml.MappingNode && update.Kind == yaml.MappingNode:
+		for i := 0; i+1 < len(update.Content); i += 2 {
+			key, value := update.Content[i], update.Content[i+1]
+			existing := mappingValue(target, key.Value)
+			if existing == nil {
+				target.Content = append(target.Content, cloneNode(key), cloneNode(value))
+			} else if (existing.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode) || (existing.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode) {
+				mergeNode(existing, value)
+			} else {
+				*existing = *cloneNode(value)
+			}
+		}
+	case target.Kind == yaml.SequenceNode && update.Kind == yaml.SequenceNode:
+		for _, item := range update.Content {
+			target.Content = append(target.Content, cloneNode(item))
+		}
+	case target.Kind == yaml.SequenceNode:
+		target.Content = append(target.Content, cloneNode(update))
+	default:
+		*target = *cloneNode(update)
+	}
+}
+
+// removeNode removes a node from its parent mapping or sequence.
+func removeNode(parent *yaml.Node, node *yaml.Node) {
+	parent = resolveAlias(parent)
+	if parent == nil {
+		return
+	}
+	switch parent.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(parent.Content); i += 2 {
+			if parent.Content[i] == node {
+				parent.Content = append(parent.Content[:i-1], parent.Content[i+1:]...)
+				return
+			}
+		}
+	case yaml.SequenceNode:
+		for i, item := range parent.Content {
+			if item == node {
+				parent.Content = append(parent.Content[:i], parent.Content[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func cloneNode(node *yaml.Node) *yaml.Node {
+	node = resolveAlias(node)
+	clone := *node
+	clone.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		clone.Content[i] = cloneNode(child)
+	}
+	return &clone
+}
+
+// marshallNodeJson marshalls a YAML node into indented JSON, preserving the order of the keys.
+func marshallNodeJson(node *yaml.Node) ([]byte, error) {
+	compact := &bytes.Buffer{}
+	err := writeNodeJson(compact, node)
+	if err != nil {
+		return nil, err
+	}
+
+	indented := &bytes.Buffer{}
+	err = json.Indent(indented, compact.Bytes(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	indented.WriteByte('\n')
+	return indented.Bytes(), nil
+}
+
+func writeNodeJson(buffer *bytes.Buffer, node *yaml.Node) error {
+	node = resolveAlias(node)
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			buffer.WriteString("null")
+			return nil
+		}
+		return writeNodeJson(buffer, node.Content[0])
+	case yaml.MappingNode:
+		buffer.WriteByte('{')
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			err := writeJsonValue(buffer, node.Content[i].Value)
+			if err != nil {
+				return err
+			}
+			buffer.WriteByte(':')
+			err = writeNodeJson(buffer, node.Content[i+1])
+			if err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte('}')
+	case yaml.SequenceNode:
+		buffer.WriteByte('[')
+		for i, item := range node.Content {
+			if i > 0 {
+				buffer.WriteByte(',')
+			}
+			err := writeNodeJson(buffer, item)
+			if err != nil {
+				return err
+			}
+		}
+		buffer.WriteByte(']')
+	default:
+		var value interface{}
+		err := node.Decode(&value)
+		if err != nil {
+			return err
+		}
+		return writeJsonValue(buffer, value)
+	}
+	return nil
+}
+
+func writeJsonValue(buffer *bytes.Buffer, value interface{}) error {
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	err := encoder.Encode(value)
+	if err != nil {
+		return err
+	}
+	buffer.Truncate(buffer.Len() - 1)
+	return nil
+}
+
+// parseOverlaidFile parses a specification after applying the overlays extending it.
+func parseOverlaidFile(specificationPath string, relativePath string, overlays []*Overlay) (*OAS3Source, error) {
+	selected := []*Overlay{}
+	for _, overlay := range overlays {
+		if overlay.Extended(specificationPath, relativePath) {
+			selected = append(selected, overlay)
+		}
+	}
+	content, err := ioutil.ReadFile(specificationPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(selected) == 0 {
+		return parseIndexedBytes(specificationPath, content)
+	}
+
+	log.Debugf("Apply %d overlays to <%s>.", len(selected), specificationPath)
+	content, err = applyOverlays(content, specificationPath, selected)
+	if err != nil {
+		return nil, fmt.Errorf("specification <%s>: %v", specificationPath, err)
+	}
+	return parseIndexedBytes(specificationPath, content)
+}
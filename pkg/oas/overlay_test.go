@@ -0,0 +1,131 @@
+package oas
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOverlayExtended(t *testing.T) {
+	directory := t.TempDir()
+	specificationPath := filepath.Join(directory, "payments", "v1", "openapi.yaml")
+	cases := []struct {
+		name     string
+		extends  string
+		targets  string
+		expected bool
+	}{
+		{"no target", "", "", true},
+		{"remote document", "https://apis.example.com/payments/openapi.yaml", "", true},
+		{"relative reference", "payments/v1/openapi.yaml", "", true},
+		{"relative reference to another document", "orders/v1/openapi.yaml", "", false},
+		{"file URL", "file://" + filepath.ToSlash(specificationPath), "", true},
+		{"target paths", "", "payments/*/openapi.yaml", true},
+		{"target paths of other documents", "", "orders/*/openapi.yaml", false},
+		{"target paths before extends", "orders/v1/openapi.yaml", "payments/*/openapi.yaml", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			overlay := &Overlay{Extends: c.extends, TargetPaths: c.targets, path: filepath.Join(directory, "overlay.yaml")}
+			if extended := overlay.Extended(specificationPath, filepath.Join("payments", "v1", "openapi.yaml")); extended != c.expected {
+				t.Errorf("got extended <%t>, want <%t>", extended, c.expected)
+			}
+		})
+	}
+}
+
+func TestParseOverlayFiles(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"valid", "overlay: 1.0.0\ninfo: {title: t, version: 1}\nextends: https://example.com/openapi.yaml\nactions:\n  - target: $.info\n    update: {x-team: a}\n", ""},
+		{"unsupported version", "overlay: 2.0.0\nactions: []\n", "unsupported overlay version"},
+		{"action without update", "overlay: 1.0.0\nactions:\n  - target: $.info\n", "neither update nor remove"},
+		{"invalid target paths", "overlay: 1.0.0\nx-target-paths: '[a'\nactions: []\n", "invalid x-target-paths"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			overlayPath := filepath.Join(t.TempDir(), "overlay.yaml")
+			writeTestFile(t, overlayPath, c.content)
+			overlays, err := ParseOverlayFiles([]string{overlayPath})
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Errorf("got error <%v>, want <%s>", err, c.err)
+				}
+				return
+			}
+			if err != nil || len(overlays) != 1 || overlays[0].Extends != "https://example.com/openapi.yaml" {
+				t.Errorf("got %v <%v>", overlays, err)
+			}
+		})
+	}
+}
+
+func TestApplyOverlays(t *testing.T) {
+	specification := "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: 1.0.0\ntags:\n  - name: pets\npaths:\n  /pets:\n    get:\n      operationId: listPets\n    delete:\n      operationId: deletePets\n"
+	content := "overlay: 1.0.0\nactions:\n" +
+		"  - target: $.info\n    update:\n      title: Pet Store\n      x-team: pets\n" +
+		"  - target: $.tags\n    update:\n      name: stores\n" +
+		"  - target: $.paths['/pets'].delete\n    remove: true\n" +
+		"  - target: $.missing\n    remove: true\n"
+	overlayPath := filepath.Join(t.TempDir(), "overlay.yaml")
+	writeTestFile(t, overlayPath, content)
+	overlays, err := ParseOverlayFiles([]string{overlayPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := applyOverlays([]byte(specification), "openapi.json", overlays)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(result, &document); err != nil {
+		t.Fatalf("invalid JSON <%s>: %v", result, err)
+	}
+	info := document["info"].(map[string]interface{})
+	if info["title"] != "Pet Store" || info["x-team"] != "pets" || info["version"] != "1.0.0" {
+		t.Errorf("got info %v", info)
+	}
+	if tags := document["tags"].([]interface{}); len(tags) != 2 {
+		t.Errorf("got tags %v", tags)
+	}
+	pets := document["paths"].(map[string]interface{})["/pets"].(map[string]interface{})
+	if _, found := pets["delete"]; found || pets["get"] == nil {
+		t.Errorf("got path item %v", pets)
+	}
+	if !strings.HasPrefix(string(result), "{\n  \"openapi\": \"3.0.3\",\n  \"info\"") {
+		t.Errorf("key order not preserved: %s", result)
+	}
+}
+
+func TestIndexOverlayTargets(t *testing.T) {
+	directory := t.TempDir()
+	writeTestFile(t, filepath.Join(directory, "payments", "openapi.yaml"), "openapi: 3.0.3\ninfo:\n  title: Payments\n  version: 1.0.0\npaths: {}\n")
+	writeTestFile(t, filepath.Join(directory, "orders", "openapi.yaml"), "openapi: 3.0.3\ninfo:\n  title: Orders\n  version: 1.0.0\npaths: {}\n")
+	overlaysDirectory := t.TempDir()
+	targetPathsOverlay := filepath.Join(overlaysDirectory, "payments.yaml")
+	writeTestFile(t, targetPathsOverlay, "overlay: 1.0.0\nextends: https://apis.example.com/payments/openapi.yaml\nx-target-paths: payments/*\nactions:\n  - target: $.info\n    update:\n      description: Payments overlay\n")
+	extendsOverlay := filepath.Join(directory, "orders", "overlay.yaml")
+	writeTestFile(t, extendsOverlay, "overlay: 1.0.0\nextends: ./openapi.yaml\nactions:\n  - target: $.info\n    update:\n      x-extra-info:\n        keywords: [orders]\n")
+
+	opts := NewIndexOpts()
+	opts.Directory = directory
+	opts.Formats = []string{"json"}
+	opts.Overlays = []string{targetPathsOverlay, extendsOverlay}
+	if err := Index(opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	index := readTestIndex(t, directory)
+	payments, orders := index.Entries["payments"][0], index.Entries["orders"][0]
+	if payments.Description != "Payments overlay" || len(payments.Keywords) != 0 {
+		t.Errorf("unexpected payments entry: %s %v", payments.Description, payments.Keywords)
+	}
+	if orders.Description != "" || len(orders.Keywords) != 1 {
+		t.Errorf("unexpected orders entry: %s %v", orders.Description, orders.Keywords)
+	}
+}
@@ -15,7 +15,17 @@ type OAS3Source struct {
 	specification *OAS3Specification
 }
 
+func (s *OAS3Source) Path() string {
+	return s.path
+}
+
+func (s *OAS3Source) Specification() *OAS3Specification {
+	return s.specification
+}
+
 type OAS3Specification struct {
+	OpenApi string `yaml:"openapi" json:"openapi"`
+
 	Info struct {
 		Title          string `yaml:"title" json:"title"`
 		Version        string `yaml:"version" json:"version"`
@@ -34,20 +44,36 @@ type OAS3Specification struct {
 		} `yaml:"license" json:"license"`
 
 		ExtraInfo struct {
-			BusinessCategory string   `yaml:"businessCategory" json:"businessCategory"`
-			Deprecated       bool     `yaml:"deprecated" json:"deprecated"`
-			DisplayName      string   `yaml:"displayName" json:"displayName"`
-			IconUrl          string   `yaml:"iconUrl" json:"iconUrl"`
-			Keywords         []string `yaml:"keywords" json:"keywords"`
-			LogoUrl          string   `yaml:"logoUrl" json:"logoUrl"`
-			LongDescription  string   `yaml:"longDescription" json:"longDescription"`
-			Starred          bool     `yaml:"starred" json:"starred"`
-			Tags             []string `yaml:"tags" json:"tags"`
-			ThumbnailUrl     string   `yaml:"thumbnailUrl" json:"thumbnailUrl"`
-			VcsGitRevision   string   `yaml:"vcsGitRevision" json:"vcsGitRevision"`
-			VcsGitUrl        string   `yaml:"vcsGitUrl" json:"vcsGitUrl"`
+			BusinessCategory string        `yaml:"businessCategory" json:"businessCategory"`
+			Deprecated       bool          `yaml:"deprecated" json:"deprecated"`
+			Description      LocalizedText `yaml:"description" json:"description"`
+			DisplayName      LocalizedText `yaml:"displayName" json:"displayName"`
+			IconUrl          string        `yaml:"iconUrl" json:"iconUrl"`
+			Id               string        `yaml:"id" json:"id"`
+			Keywords         []string      `yaml:"keywords" json:"keywords"`
+			Lifecycle        string        `yaml:"lifecycle" json:"lifecycle"`
+			LogoUrl          string        `yaml:"logoUrl" json:"logoUrl"`
+			LongDescription  LocalizedText `yaml:"longDescription" json:"longDescription"`
+			OnCall           string        `yaml:"onCall" json:"onCall"`
+			OwnerTeam        string        `yaml:"ownerTeam" json:"ownerTeam"`
+			ReplacedBy       string        `yaml:"replacedBy" json:"replacedBy"`
+			Starred          bool          `yaml:"starred" json:"starred"`
+			SunsetDate       string        `yaml:"sunsetDate" json:"sunsetDate"`
+			Tags             []string      `yaml:"tags" json:"tags"`
+			ThumbnailUrl     string        `yaml:"thumbnailUrl" json:"thumbnailUrl"`
+			VcsGitRevision   string        `yaml:"vcsGitRevision" json:"vcsGitRevision"`
+			VcsGitUrl        string        `yaml:"vcsGitUrl" json:"vcsGitUrl"`
 		} `yaml:"x-extra-info" json:"x-extra-info"`
+
+		// LocalizedExtraInfo holds the x-extra-info-<language> extensions by language.
+		LocalizedExtraInfo map[string]OAS3LocalizedExtraInfo `yaml:"-" json:"-"`
 	} `yaml:"info" json:"info"`
+
+	Servers    []OAS3Server             `yaml:"servers,omitempty" json:"servers,omitempty"`
+	Tags       []OAS3Tag                `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Paths      map[string]*OAS3PathItem `yaml:"paths,omitempty" json:"paths,omitempty"`
+	Components OAS3Components           `yaml:"components,omitempty" json:"components,omitempty"`
+	Security   []map[string][]string    `yaml:"security,omitempty" json:"security,omitempty"`
 }
 
 func ParseFile(path string) (*OAS3Source, error) {
@@ -60,17 +86,46 @@ func ParseFile(path string) (*OAS3Source, error) {
 		return nil, err
 	}
 
+	return ParseBytes(path, candidateFileBytes)
+}
+
+// ParseBytes parses the content of a specification, using the extension of its path to select the format.
+func ParseBytes(path string, candidateFileBytes []byte) (*OAS3Source, error) {
+	return parseBytes(path, candidateFileBytes, false)
+}
+
+// parseIndexedBytes parses a specification for indexing, which only requires its info: when the
+// rest of the document cannot be decoded, its paths and components are ignored with a warning.
+func parseIndexedBytes(path string, candidateFileBytes []byte) (*OAS3Source, error) {
+	return parseBytes(path, candidateFileBytes, true)
+}
+
+func parseBytes(path string, candidateFileBytes []byte, infoOnlyFallback bool) (*OAS3Source, error) {
 	// Unmarshall
+	var err error
 	var candidateFileSpecification OAS3Specification
 	if filepath.Ext(path) == ".json" {
 		// Use JSON loader
 		err = json.Unmarshal(candidateFileBytes, &candidateFileSpecification)
-		if err != nil {
-			return nil, err
-		}
 	} else if filepath.Ext(path) == ".yaml" || filepath.Ext(path) == ".yml" {
 		// Use YAML loader.
 		err = yaml.Unmarshal(candidateFileBytes, &candidateFileSpecification)
+	}
+	if err != nil {
+		if !infoOnlyFallback {
+			return nil, err
+		}
+		candidateFileSpecification = OAS3Specification{}
+		infoErr := parseInfo(candidateFileBytes, &candidateFileSpecification)
+		if infoErr != nil {
+			return nil, infoErr
+		}
+		log.Warnf("Specification <%s>: paths and components ignored, they cannot be decoded: %v", path, err)
+	}
+
+	// Read translated metadata
+	if filepath.Ext(path) == ".json" || filepath.Ext(path) == ".yaml" || filepath.Ext(path) == ".yml" {
+		candidateFileSpecification.Info.LocalizedExtraInfo, err = parseLocalizedExtraInfo(candidateFileBytes)
 		if err != nil {
 			return nil, err
 		}
@@ -81,3 +136,21 @@ func ParseFile(path string) (*OAS3Source, error) {
 		specification: &candidateFileSpecification,
 	}, nil
 }
+
+// parseInfo decodes the version and the info object of a specification only. JSON documents are
+// read as YAML, of which they are a subset.
+func parseInfo(content []byte, specification *OAS3Specification) error {
+	var document struct {
+		OpenApi string    `yaml:"openapi"`
+		Info    yaml.Node `yaml:"info"`
+	}
+	err := yaml.Unmarshal(content, &document)
+	if err != nil {
+		return err
+	}
+	specification.OpenApi = document.OpenApi
+	if document.Info.Kind == 0 {
+		return nil
+	}
+	return document.Info.Decode(&specification.Info)
+}
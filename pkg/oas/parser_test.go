@@ -0,0 +1,62 @@
+package oas
+
+import (
+	"testing"
+)
+
+func TestParseBytes(t *testing.T) {
+	cases := []struct {
+		name    string
+		path    string
+		content string
+		err     bool
+		indexed bool
+		paths   int
+	}{
+		{
+			name:    "openapi 3.1 yaml",
+			path:    "openapi.yaml",
+			content: "openapi: 3.1.0\ninfo:\n  title: Modern\n  version: 1.0.0\npaths:\n  /things:\n    get:\n      responses:\n        '200':\n          description: ok\n          content:\n            application/json:\n              schema:\n                type: [string, 'null']\n                exclusiveMinimum: 3\n",
+			indexed: true,
+			paths:   1,
+		},
+		{
+			name:    "openapi 3.1 json",
+			path:    "openapi.json",
+			content: `{"openapi":"3.1.0","info":{"title":"Modern","version":"1.0.0"},"components":{"schemas":{"A":{"type":["integer","null"],"exclusiveMaximum":10}}}}`,
+			indexed: true,
+		},
+		{
+			name:    "undecodable paths",
+			path:    "openapi.yaml",
+			content: "openapi: 3.1.0\ninfo:\n  title: Broken\n  version: 1.0.0\npaths: [1, 2]\n",
+			err:     true,
+			indexed: true,
+		},
+		{
+			name:    "undecodable info",
+			path:    "openapi.yaml",
+			content: "openapi: 3.1.0\ninfo: [1, 2]\n",
+			err:     true,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			source, err := ParseBytes(c.path, []byte(c.content))
+			if (err != nil) != c.err {
+				t.Fatalf("got error <%v>, want error <%t>", err, c.err)
+			}
+			if err == nil && len(source.Specification().Paths) != c.paths {
+				t.Errorf("got %d paths, want %d", len(source.Specification().Paths), c.paths)
+			}
+
+			source, err = parseIndexedBytes(c.path, []byte(c.content))
+			if (err == nil) != c.indexed {
+				t.Fatalf("got indexing error <%v>, want success <%t>", err, c.indexed)
+			}
+			if err == nil && (source.Specification().Info.Title == "" || source.Specification().Info.Version != "1.0.0") {
+				t.Errorf("unexpected info: %+v", source.Specification().Info)
+			}
+		})
+	}
+}
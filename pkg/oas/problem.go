@@ -0,0 +1,27 @@
+package oas
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// writeProblem writes an application/problem+json response, listing the validation errors if any.
+// It is shared by the mock, repository and federation servers.
+func writeProblem(w http.ResponseWriter, status int, detail string, errs ValidationErrors) {
+	problem := struct {
+		Status int              `json:"status"`
+		Title  string           `json:"title"`
+		Detail string           `json:"detail"`
+		Errors ValidationErrors `json:"errors,omitempty"`
+	}{
+		Status: status,
+		Title:  http.StatusText(status),
+		Detail: detail,
+		Errors: errs,
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(problem)
+}
@@ -0,0 +1,134 @@
+package oas
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+// Name of the specification files in the conventional repository layout.
+const layoutFileName = "openapi"
+
+// LayoutPath returns the conventional slash-separated path of a specification in a repository:
+// <name>/<version>/openapi.yaml, or openapi.json for JSON documents.
+func LayoutPath(name string, version string, extension string) string {
+	if strings.ToLower(extension) == ".json" {
+		return path.Join(name, version, layoutFileName+".json")
+	}
+	return path.Join(name, version, layoutFileName+".yaml")
+}
+
+type PublishOpts struct {
+	// Specification file to publish.
+	Specification string
+
+	// Index holds the options used to re-index the repository, whose directory is the repository
+	// receiving the specification.
+	Index *IndexOpts
+}
+
+func NewPublishOpts() *PublishOpts {
+	return &PublishOpts{
+		Specification: "",
+		Index:         NewIndexOpts(),
+	}
+}
+
+// Publish validates a specification, copies it into the repository at its conventional location and
+// re-indexes the repository. Released versions are immutable: publishing a version already in the
+// repository fails, unless it is a pre-release. The copy is undone when re-indexing fails.
+func Publish(opts *PublishOpts) error {
+	log.Infof("Publishing specification <%s> to repository <%s>.", opts.Specification, opts.Index.Directory)
+
+	// Validate specification
+	oas3Source, err := ParseFile(opts.Specification)
+	if err != nil {
+		return fmt.Errorf("specification <%s>: %v", opts.Specification, err)
+	}
+	name, version, err := publicationCoordinates(oas3Source)
+	if err != nil {
+		return err
+	}
+	_, err = validateLifecycle(oas3Source)
+	if err != nil {
+		return err
+	}
+	content, err := ioutil.ReadFile(opts.Specification)
+	if err != nil {
+		return err
+	}
+
+	// Check released versions
+	target := filepath.Join(opts.Index.Directory, filepath.FromSlash(LayoutPath(name, version, filepath.Ext(opts.Specification))))
+	released := isReleasedVersion(version)
+	repository, err := OpenRepositoryIfExists(opts.Index.Directory)
+	if err != nil {
+		return err
+	}
+	if repository != nil && released {
+		for _, reference := range []string{name, oas3Source.specification.Info.Title} {
+			id, _ := repository.Index.GetSpecificationEntries(reference)
+			if repository.Index.FindSpecificationEntry(id, version) != nil {
+				return fmt.Errorf("version <%s> of specification <%s> is already published: released versions cannot be overwritten", version, name)
+			}
+		}
+	}
+	previousContent, err := ioutil.ReadFile(target)
+	if err == nil && released {
+		return fmt.Errorf("version <%s> of specification <%s> is already published at <%s>: released versions cannot be overwritten", version, name, target)
+	}
+
+	// Copy specification
+	log.Debugf("Copy specification to <%s>.", target)
+	err = os.MkdirAll(filepath.Dir(target), 0755)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(target, content, 0644)
+	if err != nil {
+		return err
+	}
+
+	// Re-index repository
+	err = Index(opts.Index)
+	if err != nil {
+		log.Debugf("Re-indexing failed, undo the copy of <%s>.", target)
+		if previousContent != nil {
+			ioutil.WriteFile(target, previousContent, 0644)
+		} else {
+			os.Remove(target)
+			os.Remove(filepath.Dir(target))
+			os.Remove(filepath.Dir(filepath.Dir(target)))
+		}
+		return fmt.Errorf("specification <%s> not published: %v", opts.Specification, err)
+	}
+
+	log.Infof("Specification <%s> published as version <%s> of <%s>.", opts.Specification, version, name)
+	return nil
+}
+
+// publicationCoordinates returns the name of a specification in the repository layout, its
+// x-extra-info.id or else its slugified title, and its semantic version.
+func publicationCoordinates(oas3Source *OAS3Source) (string, string, error) {
+	name, err := extraInfoId(oas3Source)
+	if err != nil {
+		return "", "", err
+	}
+	version, err := normalizeVersion(oas3Source.path, oas3Source.specification.Info.Version, VersionPolicyReject)
+	if err != nil {
+		return "", "", err
+	}
+	return name, version, nil
+}
+
+// isReleasedVersion tells whether a semantic version is a release rather than a pre-release.
+func isReleasedVersion(version string) bool {
+	parsedVersion, err := semver.NewVersion(version)
+	return err != nil || parsedVersion.Prerelease() == ""
+}
@@ -0,0 +1,106 @@
+package oas
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLayoutPath(t *testing.T) {
+	cases := []struct {
+		extension string
+		expected  string
+	}{
+		{".yaml", "petstore/1.0.0/openapi.yaml"},
+		{".yml", "petstore/1.0.0/openapi.yaml"},
+		{".JSON", "petstore/1.0.0/openapi.json"},
+	}
+	for _, c := range cases {
+		if layoutPath := LayoutPath("petstore", "1.0.0", c.extension); layoutPath != c.expected {
+			t.Errorf("%s: got <%s>, want <%s>", c.extension, layoutPath, c.expected)
+		}
+	}
+}
+
+func TestIsReleasedVersion(t *testing.T) {
+	for version, released := range map[string]bool{
+		"1.0.0":        true,
+		"1.0.0-beta.1": false,
+		"2.0.0-rc1":    false,
+		"invalid":      true,
+	} {
+		if isReleasedVersion(version) != released {
+			t.Errorf("<%s>: want released <%t>", version, released)
+		}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	directory := t.TempDir()
+	specifications := t.TempDir()
+	publish := func(name string, content string) error {
+		specificationPath := filepath.Join(specifications, name)
+		writeTestFile(t, specificationPath, content)
+		opts := NewPublishOpts()
+		opts.Specification = specificationPath
+		opts.Index.Directory = directory
+		opts.Index.Formats = []string{"json"}
+		return Publish(opts)
+	}
+
+	cases := []struct {
+		name     string
+		file     string
+		content  string
+		err      string
+		expected string
+	}{
+		{"release", "a.yaml", "openapi: 3.0.3\ninfo:\n  title: Pet Store\n  version: v1.0\npaths: {}\n", "", "pet-store/1.0.0/openapi.yaml"},
+		{"released version overwritten", "b.yaml", "openapi: 3.0.3\ninfo:\n  title: Pet Store\n  version: 1.0.0\n  description: changed\npaths: {}\n", "already published", ""},
+		{"released version overwritten by id", "c.yaml", "openapi: 3.0.3\ninfo:\n  title: Other\n  version: 1.0.0\n  x-extra-info:\n    id: pet-store\npaths: {}\n", "already published", ""},
+		{"pre-release", "d.json", `{"openapi":"3.0.3","info":{"title":"Pet Store","version":"2.0.0-beta.1"},"paths":{}}`, "", "pet-store/2.0.0-beta.1/openapi.json"},
+		{"pre-release overwritten", "e.json", `{"openapi":"3.0.3","info":{"title":"Pet Store","version":"2.0.0-beta.1","description":"fixed"},"paths":{}}`, "", "pet-store/2.0.0-beta.1/openapi.json"},
+		{"invalid version", "f.yaml", "openapi: 3.0.3\ninfo:\n  title: Pet Store\n  version: latest\npaths: {}\n", "not a valid semantic version", ""},
+		{"no identifier", "g.yaml", "openapi: 3.0.3\ninfo:\n  title: '+++'\n  version: 1.0.0\npaths: {}\n", "no identifier", ""},
+		{"invalid sunset date", "h.yaml", "openapi: 3.0.3\ninfo:\n  title: Orders\n  version: 1.0.0\n  x-extra-info:\n    sunsetDate: tomorrow\npaths: {}\n", "invalid sunset date", ""},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := publish(c.file, c.content)
+			if c.err != "" || c.expected == "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Fatalf("got error <%v>, want <%s>", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if _, err := os.Stat(filepath.Join(directory, filepath.FromSlash(c.expected))); err != nil {
+				t.Errorf("specification not published at <%s>", c.expected)
+			}
+			index := readTestIndex(t, directory)
+			if entry := index.FindSpecificationEntry("pet-store", strings.Split(c.expected, "/")[1]); entry == nil {
+				t.Errorf("entry not indexed: %v", index.Entries)
+			}
+		})
+	}
+
+	// Publications failing when re-indexing are undone.
+	writeTestFile(t, filepath.Join(directory, "broken.yaml"), "openapi: [")
+	if err := publish("i.yaml", "openapi: 3.0.3\ninfo:\n  title: Orders\n  version: 1.0.0\npaths: {}\n"); err == nil || !strings.Contains(err.Error(), "not published") {
+		t.Errorf("got error <%v>, want a re-indexing failure", err)
+	}
+	os.Remove(filepath.Join(directory, "broken.yaml"))
+
+	// Failed publications leave the repository untouched.
+	for _, name := range []string{"orders", "other"} {
+		if _, err := os.Stat(filepath.Join(directory, name)); !os.IsNotExist(err) {
+			t.Errorf("unexpected directory <%s> in the repository", name)
+		}
+	}
+	if entries := readTestIndex(t, directory).Entries["pet-store"]; len(entries) != 2 || entries[1].Description != "" || entries[0].Description != "fixed" {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
@@ -0,0 +1,224 @@
+package oas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	log "github.com/sirupsen/logrus"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Repository gives access to the specifications of an index produced by Index, located in a local
+// directory or behind an HTTP URL.
+type Repository struct {
+	// Location of the repository root: a directory or an http(s) URL.
+	Location string
+	Index    *V1_RepositoryIndex
+	Client   *http.Client
+
+	// IncludeYanked lets yanked versions match the latest version and version constraints. Exact
+	// versions always match.
+	IncludeYanked bool
+}
+
+// OpenRepository reads the index of a repository. The location is a directory, an http(s) URL, or
+// directly an index.json or index.yaml file or URL.
+func OpenRepository(location string) (*Repository, error) {
+	repository := &Repository{
+		Location: location,
+		Client:   &http.Client{Timeout: 30 * time.Second},
+	}
+
+	// Locate index
+	indexLocation := location
+	extension := strings.ToLower(filepath.Ext(location))
+	var indexUrl *url.URL
+	if isHttpLocation(location) {
+		// Index URLs may have a query string, e.g. holding a token.
+		parsed, err := url.Parse(location)
+		if err != nil {
+			return nil, err
+		}
+		indexUrl = parsed
+		extension = strings.ToLower(path.Ext(parsed.Path))
+	}
+	if extension == ".json" || extension == ".yaml" || extension == ".yml" {
+		if indexUrl != nil {
+			repository.Location = indexUrl.ResolveReference(&url.URL{Path: "./"}).String()
+		} else {
+			repository.Location = filepath.Dir(location)
+		}
+	} else {
+		indexLocation = strings.TrimSuffix(location, "/") + "/index.json"
+		extension = ".json"
+	}
+
+	// Read index
+	log.Debugf("Read repository index <%s>.", indexLocation)
+	content, err := repository.read(indexLocation)
+	if err != nil {
+		return nil, err
+	}
+	index := NewV1_RepositoryIndex()
+	if extension == ".json" {
+		err = json.Unmarshal(content, index)
+	} else {
+		err = yaml.Unmarshal(content, index)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("repository index <%s>: %v", indexLocation, err)
+	}
+	repository.Index = index
+	return repository, nil
+}
+
+// Resolve finds the entry designated by a reference such as "name", "name@1.2.0" or "name@^1.2".
+// The name is an identifier or an alias, and the version is exact or a semantic version constraint.
+// The latest matching version is returned, skipping yanked versions unless IncludeYanked is set.
+func (r *Repository) Resolve(reference string) (*V1_RepositoryIndexSpecificationEntry, error) {
+	name, version := reference, ""
+	if i := strings.LastIndex(reference, "@"); i > 0 {
+		name, version = reference[:i], reference[i+1:]
+	}
+
+	id, entries := r.Index.GetSpecificationEntries(name)
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("specification <%s> not found in repository <%s>", name, r.Location)
+	}
+	if version == "" || version == "latest" {
+		for i := range entries {
+			if r.IncludeYanked || entries[i].Yanked == nil {
+				return &entries[i], nil
+			}
+		}
+		return nil, fmt.Errorf("all versions of specification <%s> are yanked", name)
+	}
+	if entry := r.Index.FindSpecificationEntry(id, version); entry != nil {
+		if entry.Yanked != nil {
+			log.Warnf("Version <%s> of specification <%s> is yanked: %s", entry.Version, id, entry.Yanked.Reason)
+		}
+		return entry, nil
+	}
+
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return nil, fmt.Errorf("version <%s> of specification <%s> not found", version, name)
+	}
+	for i := range entries {
+		if entries[i].Yanked != nil && !r.IncludeYanked {
+			continue
+		}
+		entryVersion, err := parseEntryVersion(&entries[i])
+		if err == nil && constraint.Check(entryVersion) {
+			return &entries[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no version of specification <%s> matches <%s>", name, version)
+}
+
+// Fetch returns the content of the specification of an entry, and a path whose extension tells its format.
+func (r *Repository) Fetch(entry *V1_RepositoryIndexSpecificationEntry) ([]byte, string, error) {
+	// Local repositories read their own specifications from disk, even if absolute urls were produced when indexing.
+	if entry.Path != "" && entry.Source == nil && !isHttpLocation(r.Location) {
+		if validateArtifactName(entry.Path) != nil {
+			return nil, "", fmt.Errorf("specification <%s>: path <%s> is outside of the repository", entry.Id, entry.Path)
+		}
+		location := filepath.Join(r.Location, filepath.FromSlash(entry.Path))
+		content, err := r.read(location)
+		return content, location, err
+	}
+
+	location := entry.Url
+	if !isHttpLocation(location) {
+		// Urls are relative to the repository root unless absolute urls were produced when indexing.
+		if isHttpLocation(r.Location) {
+			base, err := url.Parse(strings.TrimSuffix(r.Location, "/") + "/")
+			if err != nil {
+				return nil, "", err
+			}
+			reference, err := url.Parse(strings.TrimPrefix(location, "/"))
+			if err != nil {
+				return nil, "", err
+			}
+			location = base.ResolveReference(reference).String()
+		} else {
+			location = filepath.Join(r.Location, filepath.FromSlash(localUrlPath(location)))
+		}
+	}
+	content, err := r.read(location)
+	return content, location, err
+}
+
+// Load resolves a reference and parses its specification. References which are existing files are
+// parsed directly, without looking up the index.
+func (r *Repository) Load(reference string) (*OAS3Source, []byte, error) {
+	location := reference
+	content, err := ioutil.ReadFile(reference)
+	if err != nil {
+		if r == nil || r.Index == nil {
+			return nil, nil, err
+		}
+		entry, err := r.Resolve(reference)
+		if err != nil {
+			return nil, nil, err
+		}
+		content, location, err = r.Fetch(entry)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	source, err := ParseBytes(location, content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("specification <%s>: %v", location, err)
+	}
+	return source, content, nil
+}
+
+func (r *Repository) read(location string) ([]byte, error) {
+	if !isHttpLocation(location) {
+		return ioutil.ReadFile(location)
+	}
+
+	log.Debugf("Fetch <%s>.", location)
+	res, err := r.Client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetching <%s> failed: %s", location, res.Status)
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
+func isHttpLocation(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// OpenRepositoryIfExists opens a repository when its location exists, and returns nil otherwise, so
+// that commands accepting references may also be given plain files.
+func OpenRepositoryIfExists(location string) (*Repository, error) {
+	if !isHttpLocation(location) {
+		info, err := os.Stat(location)
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err == nil && info.IsDir() {
+			if _, err := os.Stat(filepath.Join(location, "index.json")); os.IsNotExist(err) {
+				return nil, nil
+			}
+		}
+	}
+	return OpenRepository(location)
+}
@@ -0,0 +1,127 @@
+package oas
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRepositoryResolve(t *testing.T) {
+	index := NewV1_RepositoryIndex()
+	for _, version := range []string{"2.0.0", "1.2.0", "1.1.0", "1.0.0"} {
+		entry := NewV1_RepositoryIndexSpecificationEntry()
+		entry.Id, entry.Name, entry.Version = "pets", "Pet Store", version
+		if version == "2.0.0" || version == "1.1.0" {
+			entry.Yanked = &V1_RepositoryIndexYankEntry{Reason: "broken"}
+		}
+		index.AddSpecificationEntry(entry)
+	}
+	index.BuildAliases()
+
+	cases := []struct {
+		reference     string
+		includeYanked bool
+		expected      string
+		err           bool
+	}{
+		{"pets", false, "1.2.0", false},
+		{"pets@latest", true, "2.0.0", false},
+		{"Pet Store", false, "1.2.0", false},
+		{"pets@1.1.0", false, "1.1.0", false},
+		{"pets@~1.1", false, "", true},
+		{"pets@~1.1", true, "1.1.0", false},
+		{"pets@^1.0", false, "1.2.0", false},
+		{"pets@3.0.0", false, "", true},
+		{"orders", false, "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.reference, func(t *testing.T) {
+			repository := &Repository{Location: "repo", Index: index, IncludeYanked: c.includeYanked}
+			entry, err := repository.Resolve(c.reference)
+			if (err != nil) != c.err {
+				t.Fatalf("got error <%v>, want error <%t>", err, c.err)
+			}
+			if entry != nil && entry.Version != c.expected {
+				t.Errorf("got version <%s>, want <%s>", entry.Version, c.expected)
+			}
+		})
+	}
+}
+
+func TestRepositoryFetchLocalWithPublicUrl(t *testing.T) {
+	fetched := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = true
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	directory := t.TempDir()
+	specification := "openapi: 3.0.3\ninfo:\n  title: Pets\n  version: 1.0.0\npaths: {}\n"
+	writeTestFile(t, filepath.Join(directory, "pets", "openapi.yaml"), specification)
+	opts := NewIndexOpts()
+	opts.Directory = directory
+	opts.Url = server.URL + "/apis"
+	opts.Formats = []string{"json"}
+	if err := Index(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	repository, err := OpenRepository(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := repository.Resolve("pets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(entry.Url, server.URL) || entry.Path != "pets/openapi.yaml" {
+		t.Fatalf("unexpected entry url <%s> path <%s>", entry.Url, entry.Path)
+	}
+	content, location, err := repository.Fetch(entry)
+	if err != nil || string(content) != specification || location != filepath.Join(directory, "pets", "openapi.yaml") {
+		t.Errorf("got <%s> from <%s> <%v>", content, location, err)
+	}
+	if fetched {
+		t.Errorf("local specification fetched over HTTP")
+	}
+}
+
+func TestRepositoryFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repo/pets/openapi.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte("openapi: 3.0.3\n"))
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		name     string
+		location string
+		url      string
+		path     string
+		expected string
+		err      bool
+	}{
+		{"remote relative url", server.URL + "/repo", "pets/openapi.yaml", "pets/openapi.yaml", server.URL + "/repo/pets/openapi.yaml", false},
+		{"remote absolute url", server.URL + "/other", server.URL + "/repo/pets/openapi.yaml", "pets/openapi.yaml", server.URL + "/repo/pets/openapi.yaml", false},
+		{"local path escaping the repository", "repo", "pets/openapi.yaml", "../pets/openapi.yaml", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			repository := &Repository{Location: c.location, Index: NewV1_RepositoryIndex(), Client: server.Client()}
+			entry := &V1_RepositoryIndexSpecificationEntry{Id: "pets", Url: c.url, Path: c.path}
+			_, location, err := repository.Fetch(entry)
+			if (err != nil) != c.err {
+				t.Fatalf("got error <%v>, want error <%t>", err, c.err)
+			}
+			if !c.err && location != c.expected {
+				t.Errorf("got location <%s>, want <%s>", location, c.expected)
+			}
+		})
+	}
+}
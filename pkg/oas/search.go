@@ -0,0 +1,525 @@
+package oas
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Name of the search index published at the root of the repository.
+const searchIndexFile = "search.json"
+
+// searchField is a part of a specification contributing terms to the search index.
+type searchField struct {
+	name  string
+	boost float64
+	texts func(entry *V1_RepositoryIndexSpecificationEntry, specification *OAS3Specification) []string
+}
+
+// searchFields lists the indexed fields with their boost: a term found in the name weighs more than
+// a term found in the description.
+var searchFields = []searchField{
+	{"name", 5, func(e *V1_RepositoryIndexSpecificationEntry, _ *OAS3Specification) []string {
+		names := []string{e.Id, e.Name, e.DisplayName}
+		for _, translation := range e.Localized {
+			names = append(names, translation.DisplayName)
+		}
+		return names
+	}},
+	{"keywords", 3, func(e *V1_RepositoryIndexSpecificationEntry, _ *OAS3Specification) []string {
+		return append(append([]string{}, e.Keywords...), e.Tags...)
+	}},
+	{"operations", 2, func(_ *V1_RepositoryIndexSpecificationEntry, s *OAS3Specification) []string {
+		summaries := []string{}
+		if s != nil {
+			for _, operation := range s.Operations() {
+				summaries = append(summaries, operation.Operation.Summary)
+			}
+		}
+		return summaries
+	}},
+	{"description", 1, func(e *V1_RepositoryIndexSpecificationEntry, _ *OAS3Specification) []string {
+		descriptions := []string{e.Description, e.LongDescription}
+		for _, translation := range e.Localized {
+			descriptions = append(descriptions, translation.Description, translation.LongDescription)
+		}
+		return descriptions
+	}},
+}
+
+// searchStopWords are ignored when indexing and querying.
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"the": true, "this": true, "to": true, "with": true,
+}
+
+// SearchIndex is an inverted index of the latest version of each specification of a repository.
+type SearchIndex struct {
+	ApiVersion int              `json:"apiVersion"`
+	Documents  []SearchDocument `json:"documents"`
+
+	// Terms maps each stemmed term to the documents containing it.
+	Terms map[string][]SearchPosting `json:"terms"`
+}
+
+// SearchDocument is a specification which may be returned by a search.
+type SearchDocument struct {
+	Id          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+	Url         string `json:"url"`
+
+	// Localized holds the translations of the display name and description by language.
+	Localized map[string]V1_RepositoryIndexLocalizedEntry `json:"localized,omitempty"`
+}
+
+// Localize returns a copy of the document whose display name and description are translated in
+// the first of the languages having a translation, without the other translations.
+func (d SearchDocument) Localize(languages []string) SearchDocument {
+	for _, language := range languages {
+		if translation, found := d.Localized[language]; found {
+			if translation.DisplayName != "" {
+				d.DisplayName = translation.DisplayName
+			}
+			if translation.Description != "" {
+				d.Description = translation.Description
+			}
+			break
+		}
+	}
+	d.Localized = nil
+	return d
+}
+
+// SearchPosting tells the weight of a term in a document: its occurrences multiplied by the field boosts.
+type SearchPosting struct {
+	Document int     `json:"d"`
+	Weight   float64 `json:"w"`
+}
+
+// SearchResult is a document matching a query, with its relevance score.
+type SearchResult struct {
+	SearchDocument
+	Score float64 `json:"score"`
+}
+
+type SearchOpts struct {
+	// Repository containing the search index, or whose specifications are indexed on the fly.
+	Repository string
+	Query      string
+	Limit      int
+
+	// Languages in which results are shown when translated, by decreasing preference.
+	Languages []string
+
+	// Format of the results: text or json.
+	Format string
+	Writer io.Writer
+}
+
+func NewSearchOpts() *SearchOpts {
+	return &SearchOpts{
+		Repository: ".",
+		Limit:      10,
+		Languages:  []string{},
+		Format:     "text",
+		Writer:     os.Stdout,
+	}
+}
+
+// Search queries the search index of a repository and writes the best matching specifications.
+func Search(opts *SearchOpts) error {
+	if opts.Format != "text" && opts.Format != "json" {
+		return fmt.Errorf("unsupported search format <%s>", opts.Format)
+	}
+
+	// Load search index
+	repository, err := OpenRepository(opts.Repository)
+	if err != nil {
+		return err
+	}
+	searchIndex, err := repository.SearchIndex()
+	if err != nil {
+		return err
+	}
+
+	// Query
+	results := searchIndex.Search(opts.Query, opts.Limit)
+	for i := range results {
+		results[i].SearchDocument = results[i].Localize(opts.Languages)
+	}
+	log.Debugf("%d specifications match <%s>.", len(results), opts.Query)
+
+	// Write results
+	if opts.Format == "json" {
+		content, err := marshallCanonicalJson(results)
+		if err != nil {
+			return err
+		}
+		_, err = opts.Writer.Write(content)
+		return err
+	}
+	for _, result := range results {
+		fmt.Fprintf(opts.Writer, "%-8.2f %s@%s  %s\n", result.Score, result.Id, result.Version, result.Name)
+		if result.Description != "" {
+			fmt.Fprintf(opts.Writer, "         %s\n", result.Description)
+		}
+	}
+	return nil
+}
+
+// BuildSearchIndex indexes the latest version of each specification not yanked. Entries must be sorted, and
+// specifications are given by identifier, possibly missing.
+func BuildSearchIndex(index *V1_RepositoryIndex, specifications map[string]*OAS3Specification) *SearchIndex {
+	searchIndex := &SearchIndex{
+		ApiVersion: 1,
+		Documents:  []SearchDocument{},
+		Terms:      make(map[string][]SearchPosting),
+	}
+
+	ids := make([]string, 0, len(index.Entries))
+	for id, entries := range index.Entries {
+		if len(entries) > 0 {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	for document, id := range ids {
+		entry := latestEntry(index.Entries[id])
+		searchIndex.Documents = append(searchIndex.Documents, SearchDocument{
+			Id:          id,
+			Name:        entry.Name,
+			DisplayName: entry.DisplayName,
+			Version:     entry.Version,
+			Description: entry.Description,
+			Url:         entry.Url,
+			Localized:   localizedSearchEntries(entry.Localized),
+		})
+
+		weights := make(map[string]float64)
+		for _, field := range searchFields {
+			for _, text := range field.texts(entry, specifications[id]) {
+				for _, term := range searchTerms(text) {
+					weights[term] += field.boost
+				}
+			}
+		}
+		for term, weight := range weights {
+			searchIndex.Terms[term] = append(searchIndex.Terms[term], SearchPosting{Document: document, Weight: weight})
+		}
+	}
+	return searchIndex
+}
+
+// Search returns the documents matching at least one term of the query, the most relevant first.
+// Scores sum the weight of each term multiplied by its inverse document frequency.
+func (s *SearchIndex) Search(query string, limit int) []SearchResult {
+	scores := make(map[int]float64)
+	for _, term := range searchTerms(query) {
+		postings := s.Terms[term]
+		if len(postings) == 0 {
+			continue
+		}
+		idf := math.Log(1 + float64(len(s.Documents))/float64(len(postings)))
+		for _, posting := range postings {
+			scores[posting.Document] += posting.Weight * idf
+		}
+	}
+
+	results := []SearchResult{}
+	for document, score := range scores {
+		if document >= 0 && document < len(s.Documents) {
+			results = append(results, SearchResult{SearchDocument: s.Documents[document], Score: round2(score)})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Id < results[j].Id
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// SearchIndex reads the search index published with the repository index, or builds it from the
+// latest specifications when it was not published.
+func (r *Repository) SearchIndex() (*SearchIndex, error) {
+	location := strings.TrimSuffix(r.Location, "/") + "/" + searchIndexFile
+	if !isHttpLocation(r.Location) {
+		location = filepath.Join(r.Location, searchIndexFile)
+	}
+	content, err := r.read(location)
+	if err == nil {
+		searchIndex := &SearchIndex{}
+		err = json.Unmarshal(content, searchIndex)
+		if err != nil {
+			return nil, fmt.Errorf("search index <%s>: %v", location, err)
+		}
+		return searchIndex, nil
+	}
+
+	log.Debugf("No search index found at <%s>, indexing the latest specifications.", location)
+	specifications := make(map[string]*OAS3Specification)
+	for id, entries := range r.Index.Entries {
+		if len(entries) == 0 {
+			continue
+		}
+		content, path, err := r.Fetch(latestEntry(entries))
+		if err != nil {
+			return nil, err
+		}
+		source, err := ParseBytes(path, content)
+		if err != nil {
+			return nil, fmt.Errorf("specification <%s>: %v", path, err)
+		}
+		specifications[id] = source.specification
+	}
+	return BuildSearchIndex(r.Index, specifications), nil
+}
+
+// localizedSearchEntries keeps the translations shown in search results.
+func localizedSearchEntries(localized map[string]V1_RepositoryIndexLocalizedEntry) map[string]V1_RepositoryIndexLocalizedEntry {
+	if len(localized) == 0 {
+		return nil
+	}
+	searchLocalized := make(map[string]V1_RepositoryIndexLocalizedEntry, len(localized))
+	for language, translation := range localized {
+		searchLocalized[language] = V1_RepositoryIndexLocalizedEntry{DisplayName: translation.DisplayName, Description: translation.Description}
+	}
+	return searchLocalized
+}
+
+// buildSearchArtifact indexes the latest retained specification of each identifier. Entries must be sorted.
+func buildSearchArtifact(index *V1_RepositoryIndex, resolver *conflictResolver, sources map[string]*OAS3Source) ([]byte, error) {
+	specifications := make(map[string]*OAS3Specification)
+	for id, entries := range index.Entries {
+		if len(entries) == 0 {
+			continue
+		}
+		if source := sources[resolver.sources[id+"@"+entryVersionKey(latestEntry(entries))]]; source != nil {
+			specifications[id] = source.specification
+		}
+	}
+	return marshallCanonicalJson(BuildSearchIndex(index, specifications))
+}
+
+// searchTerms splits a text into lower-cased words, without stop words, and stems them.
+func searchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if len(word) < 2 || searchStopWords[word] {
+			continue
+		}
+		terms = append(terms, stem(word))
+	}
+	return terms
+}
+
+// stem reduces an English word to its stem with the first step of the Porter algorithm, which
+// handles plurals and -ed/-ing forms, e.g. "payments" and "ordered" to "payment" and "order".
+func stem(word string) string {
+	if len(word) <= 2 || strings.IndexFunc(word, func(r rune) bool { return r < 'a' || r > 'z' }) >= 0 {
+		return word
+	}
+
+	// Step 1a: plurals.
+	switch {
+	case strings.HasSuffix(word, "sses"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ies"):
+		word = word[:len(word)-2]
+	case strings.HasSuffix(word, "ss"):
+	case strings.HasSuffix(word, "s"):
+		word = word[:len(word)-1]
+	}
+
+	// Step 1b: past participles and gerunds.
+	cleanup := false
+	switch {
+	case strings.HasSuffix(word, "eed"):
+		if stemMeasure(word[:len(word)-3]) > 0 {
+			word = word[:len(word)-1]
+		}
+	case strings.HasSuffix(word, "ed") && stemHasVowel(word[:len(word)-2]):
+		word, cleanup = word[:len(word)-2], true
+	case strings.HasSuffix(word, "ing") && stemHasVowel(word[:len(word)-3]):
+		word, cleanup = word[:len(word)-3], true
+	}
+	if cleanup {
+		switch {
+		case strings.HasSuffix(word, "at") || strings.HasSuffix(word, "bl") || strings.HasSuffix(word, "iz"):
+			word += "e"
+		case len(word) >= 2 && word[len(word)-1] == word[len(word)-2] && stemConsonant(word, len(word)-1) && !strings.ContainsAny(word[len(word)-1:], "lsz"):
+			word = word[:len(word)-1]
+		case stemMeasure(word) == 1 && stemEndsCvc(word):
+			word += "e"
+		}
+	}
+
+	// Step 1c: terminal y.
+	if strings.HasSuffix(word, "y") && stemHasVowel(word[:len(word)-1]) {
+		word = word[:len(word)-1] + "i"
+	}
+	return word
+}
+
+// stemConsonant tells whether the letter at position i is a consonant in the sense of Porter.
+func stemConsonant(word string, i int) bool {
+	switch word[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !stemConsonant(word, i-1)
+	}
+	return true
+}
+
+// stemMeasure counts the vowel-consonant sequences of a word.
+func stemMeasure(word string) int {
+	measure, vowel := 0, false
+	for i := range word {
+		if stemConsonant(word, i) {
+			if vowel {
+				measure++
+			}
+			vowel = false
+		} else {
+			vowel = true
+		}
+	}
+	return measure
+}
+
+func stemHasVowel(word string) bool {
+	for i := range word {
+		if !stemConsonant(word, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// stemEndsCvc tells whether a word ends with consonant-vowel-consonant, the last one not being w, x or y.
+func stemEndsCvc(word string) bool {
+	n := len(word)
+	return n >= 3 && stemConsonant(word, n-3) && !stemConsonant(word, n-2) && stemConsonant(word, n-1) && !strings.ContainsAny(word[n-1:], "wxy")
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+type ServeOpts struct {
+	// Repository directory served over HTTP.
+	Repository string
+	Address    string
+}
+
+func NewServeOpts() *ServeOpts {
+	return &ServeOpts{
+		Repository: ".",
+		Address:    "localhost:8080",
+	}
+}
+
+// Serve exposes the files of a repository and its search index over HTTP, and blocks until it fails.
+// The index and the search results are translated according to the Accept-Language header.
+func Serve(opts *ServeOpts) error {
+	log.Infof("Serving repository: %s.", opts.Repository)
+
+	// Load search index
+	repository, err := OpenRepository(opts.Repository)
+	if err != nil {
+		return err
+	}
+	searchIndex, err := repository.SearchIndex()
+	if err != nil {
+		return err
+	}
+
+	// Start server
+	mux := http.NewServeMux()
+	mux.Handle("/search", NewSearchHandler(searchIndex))
+	mux.Handle("/index.json", NewLocalizedIndexHandler(repository.Index, "json"))
+	mux.Handle("/index.yaml", NewLocalizedIndexHandler(repository.Index, "yaml"))
+	mux.Handle("/", http.FileServer(http.Dir(opts.Repository)))
+	// The startup line goes to stderr so that it is visible whatever the log level.
+	fmt.Fprintf(os.Stderr, "Repository server listening on %s.\n", opts.Address)
+	return http.ListenAndServe(opts.Address, mux)
+}
+
+// NewSearchHandler answers GET /search?q=<query>[&limit=<n>] with the matching specifications in JSON.
+func NewSearchHandler(searchIndex *SearchIndex) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			writeProblem(w, http.StatusMethodNotAllowed, "only GET is supported", nil)
+			return
+		}
+
+		query := r.URL.Query().Get("q")
+		if strings.TrimSpace(query) == "" {
+			writeProblem(w, http.StatusBadRequest, "query parameter q is required", nil)
+			return
+		}
+		limit := 10
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed < 0 {
+				writeProblem(w, http.StatusBadRequest, "query parameter limit must be a positive integer", nil)
+				return
+			}
+			limit = parsed
+		}
+
+		log.Debugf("Search request: %s", query)
+		results := searchIndex.Search(query, limit)
+		languages := ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+		for i := range results {
+			results[i].SearchDocument = results[i].Localize(languages)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Vary", "Accept-Language")
+		w.WriteHeader(http.StatusOK)
+		encoder := json.NewEncoder(w)
+		encoder.SetEscapeHTML(false)
+		encoder.Encode(results)
+	})
+}
+
+// NewLocalizedIndexHandler answers with the index in a format, its entries translated according to
+// the Accept-Language header.
+func NewLocalizedIndexHandler(index *V1_RepositoryIndex, format string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		content, err := marshallIndexDocument(format, true, index.Localize(ParseAcceptLanguage(r.Header.Get("Accept-Language"))))
+		if err != nil {
+			writeProblem(w, http.StatusInternalServerError, err.Error(), nil)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeOf("index."+format))
+		w.Header().Set("Vary", "Accept-Language")
+		w.WriteHeader(http.StatusOK)
+		w.Write(content)
+	})
+}
@@ -0,0 +1,173 @@
+package oas
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func newTestSearchIndex(t *testing.T) *SearchIndex {
+	t.Helper()
+	index := NewV1_RepositoryIndex()
+	index.Entries["pets"] = []V1_RepositoryIndexSpecificationEntry{
+		{Id: "pets", Name: "Pets", Version: "2.0.0", Description: "Yanked pets", Yanked: &V1_RepositoryIndexYankEntry{Reason: "broken"}},
+		{Id: "pets", Name: "Pets", Version: "1.0.0", Description: "Manage the pets of a shop", Keywords: []string{"animals"}, Localized: map[string]V1_RepositoryIndexLocalizedEntry{
+			"fr": {DisplayName: "Animaux", Description: "Gérer les animaux", LongDescription: "Not shown"},
+		}},
+	}
+	index.Entries["orders"] = []V1_RepositoryIndexSpecificationEntry{
+		{Id: "orders", Name: "Orders", Version: "1.0.0", Description: "Order pets for customers"},
+	}
+	index.Entries["empty"] = []V1_RepositoryIndexSpecificationEntry{}
+
+	orders := &OAS3Specification{}
+	err := yaml.Unmarshal([]byte("openapi: 3.0.3\ninfo:\n  title: Orders\n  version: 1.0.0\npaths:\n  /orders:\n    post:\n      summary: Ordering payments\n      responses: {}\n"), orders)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return BuildSearchIndex(index, map[string]*OAS3Specification{"orders": orders})
+}
+
+func TestBuildSearchIndex(t *testing.T) {
+	searchIndex := newTestSearchIndex(t)
+	documents := []SearchDocument{
+		{Id: "orders", Name: "Orders", Version: "1.0.0", Description: "Order pets for customers"},
+		{Id: "pets", Name: "Pets", Version: "1.0.0", Description: "Manage the pets of a shop", Localized: map[string]V1_RepositoryIndexLocalizedEntry{
+			"fr": {DisplayName: "Animaux", Description: "Gérer les animaux"},
+		}},
+	}
+	if !reflect.DeepEqual(searchIndex.Documents, documents) {
+		t.Errorf("got documents %+v, want %+v", searchIndex.Documents, documents)
+	}
+
+	cases := []struct {
+		term     string
+		postings []SearchPosting
+	}{
+		{"order", []SearchPosting{{Document: 0, Weight: 13}}},
+		{"pet", []SearchPosting{{Document: 0, Weight: 1}, {Document: 1, Weight: 11}}},
+		{"animal", []SearchPosting{{Document: 1, Weight: 3}}},
+		{"payment", []SearchPosting{{Document: 0, Weight: 2}}},
+		{"the", nil},
+		{"yank", nil},
+	}
+	for _, c := range cases {
+		if postings := searchIndex.Terms[c.term]; !reflect.DeepEqual(postings, c.postings) {
+			t.Errorf("got postings %v for <%s>, want %v", postings, c.term, c.postings)
+		}
+	}
+}
+
+func TestSearchIndexSearch(t *testing.T) {
+	searchIndex := newTestSearchIndex(t)
+	cases := []struct {
+		query  string
+		limit  int
+		ids    []string
+		scores []float64
+	}{
+		{"pets", 0, []string{"pets", "orders"}, []float64{7.62, 0.69}},
+		{"pets", 1, []string{"pets"}, []float64{7.62}},
+		{"ordered", 0, []string{"orders"}, []float64{14.28}},
+		{"Animal shop", 0, []string{"pets"}, []float64{4.39}},
+		{"the yanked", 0, []string{}, []float64{}},
+		{"", 0, []string{}, []float64{}},
+	}
+	for _, c := range cases {
+		t.Run(c.query, func(t *testing.T) {
+			ids, scores := []string{}, []float64{}
+			for _, result := range searchIndex.Search(c.query, c.limit) {
+				ids = append(ids, result.Id)
+				scores = append(scores, result.Score)
+			}
+			if !reflect.DeepEqual(ids, c.ids) || !reflect.DeepEqual(scores, c.scores) {
+				t.Errorf("got %v %v, want %v %v", ids, scores, c.ids, c.scores)
+			}
+		})
+	}
+}
+
+func TestStem(t *testing.T) {
+	cases := []struct {
+		word     string
+		expected string
+	}{
+		{"payments", "payment"},
+		{"caresses", "caress"},
+		{"ponies", "poni"},
+		{"ordered", "order"},
+		{"agreed", "agree"},
+		{"feed", "feed"},
+		{"hopping", "hop"},
+		{"falling", "fall"},
+		{"filing", "file"},
+		{"sized", "size"},
+		{"sing", "sing"},
+		{"happy", "happi"},
+		{"v2", "v2"},
+		{"is", "is"},
+	}
+	for _, c := range cases {
+		if stemmed := stem(c.word); stemmed != c.expected {
+			t.Errorf("stem(%s): got %s, want %s", c.word, stemmed, c.expected)
+		}
+	}
+}
+
+func TestSearchHandler(t *testing.T) {
+	handler := NewSearchHandler(newTestSearchIndex(t))
+	cases := []struct {
+		name           string
+		method         string
+		target         string
+		acceptLanguage string
+		status         int
+		displayNames   []string
+	}{
+		{"search", "GET", "/search?q=pets", "", http.StatusOK, []string{"", ""}},
+		{"limit", "GET", "/search?q=pets&limit=1", "", http.StatusOK, []string{""}},
+		{"translated", "GET", "/search?q=pets&limit=1", "de, fr;q=0.5", http.StatusOK, []string{"Animaux"}},
+		{"no match", "GET", "/search?q=unknown", "", http.StatusOK, []string{}},
+		{"missing query", "GET", "/search", "", http.StatusBadRequest, nil},
+		{"blank query", "GET", "/search?q=+", "", http.StatusBadRequest, nil},
+		{"invalid limit", "GET", "/search?q=pets&limit=ten", "", http.StatusBadRequest, nil},
+		{"negative limit", "GET", "/search?q=pets&limit=-1", "", http.StatusBadRequest, nil},
+		{"other method", "POST", "/search?q=pets", "", http.StatusMethodNotAllowed, nil},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(c.method, c.target, nil)
+			r.Header.Set("Accept-Language", c.acceptLanguage)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != c.status {
+				t.Fatalf("got status %d <%s>, want %d", w.Code, w.Body.String(), c.status)
+			}
+			if c.status != http.StatusOK {
+				if w.Header().Get("Content-Type") != "application/problem+json" {
+					t.Errorf("got content type %s, want a problem", w.Header().Get("Content-Type"))
+				}
+				return
+			}
+			var results []SearchResult
+			if err := json.Unmarshal(w.Body.Bytes(), &results); err != nil {
+				t.Fatal(err)
+			}
+			displayNames := []string{}
+			for _, result := range results {
+				displayNames = append(displayNames, result.DisplayName)
+				if result.Localized != nil {
+					t.Errorf("got translations %v in the results", result.Localized)
+				}
+			}
+			if !reflect.DeepEqual(displayNames, c.displayNames) {
+				t.Errorf("got display names %v, want %v", displayNames, c.displayNames)
+			}
+		})
+	}
+}
@@ -0,0 +1,341 @@
+package oas
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// An IndexSink receives the marshalled index documents (index.json, index.yaml).
+type IndexSink interface {
+	Write(name string, data []byte) error
+}
+
+// NewIndexSink builds a sink from an output specification:
+// "-" writes to stdout, "s3://bucket/prefix" writes to an S3-compatible object store,
+// anything else is considered as a local directory.
+func NewIndexSink(output string) (IndexSink, error) {
+	if output == "-" {
+		return NewStdoutSink(), nil
+	}
+
+	if strings.HasPrefix(output, "s3://") {
+		return NewS3SinkFromUrl(output)
+	}
+
+	return NewFileSystemSink(output), nil
+}
+
+// FileSystemSink writes the documents in a local directory.
+// Files are written into a temporary file first and renamed afterwards so that readers never see a partial file.
+type FileSystemSink struct {
+	Directory string
+	FileMode  os.FileMode
+}
+
+func NewFileSystemSink(directory string) *FileSystemSink {
+	return &FileSystemSink{
+		Directory: directory,
+		FileMode:  0644,
+	}
+}
+
+func (s *FileSystemSink) Write(name string, data []byte) error {
+	err := validateArtifactName(name)
+	if err != nil {
+		return err
+	}
+	targetFilePath := filepath.Join(s.Directory, filepath.FromSlash(name))
+	log.Debugf("Writing %s.", targetFilePath)
+
+	// Create directory if required
+	err = os.MkdirAll(filepath.Dir(targetFilePath), 0755)
+	if err != nil {
+		return err
+	}
+
+	// Write into a temporary file located in the same directory, so that rename is atomic.
+	tmpFile, err := ioutil.TempFile(filepath.Dir(targetFilePath), "."+filepath.Base(targetFilePath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpFilePath := tmpFile.Name()
+
+	_, err = tmpFile.Write(data)
+	if err == nil {
+		err = tmpFile.Sync()
+	}
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpFilePath, s.FileMode)
+	}
+	if err != nil {
+		os.Remove(tmpFilePath)
+		return err
+	}
+
+	// Move file to its final location.
+	err = os.Rename(tmpFilePath, targetFilePath)
+	if err != nil {
+		os.Remove(tmpFilePath)
+		return err
+	}
+
+	return nil
+}
+
+// StdoutSink writes the documents one after the other to a writer, stdout by default. As documents
+// are not delimited, it only receives indexes: other artifacts are refused by Index.
+type StdoutSink struct {
+	Writer io.Writer
+}
+
+func NewStdoutSink() *StdoutSink {
+	return &StdoutSink{
+		Writer: os.Stdout,
+	}
+}
+
+func (s *StdoutSink) Write(name string, data []byte) error {
+	log.Debugf("Writing %s to stdout.", name)
+
+	_, err := s.Writer.Write(data)
+	if err != nil {
+		return err
+	}
+
+	// Always terminate the document with a new line.
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		_, err = s.Writer.Write([]byte("\n"))
+	}
+	return err
+}
+
+// S3Sink uploads the documents into a bucket of an S3-compatible object store.
+// Requests are signed using AWS Signature V4 and use path-style addressing, which is supported
+// by AWS S3 as well as by local stand-ins such as MinIO.
+type S3Sink struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+
+	// SessionToken of temporary credentials, such as those obtained from STS or SSO.
+	SessionToken string
+
+	Client *http.Client
+}
+
+func NewS3Sink(bucket string, prefix string) *S3Sink {
+	return &S3Sink{
+		Endpoint: "https://s3.amazonaws.com",
+		Region:   "us-east-1",
+		Bucket:   bucket,
+		Prefix:   strings.Trim(prefix, "/"),
+		Client:   http.DefaultClient,
+	}
+}
+
+// NewS3SinkFromUrl builds a sink from a s3://bucket/prefix URL.
+// Endpoint, region and credentials are read from the standard AWS environment variables.
+func NewS3SinkFromUrl(rawUrl string) (*S3Sink, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "s3" || u.Host == "" {
+		return nil, fmt.Errorf("invalid S3 output <%s>: expected s3://bucket/prefix", rawUrl)
+	}
+
+	sink := NewS3Sink(u.Host, u.Path)
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		sink.Endpoint = endpoint
+	}
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		sink.Region = region
+	}
+	sink.AccessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+	sink.SecretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	sink.SessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	return sink, nil
+}
+
+func (s *S3Sink) Write(name string, data []byte) error {
+	err := validateArtifactName(name)
+	if err != nil {
+		return err
+	}
+
+	// Compute object key
+	key := name
+	if s.Prefix != "" {
+		key = path.Join(s.Prefix, name)
+	}
+
+	// Resolve the object against the endpoint, which may have a path when behind a proxy
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return err
+	}
+	endpoint.Path = strings.TrimSuffix(endpoint.Path, "/") + "/"
+	endpoint.RawPath = ""
+	objectPath := s.Bucket + "/" + key
+	objectUrl := endpoint.ResolveReference(&url.URL{Path: objectPath, RawPath: s3EscapePath(objectPath)}).String()
+	log.Debugf("Uploading %s to %s.", name, objectUrl)
+
+	// Build request
+	req, err := http.NewRequest(http.MethodPut, objectUrl, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	req.Header.Set("Content-Type", contentTypeOf(name))
+	s.sign(req, data, time.Now().UTC())
+
+	// Send request
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("upload of %s to %s failed with status %d: %s", name, objectUrl, res.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// sign adds the AWS Signature V4 headers to the request.
+func (s *S3Sink) sign(req *http.Request, payload []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	shortDate := now.Format("20060102")
+	payloadHash := sha256Hex(payload)
+
+	req.Header.Set("Host", req.URL.Host)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	// Anonymous access: no signature.
+	if s.AccessKey == "" {
+		return
+	}
+	if s.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.SessionToken)
+	}
+
+	// Canonical headers
+	headerNames := []string{}
+	for name := range req.Header {
+		headerNames = append(headerNames, strings.ToLower(name))
+	}
+	sort.Strings(headerNames)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range headerNames {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(req.Header.Get(name)) + "\n")
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	// Canonical request
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	// String to sign
+	scope := strings.Join([]string{shortDate, s.Region, "s3", "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	// Signature
+	signingKey := hmacSha256([]byte("AWS4"+s.SecretKey), shortDate)
+	signingKey = hmacSha256(signingKey, s.Region)
+	signingKey = hmacSha256(signingKey, "s3")
+	signingKey = hmacSha256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", s.AccessKey, scope, signedHeaders, signature))
+}
+
+// validateArtifactName checks that the slash-separated name of a document stays within the root of
+// the sink once cleaned.
+func validateArtifactName(name string) error {
+	cleanName := path.Clean(strings.ReplaceAll(name, "\\", "/"))
+	if name == "" || path.IsAbs(cleanName) || cleanName == "." || cleanName == ".." || strings.HasPrefix(cleanName, "../") {
+		return fmt.Errorf("invalid document name <%s>: outside of the output", name)
+	}
+	return nil
+}
+
+func s3EscapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = s3EscapeSegment(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func s3EscapeSegment(segment string) string {
+	var b strings.Builder
+	for _, c := range []byte(segment) {
+		if (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func contentTypeOf(name string) string {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return "application/json"
+	case ".yaml", ".yml":
+		return "application/yaml"
+	default:
+		return "application/octet-stream"
+	}
+}
@@ -0,0 +1,645 @@
+package oas
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HttpMethods lists the operations of a path item, in the order in which they are documented.
+var HttpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+type OAS3Server struct {
+	Url         string `yaml:"url" json:"url"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+type OAS3Tag struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+type OAS3PathItem struct {
+	Ref         string           `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Summary     string           `yaml:"summary,omitempty" json:"summary,omitempty"`
+	Description string           `yaml:"description,omitempty" json:"description,omitempty"`
+	Parameters  []*OAS3Parameter `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Get         *OAS3Operation   `yaml:"get,omitempty" json:"get,omitempty"`
+	Put         *OAS3Operation   `yaml:"put,omitempty" json:"put,omitempty"`
+	Post        *OAS3Operation   `yaml:"post,omitempty" json:"post,omitempty"`
+	Delete      *OAS3Operation   `yaml:"delete,omitempty" json:"delete,omitempty"`
+	Options     *OAS3Operation   `yaml:"options,omitempty" json:"options,omitempty"`
+	Head        *OAS3Operation   `yaml:"head,omitempty" json:"head,omitempty"`
+	Patch       *OAS3Operation   `yaml:"patch,omitempty" json:"patch,omitempty"`
+	Trace       *OAS3Operation   `yaml:"trace,omitempty" json:"trace,omitempty"`
+}
+
+// Operation returns the operation of the path item for the given HTTP method, or nil.
+func (p *OAS3PathItem) Operation(method string) *OAS3Operation {
+	switch strings.ToLower(method) {
+	case "get":
+		return p.Get
+	case "put":
+		return p.Put
+	case "post":
+		return p.Post
+	case "delete":
+		return p.Delete
+	case "options":
+		return p.Options
+	case "head":
+		return p.Head
+	case "patch":
+		return p.Patch
+	case "trace":
+		return p.Trace
+	}
+	return nil
+}
+
+type OAS3Operation struct {
+	OperationId string                   `yaml:"operationId,omitempty" json:"operationId,omitempty"`
+	Summary     string                   `yaml:"summary,omitempty" json:"summary,omitempty"`
+	Description string                   `yaml:"description,omitempty" json:"description,omitempty"`
+	Tags        []string                 `yaml:"tags,omitempty" json:"tags,omitempty"`
+	Deprecated  bool                     `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	Parameters  []*OAS3Parameter         `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	RequestBody *OAS3RequestBody         `yaml:"requestBody,omitempty" json:"requestBody,omitempty"`
+	Responses   map[string]*OAS3Response `yaml:"responses,omitempty" json:"responses,omitempty"`
+	Security    []map[string][]string    `yaml:"security,omitempty" json:"security,omitempty"`
+}
+
+type OAS3Parameter struct {
+	Ref         string                    `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Name        string                    `yaml:"name,omitempty" json:"name,omitempty"`
+	In          string                    `yaml:"in,omitempty" json:"in,omitempty"`
+	Description string                    `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool                      `yaml:"required,omitempty" json:"required,omitempty"`
+	Deprecated  bool                      `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	Style       string                    `yaml:"style,omitempty" json:"style,omitempty"`
+	Explode     *bool                     `yaml:"explode,omitempty" json:"explode,omitempty"`
+	Schema      *OAS3Schema               `yaml:"schema,omitempty" json:"schema,omitempty"`
+	Example     interface{}               `yaml:"example,omitempty" json:"example,omitempty"`
+	Examples    map[string]*OAS3Example   `yaml:"examples,omitempty" json:"examples,omitempty"`
+	Content     map[string]*OAS3MediaType `yaml:"content,omitempty" json:"content,omitempty"`
+}
+
+type OAS3RequestBody struct {
+	Ref         string                    `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Description string                    `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool                      `yaml:"required,omitempty" json:"required,omitempty"`
+	Content     map[string]*OAS3MediaType `yaml:"content,omitempty" json:"content,omitempty"`
+}
+
+type OAS3MediaType struct {
+	Schema   *OAS3Schema             `yaml:"schema,omitempty" json:"schema,omitempty"`
+	Example  interface{}             `yaml:"example,omitempty" json:"example,omitempty"`
+	Examples map[string]*OAS3Example `yaml:"examples,omitempty" json:"examples,omitempty"`
+}
+
+type OAS3Example struct {
+	Ref           string      `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Summary       string      `yaml:"summary,omitempty" json:"summary,omitempty"`
+	Description   string      `yaml:"description,omitempty" json:"description,omitempty"`
+	Value         interface{} `yaml:"value,omitempty" json:"value,omitempty"`
+	ExternalValue string      `yaml:"externalValue,omitempty" json:"externalValue,omitempty"`
+}
+
+type OAS3Response struct {
+	Ref         string                    `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Description string                    `yaml:"description,omitempty" json:"description,omitempty"`
+	Headers     map[string]*OAS3Header    `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Content     map[string]*OAS3MediaType `yaml:"content,omitempty" json:"content,omitempty"`
+}
+
+type OAS3Header struct {
+	Ref         string      `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Description string      `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool        `yaml:"required,omitempty" json:"required,omitempty"`
+	Deprecated  bool        `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	Schema      *OAS3Schema `yaml:"schema,omitempty" json:"schema,omitempty"`
+	Example     interface{} `yaml:"example,omitempty" json:"example,omitempty"`
+}
+
+type OAS3Schema struct {
+	Ref                  string                 `yaml:"$ref,omitempty" json:"$ref,omitempty"`
+	Title                string                 `yaml:"title,omitempty" json:"title,omitempty"`
+	Description          string                 `yaml:"description,omitempty" json:"description,omitempty"`
+	Type                 string                 `yaml:"type,omitempty" json:"type,omitempty"`
+	Format               string                 `yaml:"format,omitempty" json:"format,omitempty"`
+	Nullable             bool                   `yaml:"nullable,omitempty" json:"nullable,omitempty"`
+	Enum                 []interface{}          `yaml:"enum,omitempty" json:"enum,omitempty"`
+	Default              interface{}            `yaml:"default,omitempty" json:"default,omitempty"`
+	Example              interface{}            `yaml:"example,omitempty" json:"example,omitempty"`
+	Deprecated           bool                   `yaml:"deprecated,omitempty" json:"deprecated,omitempty"`
+	ReadOnly             bool                   `yaml:"readOnly,omitempty" json:"readOnly,omitempty"`
+	WriteOnly            bool                   `yaml:"writeOnly,omitempty" json:"writeOnly,omitempty"`
+	Minimum              *float64               `yaml:"minimum,omitempty" json:"minimum,omitempty"`
+	Maximum              *float64               `yaml:"maximum,omitempty" json:"maximum,omitempty"`
+	ExclusiveMinimum     bool                   `yaml:"exclusiveMinimum,omitempty" json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool                   `yaml:"exclusiveMaximum,omitempty" json:"exclusiveMaximum,omitempty"`
+	MultipleOf           *float64               `yaml:"multipleOf,omitempty" json:"multipleOf,omitempty"`
+	MinLength            *int                   `yaml:"minLength,omitempty" json:"minLength,omitempty"`
+	MaxLength            *int                   `yaml:"maxLength,omitempty" json:"maxLength,omitempty"`
+	Pattern              string                 `yaml:"pattern,omitempty" json:"pattern,omitempty"`
+	MinItems             *int                   `yaml:"minItems,omitempty" json:"minItems,omitempty"`
+	MaxItems             *int                   `yaml:"maxItems,omitempty" json:"maxItems,omitempty"`
+	UniqueItems          bool                   `yaml:"uniqueItems,omitempty" json:"uniqueItems,omitempty"`
+	MinProperties        *int                   `yaml:"minProperties,omitempty" json:"minProperties,omitempty"`
+	MaxProperties        *int                   `yaml:"maxProperties,omitempty" json:"maxProperties,omitempty"`
+	Required             []string               `yaml:"required,omitempty" json:"required,omitempty"`
+	Properties           map[string]*OAS3Schema `yaml:"properties,omitempty" json:"properties,omitempty"`
+	AdditionalProperties interface{}            `yaml:"additionalProperties,omitempty" json:"additionalProperties,omitempty"`
+	Items                *OAS3Schema            `yaml:"items,omitempty" json:"items,omitempty"`
+	AllOf                []*OAS3Schema          `yaml:"allOf,omitempty" json:"allOf,omitempty"`
+	OneOf                []*OAS3Schema          `yaml:"oneOf,omitempty" json:"oneOf,omitempty"`
+	AnyOf                []*OAS3Schema          `yaml:"anyOf,omitempty" json:"anyOf,omitempty"`
+	Not                  *OAS3Schema            `yaml:"not,omitempty" json:"not,omitempty"`
+}
+
+// oas3SchemaFields has the fields of OAS3Schema, without its decoding methods.
+type oas3SchemaFields OAS3Schema
+
+// UnmarshalYAML decodes OpenAPI 3.0 schemas as well as the OpenAPI 3.1 forms which have an
+// equivalent: boolean schemas, type arrays including "null" and numeric exclusive bounds.
+func (s *OAS3Schema) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode && value.Tag == "!!bool" {
+		*s = booleanSchema(value.Value == "true")
+		return nil
+	}
+	if value.Kind == yaml.MappingNode {
+		value = normalizeSchemaNode(value)
+	}
+	return value.Decode((*oas3SchemaFields)(s))
+}
+
+// UnmarshalJSON decodes OpenAPI 3.0 schemas as well as the OpenAPI 3.1 forms which have an
+// equivalent, like UnmarshalYAML.
+func (s *OAS3Schema) UnmarshalJSON(data []byte) error {
+	var value bool
+	if json.Unmarshal(data, &value) == nil {
+		*s = booleanSchema(value)
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return json.Unmarshal(data, (*oas3SchemaFields)(s))
+	}
+
+	// Normalize through YAML, of which JSON is a subset, keeping the other values as is
+	var node yaml.Node
+	normalized := make(map[string]json.RawMessage, len(fields))
+	for _, key := range []string{"type", "nullable", "minimum", "maximum", "exclusiveMinimum", "exclusiveMaximum"} {
+		if field, found := fields[key]; found {
+			normalized[key] = field
+			delete(fields, key)
+		}
+	}
+	content, err := json.Marshal(normalized)
+	if err == nil {
+		err = yaml.Unmarshal(content, &node)
+	}
+	if err != nil || len(node.Content) == 0 {
+		return json.Unmarshal(data, (*oas3SchemaFields)(s))
+	}
+	var boundaries oas3SchemaFields
+	err = normalizeSchemaNode(node.Content[0]).Decode(&boundaries)
+	if err != nil {
+		return err
+	}
+	content, err = json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	err = json.Unmarshal(content, (*oas3SchemaFields)(s))
+	if err != nil {
+		return err
+	}
+	s.Type, s.Nullable = boundaries.Type, boundaries.Nullable
+	s.Minimum, s.Maximum = boundaries.Minimum, boundaries.Maximum
+	s.ExclusiveMinimum, s.ExclusiveMaximum = boundaries.ExclusiveMinimum, boundaries.ExclusiveMaximum
+	return nil
+}
+
+// booleanSchema converts the true and false schemas of OpenAPI 3.1: true accepts any value, false none.
+func booleanSchema(value bool) OAS3Schema {
+	if value {
+		return OAS3Schema{}
+	}
+	return OAS3Schema{Not: &OAS3Schema{}}
+}
+
+// normalizeSchemaNode returns a copy of a schema mapping where the OpenAPI 3.1 type arrays become
+// a type and the nullable flag, the first type being kept when several are listed, and where the
+// numeric exclusiveMinimum and exclusiveMaximum become a bound with the OpenAPI 3.0 boolean flag.
+func normalizeSchemaNode(value *yaml.Node) *yaml.Node {
+	fields := make(map[string]*yaml.Node)
+	keys := []string{}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		key := value.Content[i].Value
+		if _, found := fields[key]; !found {
+			keys = append(keys, key)
+		}
+		fields[key] = value.Content[i+1]
+	}
+
+	if typeNode := fields["type"]; typeNode != nil && typeNode.Kind == yaml.SequenceNode {
+		delete(fields, "type")
+		for _, item := range typeNode.Content {
+			switch {
+			case item.Value == "null":
+				fields["nullable"] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"}
+				keys = append(keys, "nullable")
+			case fields["type"] == nil:
+				fields["type"] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item.Value}
+			}
+		}
+	}
+	for _, bound := range []struct {
+		exclusive string
+		inclusive string
+		stricter  func(a float64, b float64) bool
+	}{
+		{"exclusiveMinimum", "minimum", func(a float64, b float64) bool { return a >= b }},
+		{"exclusiveMaximum", "maximum", func(a float64, b float64) bool { return a <= b }},
+	} {
+		exclusiveNode := fields[bound.exclusive]
+		if exclusiveNode == nil || exclusiveNode.Kind != yaml.ScalarNode || (exclusiveNode.Tag != "!!int" && exclusiveNode.Tag != "!!float") {
+			continue
+		}
+		exclusive, err := strconv.ParseFloat(exclusiveNode.Value, 64)
+		if err != nil {
+			continue
+		}
+		fields[bound.exclusive] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"}
+		if inclusiveNode := fields[bound.inclusive]; inclusiveNode != nil {
+			if inclusive, err := strconv.ParseFloat(inclusiveNode.Value, 64); err == nil && !bound.stricter(exclusive, inclusive) {
+				continue
+			}
+		} else {
+			keys = append(keys, bound.inclusive)
+		}
+		fields[bound.exclusive].Value = "true"
+		fields[bound.inclusive] = exclusiveNode
+	}
+
+	normalized := &yaml.Node{Kind: yaml.MappingNode, Tag: value.Tag, Line: value.Line, Column: value.Column}
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		if fields[key] == nil || seen[key] {
+			continue
+		}
+		seen[key] = true
+		normalized.Content = append(normalized.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, fields[key])
+	}
+	return normalized
+}
+
+// AdditionalPropertiesSchema returns the schema of the additional properties, if any, and
+// whether additional properties are allowed at all.
+func (s *OAS3Schema) AdditionalPropertiesSchema() (*OAS3Schema, bool) {
+	switch value := s.AdditionalProperties.(type) {
+	case nil:
+		return nil, true
+	case bool:
+		return nil, value
+	case *OAS3Schema:
+		return value, true
+	default:
+		// Decoded as a generic map: convert it back into a schema.
+		bytes, err := json.Marshal(normalizeGenericValue(value))
+		if err != nil {
+			return nil, true
+		}
+		var schema OAS3Schema
+		if err := json.Unmarshal(bytes, &schema); err != nil {
+			return nil, true
+		}
+		return &schema, true
+	}
+}
+
+// IsRequired returns true if the property is listed as required.
+func (s *OAS3Schema) IsRequired(property string) bool {
+	for _, required := range s.Required {
+		if required == property {
+			return true
+		}
+	}
+	return false
+}
+
+type OAS3Components struct {
+	Schemas         map[string]*OAS3Schema      `yaml:"schemas,omitempty" json:"schemas,omitempty"`
+	Responses       map[string]*OAS3Response    `yaml:"responses,omitempty" json:"responses,omitempty"`
+	Parameters      map[string]*OAS3Parameter   `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+	Examples        map[string]*OAS3Example     `yaml:"examples,omitempty" json:"examples,omitempty"`
+	RequestBodies   map[string]*OAS3RequestBody `yaml:"requestBodies,omitempty" json:"requestBodies,omitempty"`
+	Headers         map[string]*OAS3Header      `yaml:"headers,omitempty" json:"headers,omitempty"`
+	SecuritySchemes map[string]interface{}      `yaml:"securitySchemes,omitempty" json:"securitySchemes,omitempty"`
+}
+
+// OAS3OperationRef identifies an operation of a specification.
+type OAS3OperationRef struct {
+	Path      string
+	Method    string
+	PathItem  *OAS3PathItem
+	Operation *OAS3Operation
+}
+
+// Operations returns every operation of the specification, sorted by path then method.
+func (s *OAS3Specification) Operations() []*OAS3OperationRef {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	operations := []*OAS3OperationRef{}
+	for _, path := range paths {
+		pathItem := s.ResolvePathItem(s.Paths[path])
+		if pathItem == nil {
+			continue
+		}
+		for _, method := range HttpMethods {
+			if operation := pathItem.Operation(method); operation != nil {
+				operations = append(operations, &OAS3OperationRef{
+					Path:      path,
+					Method:    method,
+					PathItem:  pathItem,
+					Operation: operation,
+				})
+			}
+		}
+	}
+	return operations
+}
+
+// FindOperation returns the operation matching the HTTP method and request path, along with the
+// values of the path parameters. Literal path segments take precedence over templated ones.
+func (s *OAS3Specification) FindOperation(method string, requestPath string) (*OAS3OperationRef, map[string]string) {
+	var bestMatch *OAS3OperationRef
+	var bestParams map[string]string
+	bestScore := -1
+
+	requestSegments := splitPath(requestPath)
+	for path, pathItem := range s.Paths {
+		pathItem = s.ResolvePathItem(pathItem)
+		if pathItem == nil || pathItem.Operation(method) == nil {
+			continue
+		}
+
+		params, score, ok := matchPath(splitPath(path), requestSegments)
+		if !ok || score < bestScore || (score == bestScore && bestMatch != nil && path > bestMatch.Path) {
+			continue
+		}
+
+		bestScore = score
+		bestParams = params
+		bestMatch = &OAS3OperationRef{
+			Path:      path,
+			Method:    strings.ToLower(method),
+			PathItem:  pathItem,
+			Operation: pathItem.Operation(method),
+		}
+	}
+	return bestMatch, bestParams
+}
+
+// OperationParameters returns the resolved parameters of an operation, operation-level parameters
+// overriding path-level ones with the same name and location.
+func (s *OAS3Specification) OperationParameters(operation *OAS3OperationRef) []*OAS3Parameter {
+	parameters := []*OAS3Parameter{}
+	indexes := make(map[string]int)
+	for _, parameter := range append(append([]*OAS3Parameter{}, operation.PathItem.Parameters...), operation.Operation.Parameters...) {
+		parameter = s.ResolveParameter(parameter)
+		if parameter == nil {
+			continue
+		}
+		key := parameter.In + ":" + parameter.Name
+		if index, found := indexes[key]; found {
+			parameters[index] = parameter
+		} else {
+			indexes[key] = len(parameters)
+			parameters = append(parameters, parameter)
+		}
+	}
+	return parameters
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, "/")
+}
+
+// matchPath matches request segments against a path template and returns the path parameters
+// and a score counting the literal segments.
+func matchPath(templateSegments []string, requestSegments []string) (map[string]string, int, bool) {
+	if len(templateSegments) != len(requestSegments) {
+		return nil, 0, false
+	}
+
+	params := make(map[string]string)
+	score := 0
+	for i, templateSegment := range templateSegments {
+		start := strings.Index(templateSegment, "{")
+		end := strings.LastIndex(templateSegment, "}")
+		if start < 0 || end < start {
+			if templateSegment != requestSegments[i] {
+				return nil, 0, false
+			}
+			score++
+			continue
+		}
+
+		// Templated segment, possibly with a literal prefix or suffix (e.g. "{id}.json").
+		prefix := templateSegment[:start]
+		suffix := templateSegment[end+1:]
+		segment := requestSegments[i]
+		if !strings.HasPrefix(segment, prefix) || !strings.HasSuffix(segment, suffix) || len(segment) <= len(prefix)+len(suffix) {
+			return nil, 0, false
+		}
+		params[templateSegment[start+1:end]] = segment[len(prefix) : len(segment)-len(suffix)]
+	}
+	return params, score, true
+}
+
+// refName returns the name of the component referenced by a local reference such as
+// "#/components/schemas/Pet", given the expected component type.
+func refName(ref string, componentType string) (string, bool) {
+	prefix := "#/components/" + componentType + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(ref, prefix)
+	name = strings.ReplaceAll(strings.ReplaceAll(name, "~1", "/"), "~0", "~")
+	return name, true
+}
+
+// SchemaRefName returns the name of the component schema referenced by a schema, if any.
+func SchemaRefName(schema *OAS3Schema) (string, bool) {
+	if schema == nil || schema.Ref == "" {
+		return "", false
+	}
+	return refName(schema.Ref, "schemas")
+}
+
+// ResolveSchema follows the local references of a schema. Unresolvable references return nil.
+func (s *OAS3Specification) ResolveSchema(schema *OAS3Schema) *OAS3Schema {
+	for depth := 0; schema != nil && schema.Ref != "" && depth < 32; depth++ {
+		name, ok := refName(schema.Ref, "schemas")
+		if !ok {
+			return nil
+		}
+		schema = s.Components.Schemas[name]
+	}
+	return schema
+}
+
+func (s *OAS3Specification) ResolveParameter(parameter *OAS3Parameter) *OAS3Parameter {
+	for depth := 0; parameter != nil && parameter.Ref != "" && depth < 32; depth++ {
+		name, ok := refName(parameter.Ref, "parameters")
+		if !ok {
+			return nil
+		}
+		parameter = s.Components.Parameters[name]
+	}
+	return parameter
+}
+
+func (s *OAS3Specification) ResolveRequestBody(requestBody *OAS3RequestBody) *OAS3RequestBody {
+	for depth := 0; requestBody != nil && requestBody.Ref != "" && depth < 32; depth++ {
+		name, ok := refName(requestBody.Ref, "requestBodies")
+		if !ok {
+			return nil
+		}
+		requestBody = s.Components.RequestBodies[name]
+	}
+	return requestBody
+}
+
+func (s *OAS3Specification) ResolveResponse(response *OAS3Response) *OAS3Response {
+	for depth := 0; response != nil && response.Ref != "" && depth < 32; depth++ {
+		name, ok := refName(response.Ref, "responses")
+		if !ok {
+			return nil
+		}
+		response = s.Components.Responses[name]
+	}
+	return response
+}
+
+func (s *OAS3Specification) ResolveExample(example *OAS3Example) *OAS3Example {
+	for depth := 0; example != nil && example.Ref != "" && depth < 32; depth++ {
+		name, ok := refName(example.Ref, "examples")
+		if !ok {
+			return nil
+		}
+		example = s.Components.Examples[name]
+	}
+	return example
+}
+
+func (s *OAS3Specification) ResolveHeader(header *OAS3Header) *OAS3Header {
+	for depth := 0; header != nil && header.Ref != "" && depth < 32; depth++ {
+		name, ok := refName(header.Ref, "headers")
+		if !ok {
+			return nil
+		}
+		header = s.Components.Headers[name]
+	}
+	return header
+}
+
+// ResolvePathItem returns the path item; references to external path items are not supported.
+func (s *OAS3Specification) ResolvePathItem(pathItem *OAS3PathItem) *OAS3PathItem {
+	if pathItem != nil && pathItem.Ref != "" {
+		return nil
+	}
+	return pathItem
+}
+
+// SortedResponseCodes returns the response codes of an operation: 2xx codes first, then other
+// codes in ascending order, "default" last.
+func SortedResponseCodes(operation *OAS3Operation) []string {
+	codes := make([]string, 0, len(operation.Responses))
+	for code := range operation.Responses {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		return responseCodeRank(codes[i]) < responseCodeRank(codes[j])
+	})
+	return codes
+}
+
+func responseCodeRank(code string) string {
+	code = strings.ToUpper(code)
+	switch {
+	case code == "DEFAULT":
+		return "9" + code
+	case strings.HasPrefix(code, "2"):
+		return "0" + code
+	default:
+		return "1" + code
+	}
+}
+
+// PreferredMediaType returns the JSON media type of a content map if any, or the first one
+// in alphabetical order.
+func PreferredMediaType(content map[string]*OAS3MediaType) (string, *OAS3MediaType) {
+	mediaTypes := make([]string, 0, len(content))
+	for mediaType := range content {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	sort.Strings(mediaTypes)
+
+	for _, mediaType := range mediaTypes {
+		if IsJsonMediaType(mediaType) {
+			return mediaType, content[mediaType]
+		}
+	}
+	if len(mediaTypes) > 0 {
+		return mediaTypes[0], content[mediaTypes[0]]
+	}
+	return "", nil
+}
+
+// IsJsonMediaType returns true for application/json and application/*+json media types.
+func IsJsonMediaType(mediaType string) bool {
+	mediaType = strings.ToLower(strings.TrimSpace(strings.Split(mediaType, ";")[0]))
+	return mediaType == "application/json" || (strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"))
+}
+
+// normalizeGenericValue converts the map[interface{}]interface{} values that some decoders
+// produce into map[string]interface{}, so that values can be marshalled into JSON.
+func normalizeGenericValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[fmt.Sprint(key)] = normalizeGenericValue(item)
+		}
+		return result
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = normalizeGenericValue(item)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = normalizeGenericValue(item)
+		}
+		return result
+	default:
+		return v
+	}
+}
@@ -0,0 +1,168 @@
+package oas
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestOAS3SchemaUnmarshal(t *testing.T) {
+	cases := []struct {
+		name             string
+		yaml             string
+		json             string
+		typ              string
+		nullable         bool
+		minimum          *float64
+		maximum          *float64
+		exclusiveMinimum bool
+		exclusiveMaximum bool
+		not              bool
+	}{
+		{
+			name: "openapi 3.0 type",
+			yaml: "type: string\nnullable: true",
+			json: `{"type":"string","nullable":true}`,
+			typ:  "string", nullable: true,
+		},
+		{
+			name: "type array with null",
+			yaml: "type: [string, \"null\"]",
+			json: `{"type":["string","null"]}`,
+			typ:  "string", nullable: true,
+		},
+		{
+			name: "type array keeps the first type",
+			yaml: "type: [integer, string]",
+			json: `{"type":["integer","string"]}`,
+			typ:  "integer",
+		},
+		{
+			name:    "openapi 3.0 exclusive bounds",
+			yaml:    "minimum: 3\nexclusiveMinimum: true\nmaximum: 5",
+			json:    `{"minimum":3,"exclusiveMinimum":true,"maximum":5}`,
+			minimum: float64Pointer(3), maximum: float64Pointer(5), exclusiveMinimum: true,
+		},
+		{
+			name: "numeric exclusive minimum",
+			yaml: "type: integer\nexclusiveMinimum: 3",
+			json: `{"type":"integer","exclusiveMinimum":3}`,
+			typ:  "integer", minimum: float64Pointer(3), exclusiveMinimum: true,
+		},
+		{
+			name:    "numeric exclusive maximum stricter than maximum",
+			yaml:    "maximum: 20\nexclusiveMaximum: 10",
+			json:    `{"maximum":20,"exclusiveMaximum":10}`,
+			maximum: float64Pointer(10), exclusiveMaximum: true,
+		},
+		{
+			name:    "maximum stricter than numeric exclusive maximum",
+			yaml:    "maximum: 5\nexclusiveMaximum: 10.5",
+			json:    `{"maximum":5,"exclusiveMaximum":10.5}`,
+			maximum: float64Pointer(5),
+		},
+		{
+			name: "false schema",
+			yaml: "false",
+			json: `false`,
+			not:  true,
+		},
+		{
+			name: "true schema",
+			yaml: "true",
+			json: `true`,
+		},
+	}
+	for _, c := range cases {
+		for format, decode := range map[string]func(schema *OAS3Schema) error{
+			"yaml": func(schema *OAS3Schema) error { return yaml.Unmarshal([]byte(c.yaml), schema) },
+			"json": func(schema *OAS3Schema) error { return json.Unmarshal([]byte(c.json), schema) },
+		} {
+			t.Run(c.name+"/"+format, func(t *testing.T) {
+				schema := &OAS3Schema{}
+				if err := decode(schema); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if schema.Type != c.typ || schema.Nullable != c.nullable {
+					t.Errorf("got type <%s> nullable <%t>, want <%s> <%t>", schema.Type, schema.Nullable, c.typ, c.nullable)
+				}
+				if !equalFloat64Pointers(schema.Minimum, c.minimum) || schema.ExclusiveMinimum != c.exclusiveMinimum {
+					t.Errorf("got minimum <%v> exclusive <%t>, want <%v> <%t>", schema.Minimum, schema.ExclusiveMinimum, c.minimum, c.exclusiveMinimum)
+				}
+				if !equalFloat64Pointers(schema.Maximum, c.maximum) || schema.ExclusiveMaximum != c.exclusiveMaximum {
+					t.Errorf("got maximum <%v> exclusive <%t>, want <%v> <%t>", schema.Maximum, schema.ExclusiveMaximum, c.maximum, c.exclusiveMaximum)
+				}
+				if (schema.Not != nil) != c.not {
+					t.Errorf("got not <%v>, want <%t>", schema.Not, c.not)
+				}
+			})
+		}
+	}
+}
+
+func TestOAS3SchemaUnmarshalNested(t *testing.T) {
+	content := "type: object\nproperties:\n  name:\n    type: [string, \"null\"]\n  tuple:\n    type: array\n    items: false\n"
+	schema := &OAS3Schema{}
+	if err := yaml.Unmarshal([]byte(content), schema); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name := schema.Properties["name"]; name == nil || name.Type != "string" || !name.Nullable {
+		t.Errorf("unexpected name property: %+v", name)
+	}
+	if tuple := schema.Properties["tuple"]; tuple == nil || tuple.Items == nil || tuple.Items.Not == nil {
+		t.Errorf("unexpected tuple property: %+v", tuple)
+	}
+}
+
+func TestFindOperation(t *testing.T) {
+	specification := &OAS3Specification{Paths: map[string]*OAS3PathItem{
+		"/pets/{id}": {Get: &OAS3Operation{OperationId: "getPet"}},
+		"/pets/mine": {Get: &OAS3Operation{OperationId: "getMyPet"}},
+	}}
+	cases := []struct {
+		method      string
+		path        string
+		operationId string
+		params      map[string]string
+	}{
+		{"GET", "/pets/12", "getPet", map[string]string{"id": "12"}},
+		{"GET", "/pets/mine", "getMyPet", map[string]string{}},
+		{"get", "/pets/12", "getPet", map[string]string{"id": "12"}},
+		{"POST", "/pets/12", "", nil},
+		{"GET", "/pets", "", nil},
+	}
+	for _, c := range cases {
+		t.Run(c.method+" "+c.path, func(t *testing.T) {
+			operation, params := specification.FindOperation(c.method, c.path)
+			if c.operationId == "" {
+				if operation != nil {
+					t.Fatalf("unexpected operation <%s>", operation.Operation.OperationId)
+				}
+				return
+			}
+			if operation == nil || operation.Operation.OperationId != c.operationId {
+				t.Fatalf("got operation %+v, want <%s>", operation, c.operationId)
+			}
+			if len(params) != len(c.params) {
+				t.Fatalf("got params %v, want %v", params, c.params)
+			}
+			for name, value := range c.params {
+				if params[name] != value {
+					t.Errorf("got param <%s>=<%s>, want <%s>", name, params[name], value)
+				}
+			}
+		})
+	}
+}
+
+func float64Pointer(value float64) *float64 {
+	return &value
+}
+
+func equalFloat64Pointers(a *float64, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -0,0 +1,309 @@
+package oas
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// LintViolation is a quality rule not followed by a specification.
+type LintViolation struct {
+	Rule     string `yaml:"rule" json:"rule"`
+	Location string `yaml:"location" json:"location"`
+	Message  string `yaml:"message" json:"message"`
+}
+
+// SpecificationStats are the metrics of a specification. Coverages and scores are percentages.
+type SpecificationStats struct {
+	Name                  string          `yaml:"name" json:"name"`
+	Version               string          `yaml:"version" json:"version"`
+	Paths                 int             `yaml:"paths" json:"paths"`
+	Operations            int             `yaml:"operations" json:"operations"`
+	DeprecatedOperations  int             `yaml:"deprecatedOperations" json:"deprecatedOperations"`
+	Schemas               int             `yaml:"schemas" json:"schemas"`
+	DocumentationCoverage float64         `yaml:"documentationCoverage" json:"documentationCoverage"`
+	ExamplesCoverage      float64         `yaml:"examplesCoverage" json:"examplesCoverage"`
+	LintScore             float64         `yaml:"lintScore" json:"lintScore"`
+	QualityScore          float64         `yaml:"qualityScore" json:"qualityScore"`
+	Grade                 string          `yaml:"grade" json:"grade"`
+	LintViolations        []LintViolation `yaml:"lintViolations,omitempty" json:"lintViolations,omitempty"`
+}
+
+// StatsReport gathers the metrics of several specifications and their aggregate.
+type StatsReport struct {
+	Specifications []*SpecificationStats `yaml:"specifications" json:"specifications"`
+	Aggregate      *SpecificationStats   `yaml:"aggregate" json:"aggregate"`
+}
+
+type StatsOpts struct {
+	// Repository used to resolve name@version references, and whose latest versions are reported
+	// when no specification is given.
+	Repository     string
+	Specifications []string
+
+	// Format of the report: text or json.
+	Format string
+	Writer io.Writer
+}
+
+func NewStatsOpts() *StatsOpts {
+	return &StatsOpts{
+		Repository:     ".",
+		Specifications: []string{},
+		Format:         "text",
+		Writer:         os.Stdout,
+	}
+}
+
+// Stats reports the metrics of specifications, or of the latest versions of a repository.
+func Stats(opts *StatsOpts) error {
+	if opts.Format != "text" && opts.Format != "json" {
+		return fmt.Errorf("unsupported stats format <%s>", opts.Format)
+	}
+
+	// Open repository
+	repository, err := OpenRepositoryIfExists(opts.Repository)
+	if err != nil {
+		return err
+	}
+
+	// Select specifications
+	references := opts.Specifications
+	if len(references) == 0 {
+		if repository == nil {
+			return fmt.Errorf("no specification given and no repository index found in <%s>", opts.Repository)
+		}
+		for id := range repository.Index.Entries {
+			references = append(references, id)
+		}
+		sort.Strings(references)
+	}
+	log.Infof("Computing statistics of %d specifications.", len(references))
+
+	// Compute statistics
+	report := &StatsReport{Specifications: []*SpecificationStats{}}
+	for _, reference := range references {
+		source, _, err := repository.Load(reference)
+		if err != nil {
+			return err
+		}
+		report.Specifications = append(report.Specifications, source.specification.Stats())
+	}
+	report.Aggregate = AggregateStats(report.Specifications)
+
+	// Write report
+	if opts.Format == "json" {
+		content, err := marshallCanonicalJson(report)
+		if err != nil {
+			return err
+		}
+		_, err = opts.Writer.Write(content)
+		return err
+	}
+	return report.writeText(opts.Writer)
+}
+
+// Stats computes the metrics of a specification.
+func (s *OAS3Specification) Stats() *SpecificationStats {
+	stats := &SpecificationStats{
+		Name:                 s.Info.Title,
+		Version:              s.Info.Version,
+		Paths:                len(s.Paths),
+		Schemas:              len(s.Components.Schemas),
+		DeprecatedOperations: len(s.DeprecatedOperations()),
+	}
+
+	documented, documentable := 0, 0
+	exemplified, exemplifiable := 0, 0
+	count := func(ok bool, done *int, total *int) {
+		*total++
+		if ok {
+			*done++
+		}
+	}
+
+	// Operations
+	for _, operation := range s.Operations() {
+		stats.Operations++
+		count(operation.Operation.Summary != "" || operation.Operation.Description != "", &documented, &documentable)
+		for _, parameter := range s.OperationParameters(operation) {
+			count(parameter.Description != "", &documented, &documentable)
+		}
+		if requestBody := s.ResolveRequestBody(operation.Operation.RequestBody); requestBody != nil {
+			for _, mediaType := range requestBody.Content {
+				count(s.hasExample(mediaType), &exemplified, &exemplifiable)
+			}
+		}
+		for _, code := range SortedResponseCodes(operation.Operation) {
+			response := s.ResolveResponse(operation.Operation.Responses[code])
+			if response == nil {
+				continue
+			}
+			count(response.Description != "", &documented, &documentable)
+			for _, mediaType := range response.Content {
+				count(s.hasExample(mediaType), &exemplified, &exemplifiable)
+			}
+		}
+	}
+
+	// Schemas
+	for _, schema := range s.Components.Schemas {
+		count(schema.Description != "" || schema.Title != "", &documented, &documentable)
+		for _, property := range schema.Properties {
+			if _, isRef := SchemaRefName(property); !isRef {
+				count(property.Description != "", &documented, &documentable)
+			}
+		}
+	}
+
+	stats.DocumentationCoverage = percentage(documented, documentable)
+	stats.ExamplesCoverage = percentage(exemplified, exemplifiable)
+	stats.LintViolations, stats.LintScore = s.Lint()
+	stats.QualityScore = round1((stats.DocumentationCoverage + stats.ExamplesCoverage + stats.LintScore) / 3)
+	stats.Grade = grade(stats.QualityScore)
+	return stats
+}
+
+// hasExample tells whether a media type has an example, on itself or on its schema.
+func (s *OAS3Specification) hasExample(mediaType *OAS3MediaType) bool {
+	if mediaType.Example != nil || len(mediaType.Examples) > 0 {
+		return true
+	}
+	schema := s.ResolveSchema(mediaType.Schema)
+	return schema != nil && schema.Example != nil
+}
+
+// Lint checks the quality rules of a specification and returns the violations and the percentage of
+// successful checks.
+func (s *OAS3Specification) Lint() ([]LintViolation, float64) {
+	violations := []LintViolation{}
+	checks := 0
+	check := func(ok bool, rule string, location string, message string) {
+		checks++
+		if !ok {
+			violations = append(violations, LintViolation{Rule: rule, Location: location, Message: message})
+		}
+	}
+
+	check(s.Info.Description != "", "info-description", "info", "the specification has no description")
+	check(s.Info.Contact.Name != "" || s.Info.Contact.Email != "" || s.Info.Contact.Url != "", "info-contact", "info", "the specification has no contact")
+	check(len(s.Servers) > 0, "servers", "servers", "the specification declares no server")
+
+	operationIds := make(map[string]bool)
+	for _, operation := range s.Operations() {
+		location := strings.ToUpper(operation.Method) + " " + operation.Path
+		operationId := operation.Operation.OperationId
+		check(operationId != "", "operation-operationId", location, "the operation has no operationId")
+		if operationId != "" {
+			check(!operationIds[operationId], "operation-operationId-unique", location, "operationId "+operationId+" is not unique")
+			operationIds[operationId] = true
+		}
+		check(operation.Operation.Summary != "", "operation-summary", location, "the operation has no summary")
+		check(len(operation.Operation.Tags) > 0, "operation-tags", location, "the operation has no tag")
+		check(operation.Path == "/" || !strings.HasSuffix(operation.Path, "/"), "path-trailing-slash", location, "the path ends with a slash")
+
+		success := false
+		for code := range operation.Operation.Responses {
+			success = success || strings.HasPrefix(code, "2") || strings.HasPrefix(code, "3")
+		}
+		check(success, "operation-success-response", location, "the operation has no success response")
+
+		for _, parameter := range s.OperationParameters(operation) {
+			check(parameter.Description != "", "parameter-description", location, "parameter "+parameter.Name+" has no description")
+		}
+	}
+
+	names := make([]string, 0, len(s.Components.Schemas))
+	for name := range s.Components.Schemas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		schema := s.Components.Schemas[name]
+		check(schema.Description != "" || schema.Title != "", "schema-description", "schema "+name, "the schema has no description")
+	}
+
+	return violations, percentage(checks-len(violations), checks)
+}
+
+// AggregateStats sums the counts of several specifications and averages their coverages and scores.
+func AggregateStats(specifications []*SpecificationStats) *SpecificationStats {
+	aggregate := &SpecificationStats{Name: "Total"}
+	if len(specifications) == 0 {
+		return aggregate
+	}
+	for _, stats := range specifications {
+		aggregate.Paths += stats.Paths
+		aggregate.Operations += stats.Operations
+		aggregate.DeprecatedOperations += stats.DeprecatedOperations
+		aggregate.Schemas += stats.Schemas
+		aggregate.DocumentationCoverage += stats.DocumentationCoverage
+		aggregate.ExamplesCoverage += stats.ExamplesCoverage
+		aggregate.LintScore += stats.LintScore
+		aggregate.QualityScore += stats.QualityScore
+	}
+	count := float64(len(specifications))
+	aggregate.DocumentationCoverage = round1(aggregate.DocumentationCoverage / count)
+	aggregate.ExamplesCoverage = round1(aggregate.ExamplesCoverage / count)
+	aggregate.LintScore = round1(aggregate.LintScore / count)
+	aggregate.QualityScore = round1(aggregate.QualityScore / count)
+	aggregate.Grade = grade(aggregate.QualityScore)
+	return aggregate
+}
+
+func (r *StatsReport) writeText(writer io.Writer) error {
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "NAME\tVERSION\tOPERATIONS\tDEPRECATED\tSCHEMAS\tDOCS %\tEXAMPLES %\tLINT %\tSCORE\tGRADE")
+	for _, stats := range append(r.Specifications, r.Aggregate) {
+		fmt.Fprintf(table, "%s\t%s\t%d\t%d\t%d\t%.1f\t%.1f\t%.1f\t%.1f\t%s\n", stats.Name, stats.Version, stats.Operations, stats.DeprecatedOperations, stats.Schemas, stats.DocumentationCoverage, stats.ExamplesCoverage, stats.LintScore, stats.QualityScore, stats.Grade)
+	}
+	err := table.Flush()
+	if err != nil {
+		return err
+	}
+
+	for _, stats := range r.Specifications {
+		if len(stats.LintViolations) == 0 {
+			continue
+		}
+		fmt.Fprintf(writer, "\n%s %s:\n", stats.Name, stats.Version)
+		for _, violation := range stats.LintViolations {
+			fmt.Fprintf(writer, "  [%s] %s: %s\n", violation.Rule, violation.Location, violation.Message)
+		}
+	}
+	return nil
+}
+
+// percentage returns done/total as a percentage rounded to one decimal, 100 when there is nothing to do.
+func percentage(done int, total int) float64 {
+	if total == 0 {
+		return 100
+	}
+	return round1(100 * float64(done) / float64(total))
+}
+
+func round1(value float64) float64 {
+	return math.Round(value*10) / 10
+}
+
+// grade converts a quality score into a letter, from A (>= 90) to E (< 60).
+func grade(score float64) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "E"
+	}
+}
@@ -0,0 +1,189 @@
+package oas
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const statsTestSpecification = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+  description: Pets of the shop.
+  contact:
+    name: Shop
+servers:
+  - url: https://api.example.com
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List the pets
+      tags: [pets]
+      parameters:
+        - name: limit
+          in: query
+          description: Maximum number of pets.
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: Pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+              example: []
+    post:
+      operationId: createPet
+      description: Create a pet.
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        "201":
+          description: Created
+components:
+  schemas:
+    Pet:
+      description: A pet.
+      type: object
+      properties:
+        name:
+          type: string
+          description: Name of the pet.
+        tag:
+          type: string
+        owner:
+          $ref: '#/components/schemas/Owner'
+    Owner:
+      type: object
+`
+
+func parseStatsTestSpecification(t *testing.T, content string) *OAS3Specification {
+	t.Helper()
+	specification := &OAS3Specification{}
+	if err := yaml.Unmarshal([]byte(content), specification); err != nil {
+		t.Fatal(err)
+	}
+	return specification
+}
+
+func TestStats(t *testing.T) {
+	cases := []struct {
+		name          string
+		specification string
+		expected      SpecificationStats
+		rules         []string
+	}{
+		{
+			"specification",
+			statsTestSpecification,
+			SpecificationStats{Name: "Pets", Version: "1.0.0", Paths: 1, Operations: 2, Schemas: 2, DocumentationCoverage: 77.8, ExamplesCoverage: 50, LintScore: 83.3, QualityScore: 70.4, Grade: "C"},
+			[]string{"operation-summary", "operation-tags", "schema-description"},
+		},
+		{
+			"nothing to count",
+			"openapi: 3.0.3\ninfo:\n  title: Empty\n  version: 1.0.0\npaths: {}\n",
+			SpecificationStats{Name: "Empty", Version: "1.0.0", DocumentationCoverage: 100, ExamplesCoverage: 100, LintScore: 0, QualityScore: 66.7, Grade: "D"},
+			[]string{"info-contact", "info-description", "servers"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stats := parseStatsTestSpecification(t, c.specification).Stats()
+			rules := []string{}
+			for _, violation := range stats.LintViolations {
+				rules = append(rules, violation.Rule)
+			}
+			sort.Strings(rules)
+			if !reflect.DeepEqual(rules, c.rules) {
+				t.Errorf("got violations %v, want %v", rules, c.rules)
+			}
+			stats.LintViolations = nil
+			if !reflect.DeepEqual(*stats, c.expected) {
+				t.Errorf("got %+v, want %+v", *stats, c.expected)
+			}
+		})
+	}
+}
+
+func TestLint(t *testing.T) {
+	specification := parseStatsTestSpecification(t, statsTestSpecification)
+	specification.Paths["/pets/"] = specification.Paths["/pets"]
+
+	violations, score := specification.Lint()
+	counts := map[string]int{}
+	for _, violation := range violations {
+		counts[violation.Rule]++
+	}
+	// 31 checks: 3 on the info and servers, 2 on the schemas, 7 on each GET and 6 on each POST.
+	expected := map[string]int{"operation-operationId-unique": 2, "operation-summary": 2, "operation-tags": 2, "path-trailing-slash": 2, "schema-description": 1}
+	if !reflect.DeepEqual(counts, expected) || score != 71.0 {
+		t.Errorf("got violations %v and score %v", counts, score)
+	}
+}
+
+func TestAggregateStats(t *testing.T) {
+	aggregate := AggregateStats([]*SpecificationStats{
+		{Paths: 1, Operations: 2, DeprecatedOperations: 1, Schemas: 3, DocumentationCoverage: 100, ExamplesCoverage: 50, LintScore: 90, QualityScore: 80},
+		{Paths: 2, Operations: 3, Schemas: 1, DocumentationCoverage: 50, ExamplesCoverage: 25, LintScore: 70.5, QualityScore: 49.5},
+	})
+	expected := &SpecificationStats{Name: "Total", Paths: 3, Operations: 5, DeprecatedOperations: 1, Schemas: 4, DocumentationCoverage: 75, ExamplesCoverage: 37.5, LintScore: 80.3, QualityScore: 64.8, Grade: "D"}
+	if !reflect.DeepEqual(aggregate, expected) {
+		t.Errorf("got %+v, want %+v", aggregate, expected)
+	}
+
+	if empty := AggregateStats(nil); !reflect.DeepEqual(empty, &SpecificationStats{Name: "Total"}) {
+		t.Errorf("got %+v for no specification", empty)
+	}
+}
+
+func TestPercentage(t *testing.T) {
+	cases := []struct {
+		done     int
+		total    int
+		expected float64
+	}{
+		{0, 0, 100},
+		{0, 3, 0},
+		{1, 3, 33.3},
+		{2, 3, 66.7},
+		{3, 3, 100},
+	}
+	for _, c := range cases {
+		if value := percentage(c.done, c.total); value != c.expected {
+			t.Errorf("percentage(%d, %d): got %v, want %v", c.done, c.total, value, c.expected)
+		}
+	}
+}
+
+func TestGrade(t *testing.T) {
+	cases := []struct {
+		score    float64
+		expected string
+	}{
+		{100, "A"},
+		{90, "A"},
+		{89.9, "B"},
+		{80, "B"},
+		{79.9, "C"},
+		{70, "C"},
+		{69.9, "D"},
+		{60, "D"},
+		{59.9, "E"},
+		{0, "E"},
+	}
+	for _, c := range cases {
+		if value := grade(c.score); value != c.expected {
+			t.Errorf("grade(%v): got %s, want %s", c.score, value, c.expected)
+		}
+	}
+}
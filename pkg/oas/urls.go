@@ -0,0 +1,146 @@
+package oas
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// UrlMode defines whether the URLs of an index are absolute or relative to the index.
+type UrlMode string
+
+const (
+	// UrlModeRelative produces URLs relative to the location of the index, e.g. "petstore/1.0.0/openapi.yaml".
+	UrlModeRelative UrlMode = "relative"
+
+	// UrlModeAbsolute produces URLs resolved against the public URL of the repository.
+	UrlModeAbsolute UrlMode = "absolute"
+)
+
+// ParseUrlMode converts a string into a URL mode.
+func ParseUrlMode(value string) (UrlMode, error) {
+	switch mode := UrlMode(value); mode {
+	case UrlModeRelative, UrlModeAbsolute:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unsupported url mode <%s>: expected one of relative, absolute", value)
+	}
+}
+
+// urlMode returns the URL mode of the index: absolute when a public URL is given, unless specified.
+func (o *IndexOpts) urlMode() UrlMode {
+	if o.UrlMode != "" {
+		return o.UrlMode
+	}
+	if o.Url != "" {
+		return UrlModeAbsolute
+	}
+	return UrlModeRelative
+}
+
+// validateUrlOpts checks the public URL, mode and template of the index.
+func validateUrlOpts(o *IndexOpts) error {
+	if o.Url != "" {
+		base, err := url.Parse(o.Url)
+		if err != nil || !base.IsAbs() || base.Host == "" {
+			return fmt.Errorf("invalid public url <%s>: expected an absolute URL", o.Url)
+		}
+	}
+	if o.urlMode() == UrlModeAbsolute && o.Url == "" {
+		return fmt.Errorf("absolute urls require a public url")
+	}
+	if o.UrlTemplate != "" {
+		if _, err := url.Parse(o.expandUrlTemplate("", "", "", "")); err != nil {
+			return fmt.Errorf("invalid url template <%s>: %v", o.UrlTemplate, err)
+		}
+	}
+	return nil
+}
+
+// publicUrl returns the URL of a file of the repository given by its slash-separated path relative
+// to the indexed directory.
+func (o *IndexOpts) publicUrl(relativePath string) string {
+	return o.resolveUrl(escapeUrlPath(strings.TrimPrefix(relativePath, "/")))
+}
+
+// specificationUrl returns the URL of a specification, built from the URL template when given.
+func (o *IndexOpts) specificationUrl(relativePath string, id string, title string, version string) string {
+	if o.UrlTemplate == "" {
+		return o.publicUrl(relativePath)
+	}
+	return o.resolveUrl(o.expandUrlTemplate(relativePath, id, title, version))
+}
+
+// expandUrlTemplate replaces the placeholders of the URL template with escaped values: {base} (the
+// public URL, empty for relative URLs), {path} (the path relative to the indexed directory), {dir},
+// {file}, {id} or {name} (the identifier), {title} and {version}.
+func (o *IndexOpts) expandUrlTemplate(relativePath string, id string, title string, version string) string {
+	base := ""
+	if o.urlMode() == UrlModeAbsolute {
+		base = strings.TrimSuffix(o.Url, "/")
+	}
+	directory := path.Dir(relativePath)
+	if directory == "." {
+		directory = ""
+	}
+	expanded := strings.NewReplacer(
+		"{base}", base,
+		"{path}", escapeUrlPath(relativePath),
+		"{dir}", escapeUrlPath(directory),
+		"{file}", url.PathEscape(path.Base(relativePath)),
+		"{id}", url.PathEscape(id),
+		"{name}", url.PathEscape(id),
+		"{title}", url.PathEscape(title),
+		"{version}", url.PathEscape(version),
+	).Replace(o.UrlTemplate)
+	if base == "" {
+		expanded = strings.TrimLeft(expanded, "/")
+	}
+	return expanded
+}
+
+// resolveUrl makes an escaped relative URL absolute in the absolute URL mode.
+func (o *IndexOpts) resolveUrl(reference string) string {
+	if o.urlMode() != UrlModeAbsolute {
+		return reference
+	}
+	parsedReference, err := url.Parse(reference)
+	if err != nil || parsedReference.IsAbs() {
+		return reference
+	}
+	base, err := url.Parse(o.Url)
+	if err != nil {
+		return reference
+	}
+	base.Path = strings.TrimSuffix(base.Path, "/") + "/"
+	base.RawPath = ""
+	return base.ResolveReference(parsedReference).String()
+}
+
+// relativeSlashPath returns the slash-separated path of a file relative to a directory.
+func relativeSlashPath(directory string, file string) (string, error) {
+	relativePath, err := filepath.Rel(directory, file)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(relativePath), nil
+}
+
+// escapeUrlPath escapes each segment of a slash-separated path.
+func escapeUrlPath(slashPath string) string {
+	segments := strings.Split(slashPath, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// localUrlPath converts a relative URL of an index into a slash-separated path.
+func localUrlPath(reference string) string {
+	if parsed, err := url.Parse(reference); err == nil && parsed.Scheme == "" {
+		return parsed.Path
+	}
+	return reference
+}
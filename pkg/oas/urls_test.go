@@ -0,0 +1,81 @@
+package oas
+
+import (
+	"testing"
+)
+
+func TestParseUrlMode(t *testing.T) {
+	for value, valid := range map[string]bool{"relative": true, "absolute": true, "": false, "Absolute": false} {
+		mode, err := ParseUrlMode(value)
+		if (err == nil) != valid || (valid && string(mode) != value) {
+			t.Errorf("<%s>: got <%s> <%v>, want valid <%t>", value, mode, err, valid)
+		}
+	}
+}
+
+func TestValidateUrlOpts(t *testing.T) {
+	cases := []struct {
+		name     string
+		url      string
+		mode     UrlMode
+		template string
+		err      bool
+	}{
+		{"no url", "", "", "", false},
+		{"absolute url", "https://apis.example.com/catalog", "", "", false},
+		{"relative url", "apis.example.com/catalog", "", "", true},
+		{"absolute mode without url", "", UrlModeAbsolute, "", true},
+		{"relative mode with url", "https://apis.example.com", UrlModeRelative, "", false},
+		{"template", "https://apis.example.com", "", "{base}/{id}/{version}/spec.yaml", false},
+		{"invalid template", "", "", "%zz/{id}", true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := NewIndexOpts()
+			opts.Url, opts.UrlMode, opts.UrlTemplate = c.url, c.mode, c.template
+			if err := validateUrlOpts(opts); (err != nil) != c.err {
+				t.Errorf("got error <%v>, want error <%t>", err, c.err)
+			}
+		})
+	}
+}
+
+func TestSpecificationUrl(t *testing.T) {
+	cases := []struct {
+		name     string
+		url      string
+		mode     UrlMode
+		template string
+		expected string
+	}{
+		{"relative", "", "", "", "pet%20store/1.0.0/openapi.yaml"},
+		{"absolute", "https://apis.example.com", "", "", "https://apis.example.com/pet%20store/1.0.0/openapi.yaml"},
+		{"absolute with base path", "https://example.com/catalog", "", "", "https://example.com/catalog/pet%20store/1.0.0/openapi.yaml"},
+		{"absolute with base path and slash", "https://example.com/catalog/", "", "", "https://example.com/catalog/pet%20store/1.0.0/openapi.yaml"},
+		{"relative mode with url", "https://example.com/catalog", UrlModeRelative, "", "pet%20store/1.0.0/openapi.yaml"},
+		{"template", "https://example.com/catalog", "", "{base}/{id}/{version}/spec.yaml", "https://example.com/catalog/petstore/1.0.0/spec.yaml"},
+		{"relative template", "", "", "{base}/{dir}/{file}?title={title}", "pet%20store/1.0.0/openapi.yaml?title=Pet%20Store"},
+		{"template without base", "https://example.com/catalog", "", "specs/{name}.yaml", "https://example.com/catalog/specs/petstore.yaml"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := NewIndexOpts()
+			opts.Url, opts.UrlMode, opts.UrlTemplate = c.url, c.mode, c.template
+			if url := opts.specificationUrl("pet store/1.0.0/openapi.yaml", "petstore", "Pet Store", "1.0.0"); url != c.expected {
+				t.Errorf("got <%s>, want <%s>", url, c.expected)
+			}
+		})
+	}
+}
+
+func TestLocalUrlPath(t *testing.T) {
+	for reference, expected := range map[string]string{
+		"pet%20store/openapi.yaml":              "pet store/openapi.yaml",
+		"pets/openapi.yaml?ref=main":            "pets/openapi.yaml",
+		"https://apis.example.com/openapi.yaml": "https://apis.example.com/openapi.yaml",
+	} {
+		if path := localUrlPath(reference); path != expected {
+			t.Errorf("<%s>: got <%s>, want <%s>", reference, path, expected)
+		}
+	}
+}
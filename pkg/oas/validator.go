@@ -0,0 +1,715 @@
+package oas
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrOperationNotFound is returned when no operation of the specification matches a request.
+var ErrOperationNotFound = errors.New("no operation matches the request")
+
+var emailRegexp = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+var uuidRegexp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// ValidationError describes a value not matching its specification.
+type ValidationError struct {
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Location, e.Message)
+}
+
+// ValidationErrors aggregates every violation found while validating a value, a request or a response.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ValidationDirection tells whether a value is sent by a client or by the server, which drives
+// the handling of readOnly and writeOnly properties.
+type ValidationDirection int
+
+const (
+	ValidationDirectionRequest ValidationDirection = iota
+	ValidationDirectionResponse
+)
+
+// Validator validates values, requests and responses against a specification.
+type Validator struct {
+	Specification *OAS3Specification
+
+	// BasePath is stripped from the request paths before matching them against the specification paths.
+	BasePath string
+
+	patterns     map[string]*regexp.Regexp
+	patternsLock sync.Mutex
+}
+
+func NewValidator(specification *OAS3Specification) *Validator {
+	return &Validator{
+		Specification: specification,
+		BasePath:      specification.BasePath(),
+		patterns:      make(map[string]*regexp.Regexp),
+	}
+}
+
+// BasePath returns the path of the first server URL, without trailing slash.
+func (s *OAS3Specification) BasePath() string {
+	if len(s.Servers) == 0 {
+		return ""
+	}
+	serverUrl, err := url.Parse(s.Servers[0].Url)
+	if err != nil || strings.Contains(serverUrl.Path, "{") {
+		return ""
+	}
+	return strings.TrimSuffix(serverUrl.Path, "/")
+}
+
+// FindOperation returns the operation matching a request, and its path parameters.
+func (v *Validator) FindOperation(r *http.Request) (*OAS3OperationRef, map[string]string, error) {
+	requestPath := r.URL.Path
+	if v.BasePath != "" {
+		if requestPath != v.BasePath && !strings.HasPrefix(requestPath, v.BasePath+"/") {
+			return nil, nil, ErrOperationNotFound
+		}
+		requestPath = strings.TrimPrefix(requestPath, v.BasePath)
+	}
+
+	operation, pathParams := v.Specification.FindOperation(r.Method, requestPath)
+	if operation == nil {
+		return nil, nil, ErrOperationNotFound
+	}
+	return operation, pathParams, nil
+}
+
+// ValidateRequest validates the parameters and the body of a request against the matching operation.
+// The request body is read and replaced so that it can still be consumed afterwards.
+// It returns ErrOperationNotFound if no operation matches, or ValidationErrors.
+func (v *Validator) ValidateRequest(r *http.Request) (*OAS3OperationRef, error) {
+	operation, pathParams, err := v.FindOperation(r)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := ValidationErrors{}
+
+	// Parameters
+	query := r.URL.Query()
+	for _, parameter := range v.Specification.OperationParameters(operation) {
+		var values []string
+		switch parameter.In {
+		case "path":
+			if value, found := pathParams[parameter.Name]; found {
+				if unescapedValue, err := url.PathUnescape(value); err == nil {
+					value = unescapedValue
+				}
+				values = []string{value}
+			}
+		case "query":
+			values = query[parameter.Name]
+		case "header":
+			values = r.Header.Values(parameter.Name)
+		case "cookie":
+			if cookie, err := r.Cookie(parameter.Name); err == nil {
+				values = []string{cookie.Value}
+			}
+		}
+		errs = append(errs, v.validateParameter(parameter, values)...)
+	}
+
+	// Body
+	requestBody := v.Specification.ResolveRequestBody(operation.Operation.RequestBody)
+	if requestBody != nil {
+		var body []byte
+		if r.Body != nil {
+			body, err = ioutil.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				return operation, err
+			}
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		errs = append(errs, v.validateContent("body", requestBody.Content, requestBody.Required, r.Header.Get("Content-Type"), body, ValidationDirectionRequest)...)
+	}
+
+	if len(errs) > 0 {
+		return operation, errs
+	}
+	return operation, nil
+}
+
+// ValidateResponse validates the status, headers and body of a response of an operation.
+// It returns ValidationErrors if the response does not match the specification.
+func (v *Validator) ValidateResponse(operation *OAS3OperationRef, status int, header http.Header, body []byte) error {
+	response := v.findResponse(operation.Operation, status)
+	if response == nil {
+		return ValidationErrors{{Location: "status", Message: fmt.Sprintf("%d is not a documented response", status)}}
+	}
+	return v.validateResponse(response, header, body)
+}
+
+// validateResponse validates the headers and body of a response against one documented response.
+func (v *Validator) validateResponse(response *OAS3Response, header http.Header, body []byte) error {
+	errs := ValidationErrors{}
+
+	// Headers
+	headerNames := make([]string, 0, len(response.Headers))
+	for name := range response.Headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		responseHeader := v.Specification.ResolveHeader(response.Headers[name])
+		if responseHeader == nil || strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		location := "header." + name
+		values := header.Values(name)
+		if len(values) == 0 {
+			if responseHeader.Required {
+				errs = append(errs, &ValidationError{Location: location, Message: "is required"})
+			}
+			continue
+		}
+		if responseHeader.Schema == nil {
+			continue
+		}
+		value, err := v.coerceParameterValue(responseHeader.Schema, values)
+		if err != nil {
+			errs = append(errs, &ValidationError{Location: location, Message: err.Error()})
+			continue
+		}
+		errs = append(errs, v.ValidateValue(responseHeader.Schema, value, location, ValidationDirectionResponse)...)
+	}
+
+	// Body
+	if len(response.Content) == 0 {
+		if len(body) > 0 {
+			errs = append(errs, &ValidationError{Location: "body", Message: "no content is documented"})
+		}
+	} else {
+		errs = append(errs, v.validateContent("body", response.Content, false, header.Get("Content-Type"), body, ValidationDirectionResponse)...)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// findResponse returns the response documented for a status: exact code first, then range (e.g. 4XX), then default.
+func (v *Validator) findResponse(operation *OAS3Operation, status int) *OAS3Response {
+	code := strconv.Itoa(status)
+	for _, candidate := range []string{code, code[:1] + "XX", code[:1] + "xx", "default"} {
+		if response, found := operation.Responses[candidate]; found {
+			return v.Specification.ResolveResponse(response)
+		}
+	}
+	return nil
+}
+
+func (v *Validator) validateParameter(parameter *OAS3Parameter, values []string) ValidationErrors {
+	location := parameter.In + "." + parameter.Name
+
+	// Presence
+	if len(values) == 0 {
+		if parameter.Required || parameter.In == "path" {
+			return ValidationErrors{{Location: location, Message: "is required"}}
+		}
+		return nil
+	}
+
+	// Parameters described with a content map are JSON-encoded.
+	schema := parameter.Schema
+	if schema == nil {
+		_, mediaType := PreferredMediaType(parameter.Content)
+		if mediaType == nil || mediaType.Schema == nil {
+			return nil
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(values[0]), &value); err != nil {
+			return ValidationErrors{{Location: location, Message: "is not a valid JSON value"}}
+		}
+		return v.ValidateValue(mediaType.Schema, value, location, ValidationDirectionRequest)
+	}
+
+	value, err := v.coerceParameterValue(schema, values)
+	if err != nil {
+		return ValidationErrors{{Location: location, Message: err.Error()}}
+	}
+	return v.ValidateValue(schema, value, location, ValidationDirectionRequest)
+}
+
+// coerceParameterValue converts the raw values of a parameter into the type described by its schema.
+func (v *Validator) coerceParameterValue(schema *OAS3Schema, values []string) (interface{}, error) {
+	schema = v.Specification.ResolveSchema(schema)
+	if schema == nil {
+		return values[0], nil
+	}
+
+	switch schema.Type {
+	case "array":
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		items := []interface{}{}
+		for _, value := range values {
+			if schema.Items == nil {
+				items = append(items, value)
+				continue
+			}
+			item, err := v.coerceParameterValue(schema.Items, []string{value})
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case "integer", "number":
+		number, err := strconv.ParseFloat(values[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("<%s> is not a valid number", values[0])
+		}
+		return number, nil
+	case "boolean":
+		boolean, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return nil, fmt.Errorf("<%s> is not a valid boolean", values[0])
+		}
+		return boolean, nil
+	default:
+		return values[0], nil
+	}
+}
+
+// validateContent validates a body against the media type matching its content type.
+func (v *Validator) validateContent(location string, content map[string]*OAS3MediaType, required bool, contentType string, body []byte, direction ValidationDirection) ValidationErrors {
+	if len(body) == 0 {
+		if required {
+			return ValidationErrors{{Location: location, Message: "is required"}}
+		}
+		return nil
+	}
+	if len(content) == 0 {
+		return nil
+	}
+
+	// Find media type
+	mediaType := findMediaType(content, contentType)
+	if mediaType == nil {
+		return ValidationErrors{{Location: location, Message: fmt.Sprintf("content type <%s> is not supported", contentType)}}
+	}
+
+	// Only JSON bodies are validated against their schema.
+	if mediaType.Schema == nil || !IsJsonMediaType(contentType) {
+		return nil
+	}
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return ValidationErrors{{Location: location, Message: "is not a valid JSON document"}}
+	}
+	return v.ValidateValue(mediaType.Schema, value, location, direction)
+}
+
+// findMediaType returns the media type of the content map matching a content type, supporting
+// wildcards such as application/* or */*.
+func findMediaType(content map[string]*OAS3MediaType, contentType string) *OAS3MediaType {
+	contentType = strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+	if contentType == "" {
+		_, mediaType := PreferredMediaType(content)
+		return mediaType
+	}
+
+	candidates := []string{contentType}
+	if slash := strings.Index(contentType, "/"); slash >= 0 {
+		candidates = append(candidates, contentType[:slash]+"/*")
+	}
+	candidates = append(candidates, "*/*")
+
+	for _, candidate := range candidates {
+		for mediaTypeName, mediaType := range content {
+			if strings.ToLower(strings.TrimSpace(strings.Split(mediaTypeName, ";")[0])) == candidate {
+				return mediaType
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateValue validates a decoded JSON value against a schema.
+func (v *Validator) ValidateValue(schema *OAS3Schema, value interface{}, location string, direction ValidationDirection) ValidationErrors {
+	return v.validateValue(schema, normalizeGenericValue(value), location, direction, 0)
+}
+
+func (v *Validator) validateValue(schema *OAS3Schema, value interface{}, location string, direction ValidationDirection, depth int) ValidationErrors {
+	schema = v.Specification.ResolveSchema(schema)
+	if schema == nil || depth > 64 {
+		return nil
+	}
+
+	errs := ValidationErrors{}
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Location: location, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Compositions
+	for _, subSchema := range schema.AllOf {
+		errs = append(errs, v.validateValue(subSchema, value, location, direction, depth+1)...)
+	}
+	if len(schema.AnyOf) > 0 {
+		matched := false
+		for _, subSchema := range schema.AnyOf {
+			if len(v.validateValue(subSchema, value, location, direction, depth+1)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("does not match any of the expected schemas")
+		}
+	}
+	if len(schema.OneOf) > 0 {
+		matches := 0
+		for _, subSchema := range schema.OneOf {
+			if len(v.validateValue(subSchema, value, location, direction, depth+1)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			fail("must match exactly one of the expected schemas, matches %d", matches)
+		}
+	}
+	if schema.Not != nil && len(v.validateValue(schema.Not, value, location, direction, depth+1)) == 0 {
+		fail("must not match the excluded schema")
+	}
+
+	// Null
+	if value == nil {
+		if !schema.Nullable && schema.Type != "" {
+			fail("must not be null")
+		}
+		return errs
+	}
+
+	// Enum
+	if len(schema.Enum) > 0 {
+		found := false
+		for _, enumValue := range schema.Enum {
+			if valuesEqual(enumValue, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("must be one of %v", schema.Enum)
+		}
+	}
+
+	// Type
+	switch schema.Type {
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			break
+		}
+		errs = append(errs, v.validateString(schema, s, location)...)
+	case "integer", "number":
+		number, ok := toNumber(value)
+		if !ok && schema.Type == "integer" {
+			fail("must be an integer")
+			break
+		}
+		if !ok {
+			fail("must be a number")
+			break
+		}
+		if schema.Type == "integer" && number != math.Trunc(number) {
+			fail("must be an integer")
+		}
+		errs = append(errs, validateNumber(schema, number, location)...)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			break
+		}
+		errs = append(errs, v.validateArray(schema, items, location, direction, depth)...)
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			break
+		}
+		errs = append(errs, v.validateObject(schema, object, location, direction, depth)...)
+	default:
+		// Untyped schemas may still describe objects.
+		if object, ok := value.(map[string]interface{}); ok && len(schema.Properties) > 0 {
+			errs = append(errs, v.validateObject(schema, object, location, direction, depth)...)
+		}
+	}
+
+	return errs
+}
+
+func (v *Validator) validateString(schema *OAS3Schema, value string, location string) ValidationErrors {
+	errs := ValidationErrors{}
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Location: location, Message: fmt.Sprintf(format, args...)})
+	}
+
+	length := len([]rune(value))
+	if schema.MinLength != nil && length < *schema.MinLength {
+		fail("must be at least %d characters long", *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		fail("must be at most %d characters long", *schema.MaxLength)
+	}
+	if schema.Pattern != "" {
+		pattern, err := v.compilePattern(schema.Pattern)
+		if err != nil {
+			fail("pattern %s of the specification is not a valid regular expression: %v", schema.Pattern, err)
+		} else if !pattern.MatchString(value) {
+			fail("must match pattern %s", schema.Pattern)
+		}
+	}
+	if err := validateFormat(schema.Format, value); err != nil {
+		fail("%s", err)
+	}
+	return errs
+}
+
+func validateNumber(schema *OAS3Schema, value float64, location string) ValidationErrors {
+	errs := ValidationErrors{}
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Location: location, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if schema.Minimum != nil {
+		if schema.ExclusiveMinimum && value <= *schema.Minimum {
+			fail("must be greater than %v", *schema.Minimum)
+		} else if value < *schema.Minimum {
+			fail("must be greater than or equal to %v", *schema.Minimum)
+		}
+	}
+	if schema.Maximum != nil {
+		if schema.ExclusiveMaximum && value >= *schema.Maximum {
+			fail("must be less than %v", *schema.Maximum)
+		} else if value > *schema.Maximum {
+			fail("must be less than or equal to %v", *schema.Maximum)
+		}
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf != 0 {
+		quotient := value / *schema.MultipleOf
+		if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+			fail("must be a multiple of %v", *schema.MultipleOf)
+		}
+	}
+	return errs
+}
+
+func (v *Validator) validateArray(schema *OAS3Schema, items []interface{}, location string, direction ValidationDirection, depth int) ValidationErrors {
+	errs := ValidationErrors{}
+	if schema.MinItems != nil && len(items) < *schema.MinItems {
+		errs = append(errs, &ValidationError{Location: location, Message: fmt.Sprintf("must contain at least %d items", *schema.MinItems)})
+	}
+	if schema.MaxItems != nil && len(items) > *schema.MaxItems {
+		errs = append(errs, &ValidationError{Location: location, Message: fmt.Sprintf("must contain at most %d items", *schema.MaxItems)})
+	}
+	if schema.UniqueItems {
+		for i := range items {
+			for j := i + 1; j < len(items); j++ {
+				if valuesEqual(items[i], items[j]) {
+					errs = append(errs, &ValidationError{Location: location, Message: fmt.Sprintf("items %d and %d must be unique", i, j)})
+				}
+			}
+		}
+	}
+	if schema.Items != nil {
+		for i, item := range items {
+			errs = append(errs, v.validateValue(schema.Items, item, fmt.Sprintf("%s[%d]", location, i), direction, depth+1)...)
+		}
+	}
+	return errs
+}
+
+func (v *Validator) validateObject(schema *OAS3Schema, object map[string]interface{}, location string, direction ValidationDirection, depth int) ValidationErrors {
+	errs := ValidationErrors{}
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, &ValidationError{Location: location, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if schema.MinProperties != nil && len(object) < *schema.MinProperties {
+		fail("must contain at least %d properties", *schema.MinProperties)
+	}
+	if schema.MaxProperties != nil && len(object) > *schema.MaxProperties {
+		fail("must contain at most %d properties", *schema.MaxProperties)
+	}
+
+	// Required properties, ignoring readOnly properties in requests and writeOnly properties in responses.
+	for _, name := range schema.Required {
+		if _, found := object[name]; found {
+			continue
+		}
+		property := v.Specification.ResolveSchema(schema.Properties[name])
+		if property != nil && ((direction == ValidationDirectionRequest && property.ReadOnly) || (direction == ValidationDirectionResponse && property.WriteOnly)) {
+			continue
+		}
+		errs = append(errs, &ValidationError{Location: location + "." + name, Message: "is required"})
+	}
+
+	// Properties, in a stable order.
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	additionalSchema, additionalAllowed := schema.AdditionalPropertiesSchema()
+	for _, name := range names {
+		if property, found := schema.Properties[name]; found {
+			errs = append(errs, v.validateValue(property, object[name], location+"."+name, direction, depth+1)...)
+		} else if !additionalAllowed {
+			errs = append(errs, &ValidationError{Location: location + "." + name, Message: "is not allowed"})
+		} else if additionalSchema != nil {
+			errs = append(errs, v.validateValue(additionalSchema, object[name], location+"."+name, direction, depth+1)...)
+		}
+	}
+	return errs
+}
+
+func (v *Validator) compilePattern(pattern string) (*regexp.Regexp, error) {
+	v.patternsLock.Lock()
+	defer v.patternsLock.Unlock()
+
+	if v.patterns == nil {
+		v.patterns = make(map[string]*regexp.Regexp)
+	}
+	if compiled, found := v.patterns[pattern]; found {
+		return compiled, nil
+	}
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns[pattern] = compiled
+	return compiled, nil
+}
+
+// validateFormat checks the well-known string formats. Unknown formats are accepted.
+func validateFormat(format string, value string) error {
+	switch format {
+	case "email":
+		if !emailRegexp.MatchString(value) {
+			return errors.New("must be a valid email address")
+		}
+	case "uuid":
+		if !uuidRegexp.MatchString(value) {
+			return errors.New("must be a valid UUID")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return errors.New("must be a valid RFC 3339 date-time")
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", value); err != nil {
+			return errors.New("must be a valid RFC 3339 full-date")
+		}
+	case "uri":
+		if parsedUrl, err := url.Parse(value); err != nil || !parsedUrl.IsAbs() {
+			return errors.New("must be a valid absolute URI")
+		}
+	case "ipv4":
+		if ip := net.ParseIP(value); ip == nil || ip.To4() == nil {
+			return errors.New("must be a valid IPv4 address")
+		}
+	case "ipv6":
+		if ip := net.ParseIP(value); ip == nil || ip.To4() != nil {
+			return errors.New("must be a valid IPv6 address")
+		}
+	case "byte":
+		if len(value)%4 != 0 || strings.TrimRight(strings.TrimLeft(value, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/"), "=") != "" {
+			return errors.New("must be a valid base64 string")
+		}
+	}
+	return nil
+}
+
+// toNumber converts any numeric value into a float64.
+func toNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// valuesEqual compares two decoded values, numbers being compared by value whatever their type.
+func valuesEqual(a interface{}, b interface{}) bool {
+	aNumber, aIsNumber := toNumber(a)
+	bNumber, bIsNumber := toNumber(b)
+	if aIsNumber && bIsNumber {
+		return aNumber == bNumber
+	}
+
+	a = normalizeGenericValue(a)
+	b = normalizeGenericValue(b)
+	if reflect.DeepEqual(a, b) {
+		return true
+	}
+
+	// Composite values may hold numbers of different types.
+	aJson, aErr := json.Marshal(a)
+	bJson, bErr := json.Marshal(b)
+	return aErr == nil && bErr == nil && bytes.Equal(aJson, bJson)
+}
@@ -0,0 +1,324 @@
+package oas
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const validatorTestSpecification = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1/
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            minimum: 1
+            maximum: 100
+        - name: tags
+          in: query
+          schema:
+            type: array
+            items:
+              type: string
+              enum: [cat, dog]
+        - name: X-Tenant
+          in: header
+          required: true
+          schema:
+            type: string
+            format: uuid
+      responses:
+        "200":
+          description: Pets
+          headers:
+            X-Total-Count:
+              required: true
+              schema:
+                type: integer
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+        4XX:
+          description: Client error
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        "204":
+          description: Created
+  /pets/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      parameters:
+        - name: session
+          in: cookie
+          schema:
+            type: boolean
+      responses:
+        default:
+          description: Pet
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name, password]
+      additionalProperties: false
+      properties:
+        id:
+          type: integer
+          readOnly: true
+        name:
+          type: string
+          minLength: 1
+          maxLength: 10
+          pattern: '^[A-Z]'
+        password:
+          type: string
+          writeOnly: true
+        tag:
+          type: string
+          nullable: true
+        birth:
+          type: string
+          format: date
+`
+
+func newTestValidator(t *testing.T) *Validator {
+	t.Helper()
+	specification := &OAS3Specification{}
+	if err := yaml.Unmarshal([]byte(validatorTestSpecification), specification); err != nil {
+		t.Fatal(err)
+	}
+	return NewValidator(specification)
+}
+
+func TestValidateValue(t *testing.T) {
+	float := func(value float64) *float64 { return &value }
+	integer := func(value int) *int { return &value }
+	cases := []struct {
+		name      string
+		schema    *OAS3Schema
+		value     string
+		direction ValidationDirection
+		err       string
+	}{
+		{"string", &OAS3Schema{Type: "string"}, `"a"`, ValidationDirectionRequest, ""},
+		{"not a string", &OAS3Schema{Type: "string"}, `1`, ValidationDirectionRequest, "value: must be a string"},
+		{"integer", &OAS3Schema{Type: "integer"}, `3`, ValidationDirectionRequest, ""},
+		{"not an integer", &OAS3Schema{Type: "integer"}, `3.5`, ValidationDirectionRequest, "value: must be an integer"},
+		{"not a number", &OAS3Schema{Type: "number"}, `"3"`, ValidationDirectionRequest, "value: must be a number"},
+		{"not a boolean", &OAS3Schema{Type: "boolean"}, `"true"`, ValidationDirectionRequest, "value: must be a boolean"},
+		{"null", &OAS3Schema{Type: "string"}, `null`, ValidationDirectionRequest, "value: must not be null"},
+		{"nullable", &OAS3Schema{Type: "string", Nullable: true}, `null`, ValidationDirectionRequest, ""},
+		{"enum", &OAS3Schema{Type: "integer", Enum: []interface{}{1, 2}}, `2`, ValidationDirectionRequest, ""},
+		{"not in enum", &OAS3Schema{Type: "string", Enum: []interface{}{"a", "b"}}, `"c"`, ValidationDirectionRequest, "value: must be one of [a b]"},
+		{"minimum", &OAS3Schema{Type: "number", Minimum: float(1)}, `1`, ValidationDirectionRequest, ""},
+		{"below minimum", &OAS3Schema{Type: "number", Minimum: float(1)}, `0.5`, ValidationDirectionRequest, "value: must be greater than or equal to 1"},
+		{"exclusive minimum", &OAS3Schema{Type: "number", Minimum: float(1), ExclusiveMinimum: true}, `1`, ValidationDirectionRequest, "value: must be greater than 1"},
+		{"exclusive maximum", &OAS3Schema{Type: "number", Maximum: float(1), ExclusiveMaximum: true}, `1`, ValidationDirectionRequest, "value: must be less than 1"},
+		{"multiple of", &OAS3Schema{Type: "number", MultipleOf: float(0.1)}, `0.3`, ValidationDirectionRequest, ""},
+		{"not a multiple of", &OAS3Schema{Type: "integer", MultipleOf: float(5)}, `12`, ValidationDirectionRequest, "value: must be a multiple of 5"},
+		{"too short", &OAS3Schema{Type: "string", MinLength: integer(3)}, `"éé"`, ValidationDirectionRequest, "value: must be at least 3 characters long"},
+		{"length counted in runes", &OAS3Schema{Type: "string", MaxLength: integer(2)}, `"éé"`, ValidationDirectionRequest, ""},
+		{"pattern", &OAS3Schema{Type: "string", Pattern: `^\d+$`}, `"12a"`, ValidationDirectionRequest, `value: must match pattern ^\d+$`},
+		{"invalid pattern", &OAS3Schema{Type: "string", Pattern: `^[a-z`}, `"abc"`, ValidationDirectionRequest, "value: pattern ^[a-z of the specification is not a valid regular expression: error parsing regexp: missing closing ]: `[a-z`"},
+		{"email", &OAS3Schema{Type: "string", Format: "email"}, `"john@example.com"`, ValidationDirectionRequest, ""},
+		{"invalid email", &OAS3Schema{Type: "string", Format: "email"}, `"john"`, ValidationDirectionRequest, "value: must be a valid email address"},
+		{"invalid uuid", &OAS3Schema{Type: "string", Format: "uuid"}, `"1234"`, ValidationDirectionRequest, "value: must be a valid UUID"},
+		{"date-time", &OAS3Schema{Type: "string", Format: "date-time"}, `"2021-03-04T05:06:07Z"`, ValidationDirectionRequest, ""},
+		{"invalid date", &OAS3Schema{Type: "string", Format: "date"}, `"2021-13-01"`, ValidationDirectionRequest, "value: must be a valid RFC 3339 full-date"},
+		{"unknown format", &OAS3Schema{Type: "string", Format: "custom"}, `"anything"`, ValidationDirectionRequest, ""},
+		{"array items", &OAS3Schema{Type: "array", Items: &OAS3Schema{Type: "integer"}}, `[1, "2"]`, ValidationDirectionRequest, "value[1]: must be an integer"},
+		{"too few items", &OAS3Schema{Type: "array", MinItems: integer(2)}, `[1]`, ValidationDirectionRequest, "value: must contain at least 2 items"},
+		{"unique items", &OAS3Schema{Type: "array", UniqueItems: true}, `[1, 2, 1]`, ValidationDirectionRequest, "value: items 0 and 2 must be unique"},
+		{"any of", &OAS3Schema{AnyOf: []*OAS3Schema{{Type: "string"}, {Type: "integer"}}}, `true`, ValidationDirectionRequest, "value: does not match any of the expected schemas"},
+		{"one of matching twice", &OAS3Schema{OneOf: []*OAS3Schema{{Type: "number"}, {Type: "integer"}}}, `1`, ValidationDirectionRequest, "value: must match exactly one of the expected schemas, matches 2"},
+		{"not", &OAS3Schema{Not: &OAS3Schema{Type: "string"}}, `"a"`, ValidationDirectionRequest, "value: must not match the excluded schema"},
+		{"all of", &OAS3Schema{AllOf: []*OAS3Schema{{Type: "string"}, {Type: "string", MaxLength: integer(1)}}}, `"ab"`, ValidationDirectionRequest, "value: must be at most 1 characters long"},
+		{"request object", &OAS3Schema{Ref: "#/components/schemas/Pet"}, `{"name":"Rex","password":"secret"}`, ValidationDirectionRequest, ""},
+		{"response object", &OAS3Schema{Ref: "#/components/schemas/Pet"}, `{"id":1,"name":"Rex"}`, ValidationDirectionResponse, ""},
+		{"read-only property required in responses", &OAS3Schema{Ref: "#/components/schemas/Pet"}, `{"name":"Rex"}`, ValidationDirectionResponse, "value.id: is required"},
+		{"write-only property required in requests", &OAS3Schema{Ref: "#/components/schemas/Pet"}, `{"name":"Rex"}`, ValidationDirectionRequest, "value.password: is required"},
+		{"invalid properties", &OAS3Schema{Ref: "#/components/schemas/Pet"}, `{"id":1,"name":"rex","tag":null,"color":"red"}`, ValidationDirectionResponse, "value.color: is not allowed; value.name: must match pattern ^[A-Z]"},
+	}
+	validator := newTestValidator(t)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var value interface{}
+			if err := json.Unmarshal([]byte(c.value), &value); err != nil {
+				t.Fatal(err)
+			}
+			errs := validator.ValidateValue(c.schema, value, "value", c.direction)
+			got := ""
+			if len(errs) > 0 {
+				got = errs.Error()
+			}
+			if got != c.err {
+				t.Errorf("got <%s>, want <%s>", got, c.err)
+			}
+		})
+	}
+}
+
+func TestValidateRequest(t *testing.T) {
+	const tenant = "0f8fad5b-d9cb-469f-a165-70867728950e"
+	cases := []struct {
+		name   string
+		method string
+		target string
+		header map[string]string
+		body   string
+		err    string
+	}{
+		{"valid query", "GET", "/v1/pets?limit=10&tags=cat,dog", map[string]string{"X-Tenant": tenant}, "", ""},
+		{"repeated query values", "GET", "/v1/pets?tags=cat&tags=dog", map[string]string{"X-Tenant": tenant}, "", ""},
+		{"invalid query values", "GET", "/v1/pets?limit=0&tags=cow", map[string]string{"X-Tenant": tenant}, "", "query.limit: must be greater than or equal to 1; query.tags[0]: must be one of [cat dog]"},
+		{"not a number", "GET", "/v1/pets?limit=ten", map[string]string{"X-Tenant": tenant}, "", "query.limit: <ten> is not a valid number"},
+		{"missing header", "GET", "/v1/pets", nil, "", "header.X-Tenant: is required"},
+		{"invalid header", "GET", "/v1/pets", map[string]string{"X-Tenant": "acme"}, "", "header.X-Tenant: must be a valid UUID"},
+		{"path parameter", "GET", "/v1/pets/7", nil, "", ""},
+		{"invalid path parameter", "GET", "/v1/pets/rex", nil, "", "path.id: <rex> is not a valid number"},
+		{"invalid cookie", "GET", "/v1/pets/7", map[string]string{"Cookie": "session=maybe"}, "", "cookie.session: <maybe> is not a valid boolean"},
+		{"valid body", "POST", "/v1/pets", map[string]string{"Content-Type": "application/json"}, `{"name":"Rex","password":"secret"}`, ""},
+		{"missing body", "POST", "/v1/pets", map[string]string{"Content-Type": "application/json"}, "", "body: is required"},
+		{"malformed body", "POST", "/v1/pets", map[string]string{"Content-Type": "application/json"}, `{"name":`, "body: is not a valid JSON document"},
+		{"invalid body", "POST", "/v1/pets", map[string]string{"Content-Type": "application/json"}, `{"name":"Rex"}`, "body.password: is required"},
+		{"unsupported content type", "POST", "/v1/pets", map[string]string{"Content-Type": "text/plain"}, `Rex`, "body: content type <text/plain> is not supported"},
+	}
+	validator := newTestValidator(t)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(c.method, c.target, strings.NewReader(c.body))
+			for name, value := range c.header {
+				r.Header.Set(name, value)
+			}
+			operation, err := validator.ValidateRequest(r)
+			if operation == nil {
+				t.Fatalf("no operation found: %v", err)
+			}
+			got := ""
+			if err != nil {
+				got = err.Error()
+			}
+			if got != c.err {
+				t.Errorf("got <%s>, want <%s>", got, c.err)
+			}
+
+			// The body can still be read afterwards.
+			body, err := ioutil.ReadAll(r.Body)
+			if err != nil || string(body) != c.body {
+				t.Errorf("got body <%s> <%v>, want <%s>", body, err, c.body)
+			}
+		})
+	}
+}
+
+func TestValidateRequestOperationNotFound(t *testing.T) {
+	cases := []struct {
+		method string
+		target string
+	}{
+		{"GET", "/pets"},
+		{"GET", "/v1/orders"},
+		{"DELETE", "/v1/pets"},
+		{"GET", "/v10/pets"},
+	}
+	validator := newTestValidator(t)
+	for _, c := range cases {
+		t.Run(c.method+" "+c.target, func(t *testing.T) {
+			operation, err := validator.ValidateRequest(httptest.NewRequest(c.method, c.target, nil))
+			if operation != nil || err != ErrOperationNotFound {
+				t.Errorf("got %v <%v>, want ErrOperationNotFound", operation, err)
+			}
+		})
+	}
+}
+
+func TestValidateResponse(t *testing.T) {
+	cases := []struct {
+		name   string
+		target string
+		status int
+		header map[string]string
+		body   string
+		err    string
+	}{
+		{"valid response", "/v1/pets", http.StatusOK, map[string]string{"Content-Type": "application/json", "X-Total-Count": "1"}, `[{"id":1,"name":"Rex"}]`, ""},
+		{"missing header", "/v1/pets", http.StatusOK, map[string]string{"Content-Type": "application/json"}, `[]`, "header.X-Total-Count: is required"},
+		{"invalid header", "/v1/pets", http.StatusOK, map[string]string{"Content-Type": "application/json", "X-Total-Count": "many"}, `[]`, "header.X-Total-Count: <many> is not a valid number"},
+		{"invalid body", "/v1/pets", http.StatusOK, map[string]string{"Content-Type": "application/json", "X-Total-Count": "1"}, `[{"name":"Rex"}]`, "body[0].id: is required"},
+		{"status range", "/v1/pets", http.StatusNotFound, nil, ``, ""},
+		{"undocumented content", "/v1/pets", http.StatusNotFound, nil, `not found`, "body: no content is documented"},
+		{"undocumented status", "/v1/pets", http.StatusInternalServerError, nil, ``, "status: 500 is not a documented response"},
+		{"default response", "/v1/pets/1", http.StatusInternalServerError, map[string]string{"Content-Type": "application/json"}, `{"id":1,"name":"Rex"}`, ""},
+	}
+	validator := newTestValidator(t)
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			operation, _, err := validator.FindOperation(httptest.NewRequest("GET", c.target, nil))
+			if err != nil {
+				t.Fatal(err)
+			}
+			header := http.Header{}
+			for name, value := range c.header {
+				header.Set(name, value)
+			}
+			got := ""
+			if err := validator.ValidateResponse(operation, c.status, header, []byte(c.body)); err != nil {
+				got = err.Error()
+			}
+			if got != c.err {
+				t.Errorf("got <%s>, want <%s>", got, c.err)
+			}
+		})
+	}
+}
+
+func TestValuesEqual(t *testing.T) {
+	cases := []struct {
+		a     interface{}
+		b     interface{}
+		equal bool
+	}{
+		{1, 1.0, true},
+		{int64(2), json.Number("2"), true},
+		{"1", 1, false},
+		{[]interface{}{1, "a"}, []interface{}{1.0, "a"}, true},
+		{map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1.0}, true},
+		{map[string]interface{}{"a": 1}, map[string]interface{}{"a": 2}, false},
+	}
+	for _, c := range cases {
+		if valuesEqual(c.a, c.b) != c.equal {
+			t.Errorf("%v == %v: want <%t>", c.a, c.b, c.equal)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package oas
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Masterminds/semver/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+// VersionPolicy defines how specifications declaring an invalid semantic version are handled.
+type VersionPolicy string
+
+const (
+	// VersionPolicyReject fails the indexation.
+	VersionPolicyReject VersionPolicy = "reject"
+
+	// VersionPolicyWarn logs a warning and keeps the entry, listed after all valid versions.
+	VersionPolicyWarn VersionPolicy = "warn"
+
+	// VersionPolicyCoerce extracts the leading numeric part of the version (e.g. "release-2.1" becomes "2.1.0").
+	// Versions without any numeric part are handled as with VersionPolicyWarn.
+	VersionPolicyCoerce VersionPolicy = "coerce"
+)
+
+var coercibleVersionRegexp = regexp.MustCompile(`(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// ParseVersionPolicy converts a string into a version policy.
+func ParseVersionPolicy(value string) (VersionPolicy, error) {
+	switch policy := VersionPolicy(value); policy {
+	case VersionPolicyReject, VersionPolicyWarn, VersionPolicyCoerce:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unsupported version policy <%s>: expected one of reject, warn, coerce", value)
+	}
+}
+
+// normalizeVersion returns the normalized semantic version of a specification, or an empty string
+// if the version is invalid and the policy allows to keep it.
+func normalizeVersion(path string, version string, policy VersionPolicy) (string, error) {
+	// Valid version
+	parsedVersion, err := semver.NewVersion(version)
+	if err == nil {
+		return parsedVersion.String(), nil
+	}
+
+	switch policy {
+	case VersionPolicyCoerce:
+		if coercedVersion, ok := coerceVersion(version); ok {
+			log.Warnf("Specification <%s>: version <%s> is not a valid semantic version, coerced to <%s>.", path, version, coercedVersion)
+			return coercedVersion, nil
+		}
+		log.Warnf("Specification <%s>: version <%s> is not a valid semantic version and cannot be coerced, listed last.", path, version)
+		return "", nil
+	case VersionPolicyWarn:
+		log.Warnf("Specification <%s>: version <%s> is not a valid semantic version, listed last.", path, version)
+		return "", nil
+	default:
+		return "", fmt.Errorf("specification <%s>: version <%s> is not a valid semantic version: %v", path, version, err)
+	}
+}
+
+// coerceVersion builds a semantic version from the first numeric sequence found in the version.
+func coerceVersion(version string) (string, bool) {
+	matches := coercibleVersionRegexp.FindStringSubmatch(version)
+	if matches == nil {
+		return "", false
+	}
+
+	parts := []string{matches[1], "0", "0"}
+	for i := 2; i <= 3; i++ {
+		if matches[i] != "" {
+			parts[i-1] = matches[i]
+		}
+	}
+
+	coercedVersion, err := semver.NewVersion(fmt.Sprintf("%s.%s.%s", parts[0], parts[1], parts[2]))
+	if err != nil {
+		return "", false
+	}
+	return coercedVersion.String(), true
+}
+
+// parseEntryVersion returns the semantic version of an entry, preferring the normalized version.
+func parseEntryVersion(e *V1_RepositoryIndexSpecificationEntry) (*semver.Version, error) {
+	if e.NormalizedVersion != "" {
+		return semver.NewVersion(e.NormalizedVersion)
+	}
+	return semver.NewVersion(e.Version)
+}
@@ -0,0 +1,79 @@
+package oas
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseVersionPolicy(t *testing.T) {
+	cases := []struct {
+		value  string
+		policy VersionPolicy
+		err    bool
+	}{
+		{"reject", VersionPolicyReject, false},
+		{"warn", VersionPolicyWarn, false},
+		{"coerce", VersionPolicyCoerce, false},
+		{"", "", true},
+		{"strict", "", true},
+	}
+	for _, c := range cases {
+		t.Run(c.value, func(t *testing.T) {
+			policy, err := ParseVersionPolicy(c.value)
+			if (err != nil) != c.err || policy != c.policy {
+				t.Errorf("got <%s> <%v>, want <%s> error <%t>", policy, err, c.policy, c.err)
+			}
+		})
+	}
+}
+
+func TestNormalizeVersion(t *testing.T) {
+	cases := []struct {
+		version  string
+		policy   VersionPolicy
+		expected string
+		err      bool
+	}{
+		{"1.2.3", VersionPolicyReject, "1.2.3", false},
+		{"v1.2", VersionPolicyReject, "1.2.0", false},
+		{"1.0.0-beta.1", VersionPolicyReject, "1.0.0-beta.1", false},
+		{"release-2.1", VersionPolicyReject, "", true},
+		{"release-2.1", VersionPolicyWarn, "", false},
+		{"release-2.1", VersionPolicyCoerce, "2.1.0", false},
+		{"2021.03.build7", VersionPolicyCoerce, "2021.3.0", false},
+		{"v3-final", VersionPolicyReject, "3.0.0-final", false},
+		{"v3 final", VersionPolicyCoerce, "3.0.0", false},
+		{"latest", VersionPolicyCoerce, "", false},
+	}
+	for _, c := range cases {
+		t.Run(string(c.policy)+" "+c.version, func(t *testing.T) {
+			normalized, err := normalizeVersion("openapi.yaml", c.version, c.policy)
+			if (err != nil) != c.err {
+				t.Fatalf("got error <%v>, want error <%t>", err, c.err)
+			}
+			if normalized != c.expected {
+				t.Errorf("got <%s>, want <%s>", normalized, c.expected)
+			}
+		})
+	}
+}
+
+func TestSortByVersionDesc(t *testing.T) {
+	index := NewV1_RepositoryIndex()
+	for _, version := range []string{"beta", "1.10.0", "1.2.0", "alpha", "2.0.0-rc.1", "2.0.0", "v1.9"} {
+		entry := NewV1_RepositoryIndexSpecificationEntry()
+		entry.Id, entry.Version = "pets", version
+		entry.NormalizedVersion, _ = normalizeVersion("openapi.yaml", version, VersionPolicyWarn)
+		index.AddSpecificationEntry(entry)
+	}
+	index.SortByVersionDesc()
+
+	versions := []string{}
+	for _, entry := range index.Entries["pets"] {
+		versions = append(versions, entry.Version)
+	}
+	expected := []string{"2.0.0", "2.0.0-rc.1", "1.10.0", "v1.9", "1.2.0", "beta", "alpha"}
+	if !reflect.DeepEqual(versions, expected) {
+		t.Errorf("got %v, want %v", versions, expected)
+	}
+}
@@ -0,0 +1,172 @@
+package oas
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// File of the repository recording the yanked versions, applied to the index each time it is built.
+const yanksFile = "yanks.yaml"
+
+// V1_RepositoryIndexYankEntry tells why and when a version was yanked. Yanked versions stay in the
+// index for history but are skipped when resolving the latest version or a version constraint.
+type V1_RepositoryIndexYankEntry struct {
+	Reason string `yaml:"reason" json:"reason"`
+	Date   string `yaml:"date" json:"date"`
+}
+
+// RepositoryYank is a version yanked from a repository.
+type RepositoryYank struct {
+	Id      string `yaml:"id"`
+	Version string `yaml:"version"`
+	Reason  string `yaml:"reason"`
+	Date    string `yaml:"date"`
+}
+
+// RepositoryYanks is the content of the yanks file.
+type RepositoryYanks struct {
+	Yanks []RepositoryYank `yaml:"yanks"`
+}
+
+// readYanks reads the yanks file of a repository directory, empty when the file does not exist.
+func readYanks(directory string) (*RepositoryYanks, error) {
+	yanks := &RepositoryYanks{Yanks: []RepositoryYank{}}
+	content, err := ioutil.ReadFile(filepath.Join(directory, yanksFile))
+	if os.IsNotExist(err) {
+		return yanks, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	err = yaml.Unmarshal(content, yanks)
+	if err != nil {
+		return nil, fmt.Errorf("yanks <%s>: %v", filepath.Join(directory, yanksFile), err)
+	}
+	return yanks, nil
+}
+
+// applyYanks marks the entries of the index listed in the yanks file of the indexed directory.
+func applyYanks(o *IndexOpts, index *V1_RepositoryIndex) error {
+	yanks, err := readYanks(o.Directory)
+	if err != nil {
+		return err
+	}
+	for _, yank := range yanks.Yanks {
+		id, _ := index.GetSpecificationEntries(yank.Id)
+		entry := index.FindSpecificationEntry(id, yank.Version)
+		if entry == nil {
+			log.Warnf("Yanked version <%s> of specification <%s> not found in the index.", yank.Version, yank.Id)
+			continue
+		}
+		entry.Yanked = &V1_RepositoryIndexYankEntry{Reason: yank.Reason, Date: yank.Date}
+	}
+	return nil
+}
+
+// latestEntry returns the latest version which is not yanked, or the latest version when all of
+// them are yanked. Entries must be sorted.
+func latestEntry(entries []V1_RepositoryIndexSpecificationEntry) *V1_RepositoryIndexSpecificationEntry {
+	for i := range entries {
+		if entries[i].Yanked == nil {
+			return &entries[i]
+		}
+	}
+	return &entries[0]
+}
+
+type YankOpts struct {
+	// Reference of the yanked version, as "name@version" where the name is an identifier or an alias.
+	Reference string
+	Reason    string
+
+	// Date of the yank. Defaults to today.
+	Date time.Time
+
+	// Index holds the options used to re-index the repository, whose directory is the repository
+	// holding the yanked version.
+	Index *IndexOpts
+}
+
+func NewYankOpts() *YankOpts {
+	return &YankOpts{
+		Reference: "",
+		Reason:    "",
+		Date:      time.Now(),
+		Index:     NewIndexOpts(),
+	}
+}
+
+// Yank records a version of a repository as yanked and re-indexes the repository. Yanked versions
+// stay in the index, marked with the reason, but are excluded from version resolution unless
+// requested explicitly, like retracted Go module versions.
+func Yank(opts *YankOpts) error {
+	log.Infof("Yanking <%s> from repository <%s>.", opts.Reference, opts.Index.Directory)
+
+	// Check reference
+	i := strings.LastIndex(opts.Reference, "@")
+	if i <= 0 || i == len(opts.Reference)-1 {
+		return fmt.Errorf("invalid reference <%s>: expected name@version", opts.Reference)
+	}
+	name, version := opts.Reference[:i], opts.Reference[i+1:]
+	if strings.TrimSpace(opts.Reason) == "" {
+		return fmt.Errorf("a reason is required to yank <%s>", opts.Reference)
+	}
+
+	// Find entry
+	repository, err := OpenRepository(opts.Index.Directory)
+	if err != nil {
+		return err
+	}
+	id, _ := repository.Index.GetSpecificationEntries(name)
+	entry := repository.Index.FindSpecificationEntry(id, version)
+	if entry == nil {
+		return fmt.Errorf("version <%s> of specification <%s> not found in repository <%s>", version, name, opts.Index.Directory)
+	}
+	if entry.Yanked != nil {
+		return fmt.Errorf("version <%s> of specification <%s> is already yanked: %s", entry.Version, id, entry.Yanked.Reason)
+	}
+
+	// Record yank
+	yanks, err := readYanks(opts.Index.Directory)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(opts.Index.Directory, yanksFile)
+	previousContent, _ := ioutil.ReadFile(path)
+	yanks.Yanks = append(yanks.Yanks, RepositoryYank{
+		Id:      id,
+		Version: entry.Version,
+		Reason:  opts.Reason,
+		Date:    opts.Date.Format(sunsetDateLayout),
+	})
+	content, err := marshallCanonicalYaml(yanks)
+	if err != nil {
+		return err
+	}
+	err = ioutil.WriteFile(path, content, 0644)
+	if err != nil {
+		return err
+	}
+
+	// Re-index repository
+	err = Index(opts.Index)
+	if err != nil {
+		log.Debugf("Re-indexing failed, undo the yank.")
+		if previousContent != nil {
+			ioutil.WriteFile(path, previousContent, 0644)
+		} else {
+			os.Remove(path)
+		}
+		return fmt.Errorf("<%s> not yanked: %v", opts.Reference, err)
+	}
+
+	log.Infof("Version <%s> of specification <%s> yanked.", entry.Version, id)
+	return nil
+}
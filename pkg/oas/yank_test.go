@@ -0,0 +1,106 @@
+package oas
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestYank(t *testing.T) {
+	directory := t.TempDir()
+	for _, version := range []string{"1.0.0", "1.1.0", "2.0.0"} {
+		writeTestFile(t, filepath.Join(directory, "pets", version, "openapi.yaml"), "openapi: 3.0.3\ninfo:\n  title: Pet Store\n  version: "+version+"\n  x-extra-info:\n    id: pets\npaths: {}\n")
+	}
+	indexOpts := NewIndexOpts()
+	indexOpts.Directory = directory
+	indexOpts.Formats = []string{"json"}
+	if err := Index(indexOpts); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name      string
+		reference string
+		reason    string
+		err       string
+	}{
+		{"by identifier", "pets@2.0.0", "broken pagination", ""},
+		{"by alias", "Pet Store@1.1.0", "security issue", ""},
+		{"already yanked", "pets@2.0.0", "again", "already yanked"},
+		{"unknown version", "pets@3.0.0", "missing", "not found"},
+		{"without version", "pets", "missing", "expected name@version"},
+		{"without reason", "pets@1.0.0", " ", "reason is required"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := NewYankOpts()
+			opts.Reference = c.reference
+			opts.Reason = c.reason
+			opts.Date = time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+			opts.Index = indexOpts
+			err := Yank(opts)
+			if c.err != "" {
+				if err == nil || !strings.Contains(err.Error(), c.err) {
+					t.Errorf("got error <%v>, want <%s>", err, c.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	// Yanks are recorded and survive re-indexing.
+	content, err := ioutil.ReadFile(filepath.Join(directory, yanksFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(content), "reason: broken pagination") || !strings.Contains(string(content), "date: \"2026-10-19\"") {
+		t.Errorf("unexpected yanks file:\n%s", content)
+	}
+	if err := Index(indexOpts); err != nil {
+		t.Fatal(err)
+	}
+
+	repository, err := OpenRepository(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, err := repository.Resolve("pets")
+	if err != nil || entry.Version != "1.0.0" {
+		t.Errorf("got latest <%v> <%v>, want 1.0.0", entry, err)
+	}
+	entry, err = repository.Resolve("pets@2.0.0")
+	if err != nil || entry.Yanked == nil || entry.Yanked.Reason != "broken pagination" || entry.Yanked.Date != "2026-10-19" {
+		t.Errorf("got exact version <%+v> <%v>", entry, err)
+	}
+}
+
+func TestLatestEntry(t *testing.T) {
+	yanked := &V1_RepositoryIndexYankEntry{Reason: "broken"}
+	cases := []struct {
+		name     string
+		yanked   []bool
+		expected string
+	}{
+		{"none yanked", []bool{false, false}, "2.0.0"},
+		{"latest yanked", []bool{true, false}, "1.0.0"},
+		{"all yanked", []bool{true, true}, "2.0.0"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			entries := []V1_RepositoryIndexSpecificationEntry{{Version: "2.0.0"}, {Version: "1.0.0"}}
+			for i := range entries {
+				if c.yanked[i] {
+					entries[i].Yanked = yanked
+				}
+			}
+			if latest := latestEntry(entries); latest.Version != c.expected {
+				t.Errorf("got <%s>, want <%s>", latest.Version, c.expected)
+			}
+		})
+	}
+}
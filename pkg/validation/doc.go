@@ -0,0 +1,4 @@
+/**
+ *	HTTP middleware validating requests and responses against an OAS3 specification
+ */
+package validation
@@ -0,0 +1,185 @@
+package validation
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/julb/go/pkg/monitoring"
+	"github.com/julb/go/pkg/oas"
+)
+
+// Path label used in metrics for requests not matching any operation.
+const unknownPathLabel = "unknown"
+
+type MiddlewareOpts struct {
+	// BasePath stripped from the request paths. Defaults to the path of the first server URL.
+	BasePath string
+
+	// ValidateRequests rejects invalid requests with a 400 response.
+	ValidateRequests bool
+
+	// RejectUnknownOperations rejects requests matching no operation with a 404 response.
+	RejectUnknownOperations bool
+
+	// ValidateResponses validates the responses of the handler. Responses are buffered to do so.
+	ValidateResponses bool
+
+	// EnforceResponses replaces invalid responses with a 500 response, instead of only reporting them.
+	EnforceResponses bool
+
+	// ErrorHandler writes the response of rejected requests. Defaults to a problem+json response.
+	ErrorHandler func(w http.ResponseWriter, r *http.Request, status int, err error)
+}
+
+func NewMiddlewareOpts() *MiddlewareOpts {
+	return &MiddlewareOpts{
+		ValidateRequests:        true,
+		RejectUnknownOperations: false,
+		ValidateResponses:       false,
+		EnforceResponses:        false,
+		ErrorHandler:            WriteProblem,
+	}
+}
+
+// NewMiddlewareFromFile parses a specification file and builds a validation middleware for it.
+func NewMiddlewareFromFile(path string, opts *MiddlewareOpts) (func(http.Handler) http.Handler, error) {
+	oas3Source, err := oas.ParseFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewMiddleware(oas3Source.Specification(), opts), nil
+}
+
+// NewMiddleware builds a net/http middleware validating requests, and optionally responses, against
+// the specification. Violations are logged and counted in the oas_*_violations_total metrics.
+func NewMiddleware(specification *oas.OAS3Specification, opts *MiddlewareOpts) func(http.Handler) http.Handler {
+	validator := oas.NewValidator(specification)
+	if opts.BasePath != "" {
+		validator.BasePath = strings.TrimSuffix(opts.BasePath, "/")
+	}
+	errorHandler := opts.ErrorHandler
+	if errorHandler == nil {
+		errorHandler = WriteProblem
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Validate request
+			var operation *oas.OAS3OperationRef
+			var err error
+			if opts.ValidateRequests {
+				operation, err = validator.ValidateRequest(r)
+			} else {
+				operation, _, err = validator.FindOperation(r)
+			}
+
+			if err == oas.ErrOperationNotFound {
+				if opts.RejectUnknownOperations {
+					monitoring.OASRequestViolations.WithLabelValues(r.Method, unknownPathLabel).Inc()
+					errorHandler(w, r, http.StatusNotFound, err)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+			if _, ok := err.(oas.ValidationErrors); ok {
+				log.Infof("Request %s %s does not match the specification: %s", r.Method, r.URL.Path, err)
+				monitoring.OASRequestViolations.WithLabelValues(r.Method, operation.Path).Inc()
+				errorHandler(w, r, http.StatusBadRequest, err)
+				return
+			}
+			if err != nil {
+				errorHandler(w, r, http.StatusBadRequest, err)
+				return
+			}
+
+			// Serve without response validation
+			if !opts.ValidateResponses {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Serve and validate response
+			recorder := newResponseRecorder()
+			next.ServeHTTP(recorder, r)
+
+			err = validator.ValidateResponse(operation, recorder.status, recorder.header, recorder.body.Bytes())
+			if err != nil {
+				log.Warnf("Response of %s %s does not match the specification: %s", r.Method, r.URL.Path, err)
+				monitoring.OASResponseViolations.WithLabelValues(r.Method, operation.Path).Inc()
+				if opts.EnforceResponses {
+					errorHandler(w, r, http.StatusInternalServerError, err)
+					return
+				}
+			}
+			recorder.writeTo(w)
+		})
+	}
+}
+
+// WriteProblem writes an error as an application/problem+json response.
+func WriteProblem(w http.ResponseWriter, r *http.Request, status int, err error) {
+	problem := struct {
+		Status int                  `json:"status"`
+		Title  string               `json:"title"`
+		Detail string               `json:"detail"`
+		Errors oas.ValidationErrors `json:"errors,omitempty"`
+	}{
+		Status: status,
+		Title:  http.StatusText(status),
+		Detail: err.Error(),
+	}
+	if validationErrors, ok := err.(oas.ValidationErrors); ok {
+		problem.Detail = "message does not match the specification"
+		problem.Errors = validationErrors
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(problem)
+}
+
+// responseRecorder buffers a response so that it can be validated before being sent.
+type responseRecorder struct {
+	header      http.Header
+	body        bytes.Buffer
+	status      int
+	wroteHeader bool
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{
+		header: make(http.Header),
+		status: http.StatusOK,
+	}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(data)
+}
+
+func (r *responseRecorder) writeTo(w http.ResponseWriter) {
+	for name, values := range r.header {
+		w.Header()[name] = values
+	}
+	w.WriteHeader(r.status)
+	w.Write(r.body.Bytes())
+}
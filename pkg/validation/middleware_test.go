@@ -0,0 +1,161 @@
+package validation
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gopkg.in/yaml.v3"
+
+	"github.com/julb/go/pkg/monitoring"
+	"github.com/julb/go/pkg/oas"
+)
+
+const middlewareTestSpecification = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+servers:
+  - url: /api
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 10
+      responses:
+        "200":
+          description: Pets
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  type: object
+                  required: [name]
+                  properties:
+                    name:
+                      type: string
+`
+
+func newTestMiddleware(t *testing.T, opts *MiddlewareOpts, body string) http.Handler {
+	t.Helper()
+	specification := &oas.OAS3Specification{}
+	if err := yaml.Unmarshal([]byte(middlewareTestSpecification), specification); err != nil {
+		t.Fatal(err)
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Handler", "pets")
+		w.Write([]byte(body))
+	})
+	return NewMiddleware(specification, opts)(handler)
+}
+
+func TestMiddleware(t *testing.T) {
+	cases := []struct {
+		name              string
+		opts              func(opts *MiddlewareOpts)
+		target            string
+		body              string
+		status            int
+		problem           bool
+		requestViolation  string
+		responseViolation bool
+	}{
+		{"valid request", nil, "/api/pets?limit=5", `[]`, http.StatusOK, false, "", false},
+		{"invalid request", nil, "/api/pets?limit=50", `[]`, http.StatusBadRequest, true, "/pets", false},
+		{"invalid request not validated", func(opts *MiddlewareOpts) { opts.ValidateRequests = false }, "/api/pets?limit=50", `[]`, http.StatusOK, false, "", false},
+		{"unknown operation passed through", nil, "/api/orders", `[]`, http.StatusOK, false, "", false},
+		{"unknown operation rejected", func(opts *MiddlewareOpts) { opts.RejectUnknownOperations = true }, "/api/orders", `[]`, http.StatusNotFound, true, unknownPathLabel, false},
+		{"invalid response reported", func(opts *MiddlewareOpts) { opts.ValidateResponses = true }, "/api/pets", `[{}]`, http.StatusOK, false, "", true},
+		{"invalid response enforced", func(opts *MiddlewareOpts) { opts.ValidateResponses, opts.EnforceResponses = true, true }, "/api/pets", `[{}]`, http.StatusInternalServerError, true, "", true},
+		{"valid response enforced", func(opts *MiddlewareOpts) { opts.ValidateResponses, opts.EnforceResponses = true, true }, "/api/pets", `[{"name":"Rex"}]`, http.StatusOK, false, "", false},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			opts := NewMiddlewareOpts()
+			if c.opts != nil {
+				c.opts(opts)
+			}
+			requestViolations := map[string]float64{}
+			for _, path := range []string{"/pets", unknownPathLabel} {
+				requestViolations[path] = testutil.ToFloat64(monitoring.OASRequestViolations.WithLabelValues("GET", path))
+			}
+			responseViolations := testutil.ToFloat64(monitoring.OASResponseViolations.WithLabelValues("GET", "/pets"))
+
+			w := httptest.NewRecorder()
+			newTestMiddleware(t, opts, c.body).ServeHTTP(w, httptest.NewRequest("GET", c.target, nil))
+
+			if w.Code != c.status {
+				t.Errorf("got status %d, want %d", w.Code, c.status)
+			}
+			if c.problem {
+				var problem map[string]interface{}
+				if w.Header().Get("Content-Type") != "application/problem+json" || json.Unmarshal(w.Body.Bytes(), &problem) != nil || problem["status"] != float64(c.status) {
+					t.Errorf("got response %s <%s>, want a problem", w.Header().Get("Content-Type"), w.Body.String())
+				}
+			} else if w.Header().Get("X-Handler") != "pets" || w.Body.String() != c.body {
+				t.Errorf("got response %v <%s>, want the handler response", w.Header(), w.Body.String())
+			}
+
+			for path, before := range requestViolations {
+				want := before
+				if path == c.requestViolation {
+					want++
+				}
+				if got := testutil.ToFloat64(monitoring.OASRequestViolations.WithLabelValues("GET", path)); got != want {
+					t.Errorf("got %v request violations for <%s>, want %v", got, path, want)
+				}
+			}
+			want := responseViolations
+			if c.responseViolation {
+				want++
+			}
+			if got := testutil.ToFloat64(monitoring.OASResponseViolations.WithLabelValues("GET", "/pets")); got != want {
+				t.Errorf("got %v response violations, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWriteProblem(t *testing.T) {
+	w := httptest.NewRecorder()
+	WriteProblem(w, httptest.NewRequest("GET", "/", nil), http.StatusBadRequest, oas.ValidationErrors{{Location: "query.limit", Message: "must be <= 10"}})
+
+	var problem struct {
+		Status int                  `json:"status"`
+		Title  string               `json:"title"`
+		Detail string               `json:"detail"`
+		Errors oas.ValidationErrors `json:"errors"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != 400 || problem.Title != "Bad Request" || len(problem.Errors) != 1 || problem.Errors[0].Location != "query.limit" {
+		t.Errorf("unexpected problem %+v", problem)
+	}
+	if w.Code != http.StatusBadRequest || w.Header().Get("Content-Type") != "application/problem+json" {
+		t.Errorf("got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func TestResponseRecorder(t *testing.T) {
+	recorder := newResponseRecorder()
+	recorder.Header().Set("X-Test", "1")
+	recorder.WriteHeader(http.StatusCreated)
+	recorder.WriteHeader(http.StatusTeapot)
+	recorder.Write([]byte("a"))
+	recorder.Write([]byte("b"))
+
+	w := httptest.NewRecorder()
+	recorder.writeTo(w)
+	if w.Code != http.StatusCreated || w.Header().Get("X-Test") != "1" || w.Body.String() != "ab" {
+		t.Errorf("got %d %v <%s>", w.Code, w.Header(), w.Body.String())
+	}
+}